@@ -0,0 +1,115 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/apus-run/sea-kit/cache/v2"
+	"github.com/apus-run/sea-kit/concurrency/singleflight"
+)
+
+// notFound 空值占位符, 用于缓存数据不存在的结果
+var notFound = []byte("\x00sea-kit:not-found\x00")
+
+// LoadFunc 缓存未命中时加载数据, 数据不存在时返回 cache.ErrKeyNotExist
+type LoadFunc[T any] func(ctx context.Context, key string) (T, error)
+
+// Loader 旁路缓存加载器.
+// 缓存未命中时调用 LoadFunc 加载数据并写回缓存, 同一个 key 的并发加载会被合并为一次;
+// 数据不存在时会缓存空值, 过期时间会随机浮动以避免大量 key 同时失效.
+type Loader[T any] struct {
+	cache cache.Cache
+	opts  *options
+	group singleflight.Group[string, T]
+}
+
+// NewLoader 创建加载器
+func NewLoader[T any](c cache.Cache, opts ...Option) *Loader[T] {
+	return &Loader[T]{
+		cache: c,
+		opts:  Apply(opts...),
+	}
+}
+
+// GetOrLoad 先从缓存中获取数据, 未命中时调用 fn 加载.
+// 数据不存在时返回 cache.ErrKeyNotExist, 缓存读写和解码失败不影响加载结果.
+// fn 收到的 ctx 保留 ctx 中的值但不会随 ctx 取消, 超时时间由 WithLoadTimeout 设置.
+func (l *Loader[T]) GetOrLoad(ctx context.Context, key string, fn LoadFunc[T]) (T, error) {
+	val := l.cache.Get(ctx, key)
+	if val.Error == nil {
+		data, err := val.AsBytes()
+		if err == nil {
+			if bytes.Equal(data, notFound) {
+				var zero T
+				return zero, cache.ErrKeyNotExist
+			}
+			// 解码失败 (例如数据结构变更后的旧数据) 视为未命中, 重新加载并覆盖
+			if v, err := l.decode(data); err == nil {
+				return v, nil
+			}
+		}
+	}
+
+	// 合并后的加载由所有调用方共享, 不能因为其中一个调用方取消而失败,
+	// 所以脱离调用方的 ctx 并单独设置超时, 调用方取消时只是自己提前返回
+	ch := l.group.DoChan(key, func() (T, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.opts.loadTimeout)
+		defer cancel()
+		return l.load(loadCtx, key, fn)
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Delete 删除缓存, 一般在数据更新后调用
+func (l *Loader[T]) Delete(ctx context.Context, key ...string) error {
+	_, err := l.cache.Delete(ctx, key...)
+	return err
+}
+
+func (l *Loader[T]) load(ctx context.Context, key string, fn LoadFunc[T]) (T, error) {
+	v, err := fn(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotExist) {
+		if l.opts.notFoundExpiration > 0 {
+			_ = l.cache.Set(ctx, key, notFound, l.withJitter(l.opts.notFoundExpiration))
+		}
+		return v, cache.ErrKeyNotExist
+	}
+	if err != nil {
+		return v, err
+	}
+
+	data, err := l.opts.codec.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+	_ = l.cache.Set(ctx, key, data, l.withJitter(l.opts.expiration))
+	return v, nil
+}
+
+// decode 指针类型需要先分配内存, 以兼容 proto 等只接受指针的编解码
+func (l *Loader[T]) decode(data []byte) (T, error) {
+	var v T
+	if typ := reflect.TypeOf(v); typ != nil && typ.Kind() == reflect.Pointer {
+		v = reflect.New(typ.Elem()).Interface().(T)
+		return v, l.opts.codec.Unmarshal(data, v)
+	}
+	err := l.opts.codec.Unmarshal(data, &v)
+	return v, err
+}
+
+func (l *Loader[T]) withJitter(d time.Duration) time.Duration {
+	if l.opts.jitter <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*l.opts.jitter*float64(d))
+}
@@ -0,0 +1,319 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/cache/v2"
+	"github.com/apus-run/sea-kit/cache/v2/memory/lru"
+)
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newLRUCache(t *testing.T) *lru.Cache {
+	l, err := simplelru.NewLRU[string, any](16, nil)
+	require.NoError(t, err)
+	return lru.NewCache(l)
+}
+
+// expiringCache 按写入时的过期时间淘汰数据, 并记录每次写入的过期时间
+type expiringCache struct {
+	cache.Cache
+
+	mu          sync.Mutex
+	expireAt    map[string]time.Time
+	expirations []time.Duration
+}
+
+func newExpiringCache(t *testing.T) *expiringCache {
+	return &expiringCache{Cache: newLRUCache(t), expireAt: map[string]time.Time{}}
+}
+
+func (c *expiringCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	c.mu.Lock()
+	c.expireAt[key] = time.Now().Add(expiration)
+	c.expirations = append(c.expirations, expiration)
+	c.mu.Unlock()
+	return c.Cache.Set(ctx, key, val, expiration)
+}
+
+func (c *expiringCache) Get(ctx context.Context, key string) cache.Value {
+	c.mu.Lock()
+	expireAt, ok := c.expireAt[key]
+	c.mu.Unlock()
+	if ok && !time.Now().Before(expireAt) {
+		_, _ = c.Cache.Delete(ctx, key)
+		var val cache.Value
+		val.Error = cache.ErrKeyNotExist
+		return val
+	}
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *expiringCache) Expirations() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.expirations...)
+}
+
+func TestLoader_GetOrLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   LoadFunc[*user]
+
+		wantVal   *user
+		wantErr   error
+		wantCalls int64
+	}{
+		{
+			name: "load and cache",
+			fn: func(ctx context.Context, key string) (*user, error) {
+				return &user{ID: 1, Name: key}, nil
+			},
+			wantVal:   &user{ID: 1, Name: "foo"},
+			wantCalls: 1,
+		},
+		{
+			name: "not found is cached",
+			fn: func(ctx context.Context, key string) (*user, error) {
+				return nil, cache.ErrKeyNotExist
+			},
+			wantErr:   cache.ErrKeyNotExist,
+			wantCalls: 1,
+		},
+		{
+			name: "error is not cached",
+			fn: func(ctx context.Context, key string) (*user, error) {
+				return nil, errors.New("db error")
+			},
+			wantErr:   errors.New("db error"),
+			wantCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int64
+			fn := func(ctx context.Context, key string) (*user, error) {
+				atomic.AddInt64(&calls, 1)
+				return tc.fn(ctx, key)
+			}
+			l := NewLoader[*user](newLRUCache(t))
+
+			for i := 0; i < 2; i++ {
+				val, err := l.GetOrLoad(context.Background(), "foo", fn)
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, tc.wantVal, val)
+			}
+			assert.Equal(t, tc.wantCalls, atomic.LoadInt64(&calls))
+		})
+	}
+}
+
+func TestLoader_GetOrLoadConcurrent(t *testing.T) {
+	var calls int64
+	l := NewLoader[user](newLRUCache(t))
+	fn := func(ctx context.Context, key string) (user, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return user{ID: 1, Name: key}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := l.GetOrLoad(context.Background(), "foo", fn)
+			assert.NoError(t, err)
+			assert.Equal(t, user{ID: 1, Name: "foo"}, val)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
+
+func TestLoader_GetOrLoadDecodeError(t *testing.T) {
+	var calls int64
+	c := newLRUCache(t)
+	l := NewLoader[*user](c)
+	fn := func(ctx context.Context, key string) (*user, error) {
+		atomic.AddInt64(&calls, 1)
+		return &user{ID: 1, Name: key}, nil
+	}
+
+	// 无法解码的旧数据视为未命中, 重新加载后覆盖
+	require.NoError(t, c.Set(context.Background(), "foo", []byte(`{"id":"bad"}`), time.Minute))
+	for i := 0; i < 2; i++ {
+		val, err := l.GetOrLoad(context.Background(), "foo", fn)
+		require.NoError(t, err)
+		assert.Equal(t, &user{ID: 1, Name: "foo"}, val)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
+
+func TestLoader_Delete(t *testing.T) {
+	var calls int64
+	l := NewLoader[string](newLRUCache(t), WithNotFoundExpiration(0))
+	fn := func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt64(&calls, 1)
+		return "bar", nil
+	}
+
+	_, err := l.GetOrLoad(context.Background(), "foo", fn)
+	require.NoError(t, err)
+	require.NoError(t, l.Delete(context.Background(), "foo"))
+	val, err := l.GetOrLoad(context.Background(), "foo", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", val)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+}
+
+func TestLoader_withJitter(t *testing.T) {
+	l := NewLoader[string](newLRUCache(t), WithJitter(0.5))
+	for i := 0; i < 100; i++ {
+		d := l.withJitter(time.Minute)
+		assert.GreaterOrEqual(t, d, time.Minute)
+		assert.Less(t, d, 90*time.Second)
+	}
+
+	l = NewLoader[string](newLRUCache(t), WithJitter(0))
+	assert.Equal(t, time.Minute, l.withJitter(time.Minute))
+}
+
+func TestLoader_Expiration(t *testing.T) {
+	var calls int64
+	c := newExpiringCache(t)
+	l := NewLoader[string](c, WithExpiration(50*time.Millisecond), WithJitter(0))
+	fn := func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt64(&calls, 1)
+		return "bar", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		val, err := l.GetOrLoad(context.Background(), "foo", fn)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", val)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// 缓存过期后重新加载
+	time.Sleep(60 * time.Millisecond)
+	_, err := l.GetOrLoad(context.Background(), "foo", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 50 * time.Millisecond}, c.Expirations())
+}
+
+func TestLoader_NotFoundExpiration(t *testing.T) {
+	var calls int64
+	c := newExpiringCache(t)
+	l := NewLoader[string](c, WithNotFoundExpiration(50*time.Millisecond), WithJitter(0))
+	fn := func(ctx context.Context, key string) (string, error) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			return "", cache.ErrKeyNotExist
+		}
+		return "bar", nil
+	}
+
+	// 空值缓存期间不会重新加载
+	for i := 0; i < 2; i++ {
+		_, err := l.GetOrLoad(context.Background(), "foo", fn)
+		assert.Equal(t, cache.ErrKeyNotExist, err)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// 空值过期后重新加载
+	time.Sleep(60 * time.Millisecond)
+	val, err := l.GetOrLoad(context.Background(), "foo", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", val)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+	assert.Equal(t, 50*time.Millisecond, c.Expirations()[0])
+}
+
+func TestLoader_ExpirationJitter(t *testing.T) {
+	c := newExpiringCache(t)
+	l := NewLoader[string](c, WithExpiration(time.Minute), WithNotFoundExpiration(time.Second), WithJitter(0.5))
+	fn := func(ctx context.Context, key string) (string, error) {
+		if key == "missing" {
+			return "", cache.ErrKeyNotExist
+		}
+		return key, nil
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err := l.GetOrLoad(context.Background(), strconv.Itoa(i), fn)
+		require.NoError(t, err)
+	}
+	_, err := l.GetOrLoad(context.Background(), "missing", fn)
+	require.Equal(t, cache.ErrKeyNotExist, err)
+
+	// 写入缓存的过期时间在 [d, 1.5d) 之间随机浮动
+	expirations := c.Expirations()
+	require.Len(t, expirations, 11)
+	for _, d := range expirations[:10] {
+		assert.GreaterOrEqual(t, d, time.Minute)
+		assert.Less(t, d, 90*time.Second)
+	}
+	assert.NotEqual(t, expirations[0], expirations[1])
+	assert.GreaterOrEqual(t, expirations[10], time.Second)
+	assert.Less(t, expirations[10], 1500*time.Millisecond)
+}
+
+func TestLoader_GetOrLoadCanceled(t *testing.T) {
+	l := NewLoader[string](newLRUCache(t))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fn := func(ctx context.Context, key string) (string, error) {
+		close(started)
+		select {
+		case <-release:
+			return "bar", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	// 第一个调用方取消, 只影响它自己, 其他等待的调用方仍拿到加载结果
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := l.GetOrLoad(ctx, "foo", fn)
+		errCh <- err
+	}()
+	<-started
+
+	valCh := make(chan string, 1)
+	go func() {
+		val, err := l.GetOrLoad(context.Background(), "foo", fn)
+		assert.NoError(t, err)
+		valCh <- val
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+	close(release)
+	assert.Equal(t, "bar", <-valCh)
+}
+
+func TestLoader_LoadTimeout(t *testing.T) {
+	l := NewLoader[string](newLRUCache(t), WithLoadTimeout(20*time.Millisecond))
+	_, err := l.GetOrLoad(context.Background(), "foo", func(ctx context.Context, key string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
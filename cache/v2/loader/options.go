@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"time"
+
+	"github.com/apus-run/sea-kit/encoding"
+	"github.com/apus-run/sea-kit/encoding/json"
+)
+
+// Option 加载器配置
+type Option func(*options)
+
+type options struct {
+	// codec 缓存值的编解码方式
+	codec encoding.Codec
+	// expiration 数据的缓存时间
+	expiration time.Duration
+	// notFoundExpiration 数据不存在时的缓存时间, 为 0 时不缓存空值
+	notFoundExpiration time.Duration
+	// jitter 过期时间的随机浮动比例, 避免大量 key 同时过期
+	jitter float64
+	// loadTimeout 合并后的一次加载的超时时间
+	loadTimeout time.Duration
+}
+
+// WithCodec 设置缓存值的编解码方式
+func WithCodec(codec encoding.Codec) Option {
+	return func(o *options) {
+		if codec == nil {
+			return
+		}
+		o.codec = codec
+	}
+}
+
+// WithExpiration 设置数据的缓存时间
+func WithExpiration(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			return
+		}
+		o.expiration = d
+	}
+}
+
+// WithNotFoundExpiration 设置数据不存在时的缓存时间, 传入 0 关闭空值缓存
+func WithNotFoundExpiration(d time.Duration) Option {
+	return func(o *options) {
+		if d < 0 {
+			return
+		}
+		o.notFoundExpiration = d
+	}
+}
+
+// WithJitter 设置过期时间的随机浮动比例, 例如 0.1 表示在原过期时间上随机增加 0~10%
+func WithJitter(ratio float64) Option {
+	return func(o *options) {
+		if ratio < 0 {
+			return
+		}
+		o.jitter = ratio
+	}
+}
+
+// WithLoadTimeout 设置一次加载的超时时间, 默认 10s.
+// 并发的加载会合并为一次, 不受单个调用方 ctx 取消的影响, 只受该超时时间限制
+func WithLoadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			return
+		}
+		o.loadTimeout = d
+	}
+}
+
+// DefaultOptions .
+func DefaultOptions() *options {
+	return &options{
+		codec:              encoding.GetCodec(json.Name),
+		expiration:         10 * time.Minute,
+		notFoundExpiration: 30 * time.Second,
+		jitter:             0.1,
+		loadTimeout:        10 * time.Second,
+	}
+}
+
+func Apply(opts ...Option) *options {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
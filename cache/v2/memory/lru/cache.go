@@ -36,9 +36,8 @@ func (c *Cache) Set(ctx context.Context, key string, val any, expiration time.Du
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	if ok := c.client.Add(key, val); !ok {
-		return errors.New("添加失败")
-	}
+	// Add 返回值表示是否发生了淘汰, 不代表添加失败
+	c.client.Add(key, val)
 
 	return nil
 }
@@ -52,10 +51,7 @@ func (c *Cache) SetNX(ctx context.Context, key string, val any, expiration time.
 		return false, nil
 	}
 
-	ok := c.client.Add(key, val)
-	if !ok {
-		return false, errors.New("添加失败")
-	}
+	c.client.Add(key, val)
 
 	return true, nil
 }
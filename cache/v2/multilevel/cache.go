@@ -0,0 +1,221 @@
+package multilevel
+
+import (
+	"context"
+	"time"
+
+	"github.com/apus-run/sea-kit/cache/v2"
+)
+
+var _ cache.Cache = (*Cache)(nil)
+
+// Cache 多级缓存, L1 一般为进程内缓存, L2 一般为 redis.
+// 读先查 L1, 未命中再查 L2 并回填 L1, 回填的过期时间不超过 L2 剩余的过期时间;
+// 写和删除以 L2 为准并失效 L1, 同时通过 invalidation.Bus 通知其他节点失效各自的 L1.
+type Cache struct {
+	local  cache.Cache
+	remote cache.Cache
+
+	opts        *options
+	unsubscribe func()
+}
+
+// NewCache 创建多级缓存, 配置了 WithInvalidationBus 时会订阅失效消息, 使用完需调用 Close
+func NewCache(local, remote cache.Cache, opts ...Option) *Cache {
+	c := &Cache{
+		local:  local,
+		remote: remote,
+		opts:   Apply(opts...),
+	}
+
+	if c.opts.bus != nil {
+		c.unsubscribe = c.opts.bus.Subscribe(func(keys []string) {
+			_, _ = c.local.Delete(context.Background(), keys...)
+		})
+	}
+
+	return c
+}
+
+// Close 取消订阅失效消息, 不会关闭 Bus
+func (c *Cache) Close() error {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	return nil
+}
+
+// Set 写入 L2 后失效 L1, L1 只存放从 L2 读回的值, 保证两级返回同一种类型
+func (c *Cache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	if err := c.remote.Set(ctx, key, val, expiration); err != nil {
+		return err
+	}
+	return c.invalidate(ctx, key)
+}
+
+func (c *Cache) SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	ok, err := c.remote.SetNX(ctx, key, val, expiration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, c.invalidate(ctx, key)
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (val cache.Value) {
+	if v, ok := c.getLocal(ctx, key); ok {
+		val.Value = v
+		return
+	}
+
+	val = c.remote.Get(ctx, key)
+	if val.Error != nil {
+		return
+	}
+	// 回填失败不影响本次读取
+	if expiration := c.localExpiration(ctx, key); expiration > 0 {
+		_ = c.local.Set(ctx, key, &entry{
+			value:    val.Value,
+			expireAt: time.Now().Add(expiration),
+		}, expiration)
+	}
+	return
+}
+
+func (c *Cache) GetSet(ctx context.Context, key string, val string) (result cache.Value) {
+	result = c.remote.GetSet(ctx, key, val)
+	if result.Error != nil && !result.KeyNotFound() {
+		return
+	}
+	if err := c.invalidate(ctx, key); err != nil {
+		result.Error = err
+	}
+	return
+}
+
+func (c *Cache) Delete(ctx context.Context, key ...string) (int64, error) {
+	n, err := c.remote.Delete(ctx, key...)
+	if err != nil {
+		return n, err
+	}
+	if _, err = c.local.Delete(ctx, key...); err != nil {
+		return n, err
+	}
+	return n, c.publish(ctx, key...)
+}
+
+// LPush 列表类型只存放在 L2, 操作后失效 L1
+func (c *Cache) LPush(ctx context.Context, key string, val ...any) (int64, error) {
+	n, err := c.remote.LPush(ctx, key, val...)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *Cache) LPop(ctx context.Context, key string) (val cache.Value) {
+	val = c.remote.LPop(ctx, key)
+	if val.Error != nil {
+		return
+	}
+	if err := c.invalidate(ctx, key); err != nil {
+		val.Error = err
+	}
+	return
+}
+
+// SAdd 集合类型只存放在 L2, 操作后失效 L1
+func (c *Cache) SAdd(ctx context.Context, key string, members ...any) (int64, error) {
+	n, err := c.remote.SAdd(ctx, key, members...)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *Cache) SRem(ctx context.Context, key string, members ...any) (int64, error) {
+	n, err := c.remote.SRem(ctx, key, members...)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+// IncrBy 计数以 L2 为准, 操作后失效 L1
+func (c *Cache) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	n, err := c.remote.IncrBy(ctx, key, value)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *Cache) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	n, err := c.remote.DecrBy(ctx, key, value)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *Cache) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
+	n, err := c.remote.IncrByFloat(ctx, key, value)
+	if err != nil {
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+// invalidate 删除本地缓存并通知其他节点
+func (c *Cache) invalidate(ctx context.Context, keys ...string) error {
+	if _, err := c.local.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return c.publish(ctx, keys...)
+}
+
+// publish 通知其他节点失效 L1, 未配置 Bus 时直接返回
+func (c *Cache) publish(ctx context.Context, keys ...string) error {
+	if c.opts.bus == nil || len(keys) == 0 {
+		return nil
+	}
+	return c.opts.bus.Publish(ctx, keys...)
+}
+
+// getLocal 读取 L1, 过期或不是由本缓存写入的值会被删除并视为未命中,
+// 这样即使 L1 本身不支持过期 (如 lru), 丢失失效消息也不会让 L1 永久脏读
+func (c *Cache) getLocal(ctx context.Context, key string) (any, bool) {
+	val := c.local.Get(ctx, key)
+	if val.Error != nil {
+		return nil, false
+	}
+	e, ok := val.Value.(*entry)
+	if ok && time.Now().Before(e.expireAt) {
+		return e.value, true
+	}
+	_, _ = c.local.Delete(ctx, key)
+	return nil, false
+}
+
+// localExpiration 计算回填 L1 的过期时间, 不超过 L2 中剩余的过期时间.
+// 返回 0 表示不回填
+func (c *Cache) localExpiration(ctx context.Context, key string) time.Duration {
+	expiration := c.opts.localExpiration
+	r, ok := c.remote.(cache.TTLCache)
+	if !ok {
+		return expiration
+	}
+	ttl, err := r.TTL(ctx, key)
+	if err != nil {
+		return 0
+	}
+	if ttl > 0 && ttl < expiration {
+		return ttl
+	}
+	return expiration
+}
+
+// entry L1 中存放的值, 自带过期时间
+type entry struct {
+	value    any
+	expireAt time.Time
+}
@@ -0,0 +1,48 @@
+//go:build e2e
+
+package multilevel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	redisCache "github.com/apus-run/sea-kit/cache/v2/redis"
+	"github.com/apus-run/sea-kit/redisx/invalidation"
+)
+
+func TestCache_e2e_Invalidate(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, rdb.Ping(context.Background()).Err())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	bus1, bus2 := invalidation.NewRedisBus(rdb), invalidation.NewRedisBus(rdb)
+	defer bus1.Close()
+	defer bus2.Close()
+
+	local1, local2 := newLRUCache(t), newLRUCache(t)
+	node1 := NewCache(local1, redisCache.NewCache(rdb), WithInvalidationBus(bus1))
+	defer node1.Close()
+	node2 := NewCache(local2, redisCache.NewCache(rdb), WithInvalidationBus(bus2))
+	defer node2.Close()
+
+	require.NoError(t, node1.Set(ctx, "multilevel", "v1", time.Minute))
+	assert.Equal(t, "v1", node2.Get(ctx, "multilevel").Value)
+
+	require.NoError(t, node1.Set(ctx, "multilevel", "v2", time.Minute))
+	assert.Eventually(t, func() bool {
+		return local2.Get(ctx, "multilevel").KeyNotFound()
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "v2", node2.Get(ctx, "multilevel").Value)
+
+	_, err := node1.Delete(ctx, "multilevel")
+	require.NoError(t, err)
+}
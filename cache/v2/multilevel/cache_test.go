@@ -0,0 +1,223 @@
+package multilevel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/cache/v2"
+	"github.com/apus-run/sea-kit/cache/v2/memory/lru"
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+func newLRUCache(t *testing.T) *lru.Cache {
+	l, err := simplelru.NewLRU[string, any](16, nil)
+	require.NoError(t, err)
+	return lru.NewCache(l)
+}
+
+func TestCache_Get(t *testing.T) {
+	testCases := []struct {
+		name   string
+		before func(ctx context.Context, c *Cache, remote cache.Cache)
+		after  func(ctx context.Context, t *testing.T, local cache.Cache)
+
+		key string
+
+		wantVal any
+		wantErr error
+	}{
+		{
+			name: "hit local",
+			before: func(ctx context.Context, c *Cache, remote cache.Cache) {
+				_ = remote.Set(ctx, "name", "local", time.Minute)
+				_ = c.Get(ctx, "name")
+				_ = remote.Set(ctx, "name", "remote", time.Minute)
+			},
+			after: func(ctx context.Context, t *testing.T, local cache.Cache) {},
+			key:   "name",
+
+			wantVal: "local",
+		},
+		{
+			name: "hit remote and fill local",
+			before: func(ctx context.Context, c *Cache, remote cache.Cache) {
+				_ = remote.Set(ctx, "name", "remote", time.Minute)
+			},
+			after: func(ctx context.Context, t *testing.T, local cache.Cache) {
+				val := local.Get(ctx, "name")
+				require.NoError(t, val.Error)
+				e, ok := val.Value.(*entry)
+				require.True(t, ok)
+				assert.Equal(t, "remote", e.value)
+			},
+			key: "name",
+
+			wantVal: "remote",
+		},
+		{
+			name:   "key not found",
+			before: func(ctx context.Context, c *Cache, remote cache.Cache) {},
+			after: func(ctx context.Context, t *testing.T, local cache.Cache) {
+				assert.True(t, local.Get(ctx, "name").KeyNotFound())
+			},
+			key: "name",
+
+			wantErr: cache.ErrKeyNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			local, remote := newLRUCache(t), newLRUCache(t)
+			c := NewCache(local, remote)
+			defer c.Close()
+			tc.before(ctx, c, remote)
+
+			val := c.Get(ctx, tc.key)
+			assert.Equal(t, tc.wantErr, val.Error)
+			if val.Error != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val.Value)
+			tc.after(ctx, t, local)
+		})
+	}
+}
+
+func TestCache_SetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	local, remote := newLRUCache(t), newLRUCache(t)
+	c := NewCache(local, remote)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "name", "foo", time.Minute))
+	assert.True(t, local.Get(ctx, "name").KeyNotFound())
+	assert.Equal(t, "foo", remote.Get(ctx, "name").Value)
+	assert.Equal(t, "foo", c.Get(ctx, "name").Value)
+	assert.False(t, local.Get(ctx, "name").KeyNotFound())
+
+	ok, err := c.SetNX(ctx, "name", "bar", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	n, err := c.Delete(ctx, "name")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.True(t, local.Get(ctx, "name").KeyNotFound())
+	assert.True(t, remote.Get(ctx, "name").KeyNotFound())
+}
+
+func TestCache_IncrByInvalidateLocal(t *testing.T) {
+	ctx := context.Background()
+	local, remote := newLRUCache(t), newLRUCache(t)
+	c := NewCache(local, remote)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "counter", int64(1), time.Minute))
+	assert.Equal(t, int64(1), c.Get(ctx, "counter").Value)
+	n, err := c.IncrBy(ctx, "counter", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.True(t, local.Get(ctx, "counter").KeyNotFound())
+
+	val := c.Get(ctx, "counter")
+	require.NoError(t, val.Error)
+	assert.Equal(t, int64(3), val.Value)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	bus := invalidation.NewMemoryBus()
+	remote := newLRUCache(t)
+	local1, local2 := newLRUCache(t), newLRUCache(t)
+	node1 := NewCache(local1, remote, WithInvalidationBus(bus))
+	defer node1.Close()
+	node2 := NewCache(local2, remote, WithInvalidationBus(bus))
+	defer node2.Close()
+
+	require.NoError(t, node1.Set(ctx, "a", "1", time.Minute))
+	assert.Equal(t, "1", node2.Get(ctx, "a").Value)
+	assert.False(t, local2.Get(ctx, "a").KeyNotFound())
+
+	// 其他节点写入后失效本节点的 L1
+	require.NoError(t, node1.Set(ctx, "a", "2", time.Minute))
+	assert.True(t, local2.Get(ctx, "a").KeyNotFound())
+	assert.Equal(t, "2", node2.Get(ctx, "a").Value)
+
+	// Close 之后不再接收失效消息
+	require.NoError(t, node2.Close())
+	_, err := node1.Delete(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, local2.Get(ctx, "a").KeyNotFound())
+}
+
+// ttlCache 带剩余过期时间的 L2
+type ttlCache struct {
+	*lru.Cache
+	ttl time.Duration
+	err error
+}
+
+func (c *ttlCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.ttl, c.err
+}
+
+func TestCache_LocalExpiration(t *testing.T) {
+	testCases := []struct {
+		name   string
+		remote func(t *testing.T) cache.Cache
+		opts   []Option
+
+		wantFill bool
+	}{
+		{
+			name: "capped by remote ttl",
+			remote: func(t *testing.T) cache.Cache {
+				return &ttlCache{Cache: newLRUCache(t), ttl: 50 * time.Millisecond}
+			},
+			wantFill: true,
+		},
+		{
+			name: "local expiration",
+			remote: func(t *testing.T) cache.Cache {
+				return newLRUCache(t)
+			},
+			opts:     []Option{WithLocalExpiration(50 * time.Millisecond)},
+			wantFill: true,
+		},
+		{
+			name: "remote key expired",
+			remote: func(t *testing.T) cache.Cache {
+				return &ttlCache{Cache: newLRUCache(t), err: cache.ErrKeyNotExist}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			local, remote := newLRUCache(t), tc.remote(t)
+			c := NewCache(local, remote, tc.opts...)
+			defer c.Close()
+
+			require.NoError(t, remote.Set(ctx, "name", "foo", time.Minute))
+			assert.Equal(t, "foo", c.Get(ctx, "name").Value)
+			assert.Equal(t, tc.wantFill, !local.Get(ctx, "name").KeyNotFound())
+			if !tc.wantFill {
+				return
+			}
+
+			// 丢失失效消息时, L1 最多在过期时间内返回旧值
+			require.NoError(t, remote.Set(ctx, "name", "bar", time.Minute))
+			assert.Equal(t, "foo", c.Get(ctx, "name").Value)
+			time.Sleep(60 * time.Millisecond)
+			assert.Equal(t, "bar", c.Get(ctx, "name").Value)
+		})
+	}
+}
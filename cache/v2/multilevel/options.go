@@ -0,0 +1,50 @@
+package multilevel
+
+import (
+	"time"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+// Option 多级缓存配置
+type Option func(*options)
+
+type options struct {
+	// bus 用于跨节点失效本地缓存, 为 nil 时不做跨节点失效
+	bus invalidation.Bus
+	// localExpiration 从 L2 回填 L1 时使用的最长过期时间
+	localExpiration time.Duration
+}
+
+// WithInvalidationBus 设置跨节点失效本地缓存的消息总线, 例如 redisx/invalidation.RedisBus
+func WithInvalidationBus(bus invalidation.Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
+}
+
+// WithLocalExpiration 设置从 L2 回填 L1 时的最长过期时间,
+// L2 实现了 cache.TTLCache 时取两者中较小的一个
+func WithLocalExpiration(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			return
+		}
+		o.localExpiration = d
+	}
+}
+
+// DefaultOptions .
+func DefaultOptions() *options {
+	return &options{
+		localExpiration: time.Minute,
+	}
+}
+
+func Apply(opts ...Option) *options {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
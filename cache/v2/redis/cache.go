@@ -10,7 +10,10 @@ import (
 	"github.com/apus-run/sea-kit/cache/v2"
 )
 
-var _ cache.Cache = (*Cache)(nil)
+var (
+	_ cache.Cache    = (*Cache)(nil)
+	_ cache.TTLCache = (*Cache)(nil)
+)
 
 type Cache struct {
 	client redis.Cmdable
@@ -40,6 +43,20 @@ func (c *Cache) Get(ctx context.Context, key string) (val cache.Value) {
 	return
 }
 
+func (c *Cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -2:
+		return 0, cache.ErrKeyNotExist
+	case -1:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
 func (c *Cache) GetSet(ctx context.Context, key string, val string) (result cache.Value) {
 	result.Value, result.Error = c.client.GetSet(ctx, key, val).Result()
 	if result.Error != nil && errors.Is(result.Error, redis.Nil) {
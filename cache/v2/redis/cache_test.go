@@ -127,6 +127,55 @@ func TestCache_Get(t *testing.T) {
 	}
 }
 
+func TestCache_TTL(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		ttl time.Duration
+		err error
+
+		wantTTL time.Duration
+		wantErr error
+	}{
+		{
+			name:    "remaining ttl",
+			ttl:     time.Minute,
+			wantTTL: time.Minute,
+		},
+		{
+			name: "no expiration",
+			ttl:  -1,
+		},
+		{
+			name:    "key not exist",
+			ttl:     -2,
+			wantErr: cache.ErrKeyNotExist,
+		},
+		{
+			name:    "timeout",
+			err:     context.DeadlineExceeded,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := mocks.NewMockCmdable(ctrl)
+			status := redis.NewDurationCmd(context.Background(), time.Second)
+			status.SetVal(tc.ttl)
+			status.SetErr(tc.err)
+			cmd.EXPECT().
+				TTL(context.Background(), "name").
+				Return(status)
+			c := NewCache(cmd)
+			ttl, err := c.TTL(context.Background(), "name")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTTL, ttl)
+		})
+	}
+}
+
 func TestCache_SetNX(t *testing.T) {
 	testCase := []struct {
 		name       string
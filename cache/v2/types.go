@@ -45,6 +45,12 @@ type Cache interface {
 	IncrByFloat(ctx context.Context, key string, value float64) (float64, error)
 }
 
+// TTLCache 可以查询 key 剩余过期时间的缓存, 多级缓存用它限制 L1 的过期时间
+type TTLCache interface {
+	// TTL 返回 key 的剩余过期时间, 永不过期时返回 0, key 不存在时返回 ErrKeyNotExist
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
 // Value 代表一个从缓存中读取出来的值
 type Value struct {
 	collection.AnyValue
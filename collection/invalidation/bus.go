@@ -0,0 +1,69 @@
+// Package invalidation 提供进程内缓存的跨节点失效通知.
+//
+// 本地缓存在删除 key 时通过 Bus 广播, 其他节点收到通知后删除各自的副本,
+// 避免某个节点写入后其他节点在过期前一直读到旧数据.
+package invalidation
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler 处理收到的失效 key
+type Handler func(keys []string)
+
+// Bus 失效消息总线
+type Bus interface {
+	// Publish 广播失效的 key
+	Publish(ctx context.Context, keys ...string) error
+	// Subscribe 注册失效回调, 返回取消订阅的函数
+	Subscribe(handler Handler) (unsubscribe func())
+}
+
+var _ Bus = (*MemoryBus)(nil)
+
+// MemoryBus 进程内的消息总线, 挂在同一个 MemoryBus 上的缓存相当于不同节点, 一般用于测试
+type MemoryBus struct {
+	mu       sync.RWMutex
+	seq      int
+	handlers map[int]Handler
+}
+
+// NewMemoryBus 创建进程内消息总线
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		handlers: make(map[int]Handler),
+	}
+}
+
+// Publish 同步通知所有订阅者
+func (b *MemoryBus) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(keys)
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.seq
+	b.seq++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
@@ -0,0 +1,34 @@
+package invalidation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBus(t *testing.T) {
+	bus := NewMemoryBus()
+
+	var got1, got2 []string
+	unsubscribe1 := bus.Subscribe(func(keys []string) {
+		got1 = append(got1, keys...)
+	})
+	bus.Subscribe(func(keys []string) {
+		got2 = append(got2, keys...)
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), "a", "b"))
+	assert.Equal(t, []string{"a", "b"}, got1)
+	assert.Equal(t, []string{"a", "b"}, got2)
+
+	unsubscribe1()
+	require.NoError(t, bus.Publish(context.Background(), "c"))
+	assert.Equal(t, []string{"a", "b"}, got1)
+	assert.Equal(t, []string{"a", "b", "c"}, got2)
+
+	// 空 key 不广播
+	require.NoError(t, bus.Publish(context.Background()))
+	assert.Equal(t, []string{"a", "b", "c"}, got2)
+}
@@ -2,9 +2,12 @@ package lru_cache
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 // LRUCache is a concurrent fixed size cache that evicts elements in LRU order as well as by TTL.
@@ -16,6 +19,9 @@ type LRUCache struct {
 	ttl     time.Duration
 	TimeNow func() time.Time
 	onEvict EvictCallback
+	bus     invalidation.Bus
+
+	unsubscribe func() // unsubscribes from the bus
 }
 
 // NewLRUCache creates a new LRU cache with default options.
@@ -31,13 +37,29 @@ func NewLRUWithOptions(maxSize int, opts *Options) *LRUCache {
 	if opts.TimeNow == nil {
 		opts.TimeNow = time.Now
 	}
-	return &LRUCache{
+	c := &LRUCache{
 		lru:     list.New(),
 		cache:   make(map[string]*list.Element, opts.InitialCapacity),
 		ttl:     opts.TTL,
 		maxSize: maxSize,
 		TimeNow: opts.TimeNow,
 		onEvict: opts.OnEvict,
+		bus:     opts.InvalidationBus,
+	}
+	if c.bus != nil {
+		c.unsubscribe = c.bus.Subscribe(func(keys []string) {
+			for _, key := range keys {
+				c.del(key)
+			}
+		})
+	}
+	return c
+}
+
+// Stop unsubscribes the cache from the invalidation bus, the cache can still be used locally.
+func (c *LRUCache) Stop() {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
 	}
 }
 
@@ -131,8 +153,17 @@ func (c *LRUCache) putWithMutexHold(key string, value any, elt *list.Element) an
 	return nil
 }
 
-// Del deletes a key, value pair associated with a key
+// Del deletes a key, value pair associated with a key.
+// If an invalidation bus is configured the key is evicted on the other nodes as well.
 func (c *LRUCache) Del(key string) {
+	c.del(key)
+	if c.bus != nil {
+		_ = c.bus.Publish(context.Background(), key)
+	}
+}
+
+// del deletes a key locally
+func (c *LRUCache) del(key string) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 func TestLRU(t *testing.T) {
@@ -46,6 +48,26 @@ func TestLRU(t *testing.T) {
 	assert.Nil(t, cache.Get("A"))
 }
 
+func TestLRUWithInvalidationBus(t *testing.T) {
+	bus := invalidation.NewMemoryBus()
+	node1 := NewLRUWithOptions(4, &Options{InvalidationBus: bus})
+	node2 := NewLRUWithOptions(4, &Options{InvalidationBus: bus})
+
+	node1.Put("A", "Foo")
+	node2.Put("A", "Foo")
+	node2.Put("B", "Bar")
+
+	node1.Del("A")
+	assert.Nil(t, node1.Get("A"))
+	assert.Nil(t, node2.Get("A"))
+	assert.Equal(t, "Bar", node2.Get("B"))
+
+	// Stopped caches no longer receive invalidations.
+	node2.Stop()
+	node1.Del("B")
+	assert.Equal(t, "Bar", node2.Get("B"))
+}
+
 func TestCompareAndSwap(t *testing.T) {
 	cache := NewLRUCache(2)
 
@@ -2,6 +2,8 @@ package lru_cache
 
 import (
 	"time"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 // A Cache is a generalized interface to a cache.  See cache.LRU for a specific
@@ -45,6 +47,10 @@ type Options struct {
 
 	// TimeNow is used to override the behavior of default time.Now(), e.g. in tests.
 	TimeNow func() time.Time
+
+	// InvalidationBus is an optional bus used to evict keys cluster-wide. Keys deleted
+	// with Del are published to the bus, and keys received from the bus are evicted locally.
+	InvalidationBus invalidation.Bus
 }
 
 // EvictCallback is a type for notifying applications when an item is
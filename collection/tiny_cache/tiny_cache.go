@@ -2,11 +2,13 @@ package tiny_cache
 
 import (
 	"container/list"
+	"context"
 	"log"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/apus-run/sea-kit/collection/invalidation"
 	collection "github.com/apus-run/sea-kit/collection/timingwheel"
 	"github.com/apus-run/sea-kit/mathx"
 	"github.com/apus-run/sea-kit/syncx"
@@ -38,6 +40,8 @@ type (
 		barrier        syncx.SingleFlight
 		unstableExpiry mathx.Unstable
 		stats          *cacheStat
+		bus            invalidation.Bus
+		unsubscribe    func()
 	}
 )
 
@@ -66,18 +70,44 @@ func NewCache(expire time.Duration, opts ...CacheOption) (*Cache, error) {
 			return
 		}
 
-		cache.Del(key)
+		// expired items are only removed locally
+		cache.del(key)
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	cache.timingWheel = timingWheel
+	if cache.bus != nil {
+		cache.unsubscribe = cache.bus.Subscribe(func(keys []string) {
+			for _, key := range keys {
+				cache.del(key)
+			}
+		})
+	}
 	return cache, nil
 }
 
+// Stop stops the timing wheel and unsubscribes c from the invalidation bus.
+// Items no longer expire after stopping.
+func (c *Cache) Stop() {
+	c.timingWheel.Stop()
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+}
+
 // Del deletes the item with the given key from c.
+// If an invalidation bus is configured the key is deleted on the other nodes as well.
 func (c *Cache) Del(key string) {
+	c.del(key)
+	if c.bus != nil {
+		_ = c.bus.Publish(context.Background(), key)
+	}
+}
+
+// del deletes the item with the given key from c locally.
+func (c *Cache) del(key string) {
 	c.lock.Lock()
 	delete(c.data, key)
 	c.lruCache.remove(key)
@@ -198,6 +228,13 @@ func WithName(name string) CacheOption {
 	}
 }
 
+// WithInvalidationBus customizes a Cache to delete keys cluster-wide through the given bus.
+func WithInvalidationBus(bus invalidation.Bus) CacheOption {
+	return func(cache *Cache) {
+		cache.bus = bus
+	}
+}
+
 type (
 	lru interface {
 		add(key string)
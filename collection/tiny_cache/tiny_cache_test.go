@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 var errDummy = errors.New("dummy")
@@ -28,6 +30,32 @@ func TestCacheSet(t *testing.T) {
 	assert.Equal(t, "second element", value)
 }
 
+func TestCacheDelWithInvalidationBus(t *testing.T) {
+	bus := invalidation.NewMemoryBus()
+	node1, err := NewCache(time.Second*2, WithInvalidationBus(bus))
+	assert.Nil(t, err)
+	node2, err := NewCache(time.Second*2, WithInvalidationBus(bus))
+	assert.Nil(t, err)
+
+	node1.Set("first", "first element")
+	node2.Set("first", "first element")
+	node2.Set("second", "second element")
+	node1.Del("first")
+
+	_, ok := node1.Get("first")
+	assert.False(t, ok)
+	_, ok = node2.Get("first")
+	assert.False(t, ok)
+	_, ok = node2.Get("second")
+	assert.True(t, ok)
+
+	// stopped caches no longer receive invalidations
+	node2.Stop()
+	node1.Del("second")
+	_, ok = node2.Get("second")
+	assert.True(t, ok)
+}
+
 func TestCacheDel(t *testing.T) {
 	cache, err := NewCache(time.Second * 2)
 	assert.Nil(t, err)
@@ -1,14 +1,19 @@
 package ttl_cache
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 // item represents a cache item with a value and an expiration time.
 type item[V any] struct {
 	value  V
 	expiry time.Time
+	name   string // fmt.Sprint form of the key, set only when the keys are indexed.
 }
 
 // isExpired checks if the cache item has expired.
@@ -22,14 +27,48 @@ type TTLCache[K comparable, V any] struct {
 	items map[K]item[V]    // The map storing cache items.
 	mu    sync.Mutex       // Mutex for controlling concurrent access to the cache.
 	stop  chan interface{} // Channel to stop the goroutine that removes expired items.
+
+	bus         invalidation.Bus // Optional bus to remove keys cluster-wide.
+	unsubscribe func()           // Unsubscribes from the bus.
+	// index maps the fmt.Sprint form of non-string keys to the keys, so the keys
+	// received from the bus are removed without scanning the items.
+	index map[string]map[K]struct{}
+}
+
+// Option customizes a TTLCache.
+type Option func(*options)
+
+type options struct {
+	bus invalidation.Bus
+}
+
+// WithInvalidationBus removes keys cluster-wide through the given bus. Keys are
+// published in their fmt.Sprint form, all the keys with the same form are removed.
+func WithInvalidationBus(bus invalidation.Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
 }
 
 // NewTTL creates a new TTLCache instance and starts a goroutine to periodically
 // remove expired items every 5 seconds.
-func NewTTL[K comparable, V any]() *TTLCache[K, V] {
+func NewTTL[K comparable, V any](opts ...Option) *TTLCache[K, V] {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	c := &TTLCache[K, V]{
 		items: make(map[K]item[V]),
 		stop:  make(chan interface{}),
+		bus:   o.bus,
+	}
+
+	if c.bus != nil {
+		if _, ok := any(*new(K)).(string); !ok {
+			c.index = make(map[string]map[K]struct{})
+		}
+		c.unsubscribe = c.bus.Subscribe(c.evict)
 	}
 
 	go func() {
@@ -47,7 +86,7 @@ func NewTTL[K comparable, V any]() *TTLCache[K, V] {
 				// Iterate over the cache items and delete expired ones.
 				for key, item := range c.items {
 					if item.isExpired() {
-						delete(c.items, key)
+						c.delete(key, item)
 					}
 				}
 
@@ -61,17 +100,33 @@ func NewTTL[K comparable, V any]() *TTLCache[K, V] {
 
 func (c *TTLCache[K, V]) Stop() {
 	close(c.stop)
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
 }
 
 // Set adds a new item to the cache with the specified key, value, and
 // time-to-live (TTL).
 func (c *TTLCache[K, V]) Set(key K, value V, ttl time.Duration) {
+	it := item[V]{
+		value:  value,
+		expiry: time.Now().Add(ttl),
+	}
+	if c.index != nil {
+		it.name = fmt.Sprint(key)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items[key] = item[V]{
-		value:  value,
-		expiry: time.Now().Add(ttl),
+	c.items[key] = it
+	if c.index != nil {
+		keys, ok := c.index[it.name]
+		if !ok {
+			keys = make(map[K]struct{}, 1)
+			c.index[it.name] = keys
+		}
+		keys[key] = struct{}{}
 	}
 }
 
@@ -89,7 +144,7 @@ func (c *TTLCache[K, V]) Get(key K) (V, bool) {
 	if item.isExpired() {
 		// If the item has expired, remove it from the cache and return the
 		// value and false.
-		delete(c.items, key)
+		c.delete(key, item)
 		return item.value, false
 	}
 
@@ -97,13 +152,52 @@ func (c *TTLCache[K, V]) Get(key K) (V, bool) {
 	return item.value, true
 }
 
-// Remove removes the item with the specified key from the cache.
+// Remove removes the item with the specified key from the cache. If an invalidation
+// bus is configured, the key is removed on the other nodes as well.
 func (c *TTLCache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	// Delete the item with the given key from the cache.
+	if item, found := c.items[key]; found {
+		c.delete(key, item)
+	}
+	c.mu.Unlock()
+
+	if c.bus != nil {
+		_ = c.bus.Publish(context.Background(), fmt.Sprint(key))
+	}
+}
+
+// evict removes the keys received from the invalidation bus.
+func (c *TTLCache[K, V]) evict(keys []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Delete the item with the given key from the cache.
+	for _, s := range keys {
+		if c.index == nil {
+			// The keys are strings.
+			key := any(s).(K)
+			if item, found := c.items[key]; found {
+				c.delete(key, item)
+			}
+			continue
+		}
+		for key := range c.index[s] {
+			c.delete(key, c.items[key])
+		}
+	}
+}
+
+// delete removes the item and its index entry, it must be called with the lock held.
+func (c *TTLCache[K, V]) delete(key K, item item[V]) {
 	delete(c.items, key)
+	if c.index == nil {
+		return
+	}
+	keys := c.index[item.name]
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(c.index, item.name)
+	}
 }
 
 // Pop removes and returns the item with the specified key from the cache.
@@ -118,7 +212,7 @@ func (c *TTLCache[K, V]) Pop(key K) (V, bool) {
 	}
 
 	// If the key is found, delete the item from the cache.
-	delete(c.items, key)
+	c.delete(key, item)
 
 	if item.isExpired() {
 		// If the item has expired, return the value and false.
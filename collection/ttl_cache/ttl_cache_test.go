@@ -0,0 +1,79 @@
+package ttl_cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+func TestTTLCache(t *testing.T) {
+	c := NewTTL[string, int]()
+	defer c.Stop()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, -time.Second)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+
+	c.Remove("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestTTLCacheWithInvalidationBus(t *testing.T) {
+	bus := invalidation.NewMemoryBus()
+	node1 := NewTTL[int, string](WithInvalidationBus(bus))
+	defer node1.Stop()
+	node2 := NewTTL[int, string](WithInvalidationBus(bus))
+	defer node2.Stop()
+
+	node1.Set(1, "a", time.Minute)
+	node2.Set(1, "a", time.Minute)
+	node2.Set(2, "b", time.Minute)
+
+	node1.Remove(1)
+	_, ok := node2.Get(1)
+	assert.False(t, ok)
+	v, ok := node2.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+}
+
+func TestTTLCacheWithInvalidationBusIndex(t *testing.T) {
+	type key struct {
+		ID   int
+		Name string
+	}
+	bus := invalidation.NewMemoryBus()
+	node1 := NewTTL[key, string](WithInvalidationBus(bus))
+	defer node1.Stop()
+	node2 := NewTTL[key, string](WithInvalidationBus(bus))
+	defer node2.Stop()
+
+	node2.Set(key{1, "a"}, "a", time.Minute)
+	node2.Set(key{2, "b"}, "b", time.Minute)
+	node2.Set(key{3, "c"}, "c", -time.Second)
+	assert.Len(t, node2.index, 3)
+
+	node1.Remove(key{1, "a"})
+	_, ok := node2.Get(key{1, "a"})
+	assert.False(t, ok)
+	v, ok := node2.Get(key{2, "b"})
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+
+	// expired and popped keys leave the index as well
+	_, ok = node2.Get(key{3, "c"})
+	assert.False(t, ok)
+	_, ok = node2.Pop(key{2, "b"})
+	assert.True(t, ok)
+	assert.Empty(t, node2.index)
+	assert.Empty(t, node2.items)
+}
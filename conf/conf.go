@@ -1,6 +1,11 @@
+// Package conf keeps the API of the old viper based loader on top of the config package.
+//
+// Deprecated: use github.com/apus-run/sea-kit/config instead, Conf.Config returns the config of a file for migration.
 package conf
 
 import (
+	"bytes"
+	"context"
 	"log"
 	"os"
 	"path"
@@ -8,8 +13,11 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/apus-run/sea-kit/config"
+	cfile "github.com/apus-run/sea-kit/config/file"
 )
 
 var (
@@ -22,13 +30,29 @@ var (
 type Conf interface {
 	// Load 加载配置文件
 	Load()
-	// Watch 监听配置文件变化
+	// Watch 监听配置文件变化, File 和 Get 返回的配置在调用 Watch 之后才会随文件更新.
+	// Config 和 Scan 总是返回最新的配置
 	Watch()
 
-	//File name 文件名
+	//File name 文件名, 与旧版本一样开启了 AutomaticEnv, 环境变量优先于配置文件
+	//
+	// Deprecated: use Config or Scan instead.
 	File(name string) *viper.Viper
 
 	Get(fileName string, key string) any
+
+	// Config 返回文件对应的 config.Config
+	Config(name string) config.Config
+	// Scan 将文件的配置解析到 v
+	Scan(fileName string, v any) error
+}
+
+// file 一个配置文件, viper 由 config 的 binding 同步, 用于兼容旧版本的 File 和 Get
+type file struct {
+	cfg     config.Config
+	binding *config.Binding[map[string]any]
+	viper   *viper.Viper
+	mu      sync.Mutex
 }
 
 type conf struct {
@@ -56,22 +80,24 @@ func (c *conf) Load() {
 			panic(err)
 		}
 		for _, f := range fs {
+			cfg := config.New(config.WithSource(newSource(f)))
+			if err := cfg.Load(); err != nil {
+				panic(err)
+			}
+			binding, err := config.Bind[map[string]any](cfg, "")
+			if err != nil {
+				panic(err)
+			}
 			v := viper.New()
-			v.SetConfigType(f.Format)
-			v.SetConfigFile(f.Path)
 			v.AutomaticEnv()
-
-			if err := v.ReadInConfig(); err != nil {
-				if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-					log.Printf("Using conf file: %s [%s]\n", viper.ConfigFileUsed(), err)
-				}
+			if err := readInViper(v, *binding.Load()); err != nil {
 				panic(err)
 			}
-			v.AutomaticEnv()
 
 			name := strings.TrimSuffix(path.Base(f.Key), filepath.Ext(f.Key))
-			// log.Printf("配置文件加载成功: %s", f.Path)
-			c.files.Store(name, v)
+			if old, ok := c.files.Swap(name, &file{cfg: cfg, binding: binding, viper: v}); ok {
+				old.(*file).close()
+			}
 		}
 	}
 
@@ -80,24 +106,107 @@ func (c *conf) Load() {
 
 func (c *conf) Watch() {
 	c.files.Range(func(key, value any) bool {
-		v := value.(*viper.Viper)
-		v.OnConfigChange(func(e fsnotify.Event) {
-			log.Printf("Config file changed: %s", e.Name)
+		f := value.(*file)
+		f.binding.OnChange(func(_, m map[string]any) {
+			log.Printf("Config file changed: %s", key)
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if err := readInViper(f.viper, m); err != nil {
+				log.Printf("Config file reload failed: %s [%s]", key, err)
+			}
 		})
-		v.WatchConfig()
 		return true
 	})
 }
 
+func (c *conf) Config(name string) config.Config {
+	if f := c.file(name); f != nil {
+		return f.cfg
+	}
+	return nil
+}
+
+func (c *conf) Scan(fileName string, v any) error {
+	cfg := c.Config(fileName)
+	if cfg == nil {
+		return config.ErrNotFound
+	}
+	return cfg.Scan(v)
+}
+
 func (c *conf) File(name string) *viper.Viper {
-	if v, ok := c.files.Load(name); ok {
-		return v.(*viper.Viper)
+	if f := c.file(name); f != nil {
+		return f.viper
 	}
 	return nil
 }
 
 func (c *conf) Get(fileName string, key string) any {
-	return c.File(fileName).Get(key)
+	f := c.file(fileName)
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.viper.Get(key)
+}
+
+func (c *conf) file(name string) *file {
+	if v, ok := c.files.Load(name); ok {
+		return v.(*file)
+	}
+	return nil
+}
+
+func (f *file) close() {
+	f.binding.Close()
+	_ = f.cfg.Close()
+}
+
+// readInViper 用 m 替换 viper 中的配置, 通过 yaml 中转以便删除的配置项也能生效, 并且保留整数类型
+func readInViper(v *viper.Viper, m map[string]any) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	v.SetConfigType("yaml")
+	return v.ReadConfig(bytes.NewReader(data))
+}
+
+// newSource 有文件路径时使用 config/file 以便监听文件变化
+func newSource(kv *KeyValue) config.Source {
+	if kv.Path != "" {
+		return cfile.NewSource(kv.Path)
+	}
+	return &source{kv: kv}
+}
+
+type source struct {
+	kv *KeyValue
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	return []*config.KeyValue{{Key: s.kv.Key, Value: s.kv.Value, Format: s.kv.Format}}, nil
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	return &watcher{done: make(chan struct{})}, nil
+}
+
+// watcher 没有文件路径的配置不会变化
+type watcher struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	<-w.done
+	return nil, context.Canceled
+}
+
+func (w *watcher) Stop() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
 }
 
 // GetEnvString get value from env.
@@ -0,0 +1,44 @@
+package conf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/conf"
+	"github.com/apus-run/sea-kit/conf/file"
+)
+
+func TestConf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  port: 80\n  host: localhost\n"), 0o644))
+
+	c := conf.New(conf.WithSource(file.NewSource(path)))
+	c.Load()
+	c.Watch()
+	assert.Equal(t, 80, c.Get("app", "server.port"))
+
+	var v struct {
+		Server struct {
+			Port int `json:"port"`
+		} `json:"server"`
+	}
+	require.NoError(t, c.Scan("app", &v))
+	assert.Equal(t, 80, v.Server.Port)
+
+	// 环境变量优先于配置文件
+	t.Setenv("SERVER.HOST", "0.0.0.0")
+	assert.Equal(t, "0.0.0.0", c.Get("app", "server.host"))
+	assert.Equal(t, "0.0.0.0", c.File("app").GetString("server.host"))
+
+	// Watch 之后 File 和 Get 随文件更新
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  port: 8080\n"), 0o644))
+	assert.Eventually(t, func() bool {
+		return conf.Get("app", "server.port") == 8080
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 8080, c.File("app").GetInt("server.port"))
+}
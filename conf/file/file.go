@@ -18,6 +18,8 @@ type file struct {
 }
 
 // NewSource new a file source.
+//
+// Deprecated: use github.com/apus-run/sea-kit/config/file.NewSource instead.
 func NewSource(path string) conf.Source {
 	return &file{path: path}
 }
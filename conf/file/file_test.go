@@ -200,8 +200,7 @@ func testConfig(t *testing.T, c conf.Conf) {
 	}
 
 	var testConf testConfigStruct
-	appConf := c.File("test")
-	err := appConf.Unmarshal(&testConf)
+	err := c.Scan("test", &testConf)
 	if err != nil {
 		t.Errorf("error: %d", err)
 	}
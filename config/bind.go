@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apus-run/sea-kit/log"
+	"github.com/apus-run/sea-kit/validator"
+)
+
+// ErrBindUnsupported is returned when the config does not support reload notifications.
+var ErrBindUnsupported = errors.New("config does not support binding")
+
+// BindOption is binding option.
+type BindOption func(*bindOptions)
+
+type bindOptions struct {
+	validator *validator.Validator
+}
+
+// WithValidator with the validator used to check new values before swapping.
+// Default is validator.V(), or a new validator if the default one is not initialized.
+func WithValidator(v *validator.Validator) BindOption {
+	return func(o *bindOptions) {
+		o.validator = v
+	}
+}
+
+// Binding is a typed value bound to a config path.
+// The value is re-decoded and atomically swapped whenever the config reloads.
+type Binding[T any] struct {
+	cfg  Config
+	path string
+	opts bindOptions
+
+	value  atomic.Pointer[T]
+	cancel func()
+
+	mu       sync.Mutex
+	onChange []func(old, new T)
+	onError  []func(error)
+}
+
+// Bind decodes the value at path into T and keeps it up to date.
+// An empty path binds the whole config.
+// New values that fail validation are dropped, the old value is kept and OnError callbacks are called.
+func Bind[T any](cfg Config, path string, opts ...BindOption) (*Binding[T], error) {
+	n, ok := cfg.(reloadNotifier)
+	if !ok {
+		return nil, ErrBindUnsupported
+	}
+	o := bindOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.validator == nil {
+		o.validator = validator.V()
+	}
+	if o.validator == nil {
+		o.validator = validator.New()
+	}
+
+	b := &Binding[T]{cfg: cfg, path: path, opts: o}
+	v, err := b.decode()
+	if err != nil {
+		return nil, err
+	}
+	b.value.Store(v)
+	b.cancel = n.onReload(b.reload)
+	return b, nil
+}
+
+// Load returns the current value. The returned value must not be modified.
+func (b *Binding[T]) Load() *T {
+	return b.value.Load()
+}
+
+// OnChange registers fn to be called after the value is swapped.
+func (b *Binding[T]) OnChange(fn func(old, new T)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = append(b.onChange, fn)
+}
+
+// OnError registers fn to be called when a reloaded value cannot be decoded or validated.
+func (b *Binding[T]) OnError(fn func(error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onError = append(b.onError, fn)
+}
+
+// Close stops following config reloads.
+func (b *Binding[T]) Close() {
+	b.cancel()
+}
+
+func (b *Binding[T]) decode() (*T, error) {
+	v := new(T)
+	var err error
+	if b.path == "" {
+		err = b.cfg.Scan(v)
+	} else {
+		err = b.cfg.Value(b.path).Scan(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = b.opts.validator.Valid(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (b *Binding[T]) reload() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	v, err := b.decode()
+	if err != nil {
+		log.Errorf("failed to reload config binding %q: %v", b.path, err)
+		for _, fn := range b.onError {
+			fn(err)
+		}
+		return
+	}
+	old := b.value.Load()
+	if reflect.DeepEqual(old, v) {
+		return
+	}
+	b.value.Store(v)
+	for _, fn := range b.onChange {
+		fn(*old, *v)
+	}
+}
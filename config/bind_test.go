@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type testBindServer struct {
+	Addr string `json:"addr" valid:"required"`
+	Port int    `json:"port"`
+}
+
+type testChangingSource struct {
+	data    string
+	changes chan string
+}
+
+func (s *testChangingSource) Load() ([]*KeyValue, error) {
+	return []*KeyValue{{Key: "json", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testChangingSource) Watch() (Watcher, error) {
+	return &testChangingWatcher{changes: s.changes, exit: make(chan struct{})}, nil
+}
+
+type testChangingWatcher struct {
+	changes chan string
+	exit    chan struct{}
+}
+
+func (w *testChangingWatcher) Next() ([]*KeyValue, error) {
+	select {
+	case data := <-w.changes:
+		return []*KeyValue{{Key: "json", Value: []byte(data), Format: "json"}}, nil
+	case <-w.exit:
+		return nil, nil
+	}
+}
+
+func (w *testChangingWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	src := &testChangingSource{
+		data:    `{"server":{"addr":"0.0.0.0","port":80}}`,
+		changes: make(chan string),
+	}
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	b, err := Bind[testBindServer](c, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if got := b.Load(); got.Addr != "0.0.0.0" || got.Port != 80 {
+		t.Fatalf("unexpected value: %+v", got)
+	}
+
+	changed := make(chan [2]testBindServer, 1)
+	failed := make(chan error, 1)
+	b.OnChange(func(old, new testBindServer) {
+		changed <- [2]testBindServer{old, new}
+	})
+	b.OnError(func(err error) {
+		failed <- err
+	})
+
+	src.changes <- `{"server":{"addr":"127.0.0.1","port":8080}}`
+	select {
+	case c := <-changed:
+		if c[0].Port != 80 || c[1].Port != 8080 {
+			t.Fatalf("unexpected change: %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnChange is not called")
+	}
+	if got := b.Load(); got.Addr != "127.0.0.1" || got.Port != 8080 {
+		t.Fatalf("unexpected value: %+v", got)
+	}
+
+	// 校验失败时保留旧值
+	src.changes <- `{"server":{"addr":"","port":9090}}`
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("OnError is not called")
+	}
+	if got := b.Load(); got.Port != 8080 {
+		t.Fatalf("invalid value is swapped: %+v", got)
+	}
+}
@@ -0,0 +1,45 @@
+// Command explain prints which source every final config key comes from.
+//
+//	go run github.com/apus-run/sea-kit/config/cmd/explain -conf configs/app.yaml -profile prod -env APP
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apus-run/sea-kit/config"
+	"github.com/apus-run/sea-kit/config/env"
+	"github.com/apus-run/sea-kit/config/file"
+)
+
+func main() {
+	var (
+		conf     string
+		profiles string
+		prefix   string
+	)
+	flag.StringVar(&conf, "conf", "configs/app.yaml", "config file path")
+	flag.StringVar(&profiles, "profile", "", "comma separated profiles, e.g. prod,local")
+	flag.StringVar(&prefix, "env", "", "prefix of environment overrides, e.g. APP")
+	flag.Parse()
+
+	if err := explain(conf, strings.Split(profiles, ","), prefix); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func explain(conf string, profiles []string, prefix string) error {
+	sources := []config.Source{file.NewProfileSource(conf, profiles...)}
+	if prefix != "" {
+		sources = append(sources, env.NewOverrideSource(prefix))
+	}
+	c := config.New(config.WithSource(sources...))
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		return err
+	}
+	return config.WriteExplain(os.Stdout, c)
+}
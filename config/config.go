@@ -5,11 +5,14 @@ import (
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	// init encoding
 	_ "github.com/apus-run/sea-kit/encoding/json"
+	_ "github.com/apus-run/sea-kit/encoding/msgpack"
 	_ "github.com/apus-run/sea-kit/encoding/proto"
+	_ "github.com/apus-run/sea-kit/encoding/toml"
 	_ "github.com/apus-run/sea-kit/encoding/xml"
 	_ "github.com/apus-run/sea-kit/encoding/yaml"
 	"github.com/apus-run/sea-kit/log"
@@ -36,12 +39,20 @@ type Config interface {
 	Close() error
 }
 
+// reloadNotifier is implemented by configs that can notify bindings after reload.
+type reloadNotifier interface {
+	onReload(fn func()) (cancel func())
+}
+
 type config struct {
 	opts      options
 	reader    Reader
 	cached    sync.Map
 	observers sync.Map
 	watchers  []Watcher
+
+	seq       int64
+	listeners sync.Map
 }
 
 // New a config with options.
@@ -53,6 +64,9 @@ func New(opts ...Option) Config {
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.secrets == nil {
+		o.secrets = defaultSecretResolvers()
+	}
 	return &config{
 		opts:   o,
 		reader: newReader(o),
@@ -71,14 +85,11 @@ func (c *config) watch(w Watcher) {
 			log.Errorf("failed to watch next config: %v", err)
 			continue
 		}
+		// the values are resolved by Merge once loaded, a failing resolver keeps the previous ones
 		if err := c.reader.Merge(kvs...); err != nil {
 			log.Errorf("failed to merge next config: %v", err)
 			continue
 		}
-		if err := c.reader.Resolve(); err != nil {
-			log.Errorf("failed to resolve next config: %v", err)
-			continue
-		}
 		c.cached.Range(func(key, value interface{}) bool {
 			k := key.(string)
 			v := value.(Value)
@@ -90,6 +101,19 @@ func (c *config) watch(w Watcher) {
 			}
 			return true
 		})
+		c.listeners.Range(func(_, fn interface{}) bool {
+			fn.(func())()
+			return true
+		})
+	}
+}
+
+// onReload registers fn to be called after every successful reload of any source.
+func (c *config) onReload(fn func()) func() {
+	id := atomic.AddInt64(&c.seq, 1)
+	c.listeners.Store(id, fn)
+	return func() {
+		c.listeners.Delete(id)
 	}
 }
 
@@ -0,0 +1,103 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+// Option is consul config option.
+type Option func(o *options)
+
+type options struct {
+	ctx      context.Context
+	path     string
+	waitTime time.Duration
+}
+
+// WithContext with config context.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithPath is config path, every key under the path is loaded.
+func WithPath(p string) Option {
+	return func(o *options) {
+		o.path = p
+	}
+}
+
+// WithWaitTime is the max time a blocking query waits for changes, default is 5m.
+func WithWaitTime(d time.Duration) Option {
+	return func(o *options) {
+		o.waitTime = d
+	}
+}
+
+type source struct {
+	client  *api.Client
+	options *options
+}
+
+// New creates a consul KV config source. The format of each key is detected from its extension,
+// e.g. "configs/app.yaml" is decoded as yaml.
+func New(client *api.Client, opts ...Option) (config.Source, error) {
+	o := &options{
+		ctx:      context.Background(),
+		waitTime: 5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.path == "" {
+		return nil, errors.New("path invalid")
+	}
+	o.path = strings.TrimPrefix(o.path, "/")
+	return &source{
+		client:  client,
+		options: o,
+	}, nil
+}
+
+// Load return the config values
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kvs, _, err := s.list(s.options.ctx, 0)
+	return kvs, err
+}
+
+// list reads every key under the path. A non-zero index turns the read into a blocking query
+// which returns when the index changes or the wait time is reached.
+func (s *source) list(ctx context.Context, index uint64) ([]*config.KeyValue, uint64, error) {
+	q := (&api.QueryOptions{WaitIndex: index, WaitTime: s.options.waitTime}).WithContext(ctx)
+	pairs, meta, err := s.client.KV().List(s.options.path, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kvs := make([]*config.KeyValue, 0, len(pairs))
+	for _, item := range pairs {
+		// folders have no value
+		if strings.HasSuffix(item.Key, "/") {
+			continue
+		}
+		kvs = append(kvs, &config.KeyValue{
+			Key:    item.Key,
+			Value:  item.Value,
+			Format: strings.TrimPrefix(filepath.Ext(item.Key), "."),
+		})
+	}
+	return kvs, meta.LastIndex, nil
+}
+
+// Watch return the watcher
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s)
+}
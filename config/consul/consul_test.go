@@ -0,0 +1,104 @@
+package consul
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// testKVServer is a local stand-in for the consul KV HTTP API with blocking queries.
+type testKVServer struct {
+	mu      sync.Mutex
+	changed chan struct{}
+	index   uint64
+	entries map[string]string
+}
+
+func newTestKVServer() *testKVServer {
+	return &testKVServer{index: 1, entries: make(map[string]string), changed: make(chan struct{})}
+}
+
+func (s *testKVServer) put(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = value
+	s.index++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *testKVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	for wait > 0 && s.index <= wait {
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			s.mu.Lock()
+			return
+		}
+		s.mu.Lock()
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(s.index, 10))
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, "[")
+	first := true
+	for k, v := range s.entries {
+		if !first {
+			fmt.Fprint(w, ",")
+		}
+		first = false
+		fmt.Fprintf(w, `{"Key":%q,"Value":%q}`, k, base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	fmt.Fprint(w, "]")
+}
+
+func TestSource(t *testing.T) {
+	kv := newTestKVServer()
+	kv.put("configs/", "")
+	kv.put("configs/app.yaml", "name: foo")
+	srv := httptest.NewServer(kv)
+	defer srv.Close()
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(client, WithPath("/configs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || kvs[0].Key != "configs/app.yaml" || kvs[0].Format != "yaml" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	go kv.put("configs/app.yaml", "name: bar")
+	kvs, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != "name: bar" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+}
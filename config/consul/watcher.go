@@ -0,0 +1,62 @@
+package consul
+
+import (
+	"context"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+type watcher struct {
+	source *source
+	index  uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) (*watcher, error) {
+	ctx, cancel := context.WithCancel(s.options.ctx)
+	w := &watcher{
+		source: s,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	// remember the current index, so that Next only returns later changes
+	_, index, err := s.list(ctx, 0)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	w.index = index
+	return w, nil
+}
+
+// Next blocks with consul blocking queries until the values under the path change.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		kvs, index, err := w.source.list(w.ctx, w.index)
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			return nil, err
+		}
+		// reset the index if it goes backwards, otherwise the query returns immediately forever
+		// https://developer.hashicorp.com/consul/api-docs/features/blocking#implementation-details
+		if index < w.index {
+			w.index = 0
+			continue
+		}
+		if index == w.index {
+			// wait time reached without changes
+			continue
+		}
+		w.index = index
+		return kvs, nil
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+type override struct {
+	prefix string
+}
+
+// NewOverrideSource new an environment source that overrides nested config keys.
+// "__" separates the levels of the key and the key is lower-cased,
+// e.g. with prefix "APP", APP_SERVER__HTTP__PORT=8080 overrides "server.http.port".
+// Values are decoded as JSON when possible, so numbers and booleans keep their types.
+func NewOverrideSource(prefix string) config.Source {
+	return &override{prefix: strings.TrimSuffix(prefix, "_") + "_"}
+}
+
+func (o *override) Load() ([]*config.KeyValue, error) {
+	return o.load(os.Environ())
+}
+
+func (o *override) load(envs []string) ([]*config.KeyValue, error) {
+	var kvs []*config.KeyValue
+	for _, env := range envs {
+		name, v, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, o.prefix) || len(name) == len(o.prefix) {
+			continue
+		}
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(name, o.prefix)), "__")
+
+		var value interface{} = v
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(v), &parsed); err == nil && parsed != nil {
+			value = parsed
+		}
+		for i := len(keys) - 1; i >= 0; i-- {
+			value = map[string]interface{}{keys[i]: value}
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, &config.KeyValue{
+			Key:    name,
+			Value:  data,
+			Format: "json",
+		})
+	}
+	return kvs, nil
+}
+
+func (o *override) Watch() (config.Watcher, error) {
+	return NewWatcher()
+}
@@ -0,0 +1,56 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+func TestOverrideSource(t *testing.T) {
+	o := NewOverrideSource("APP").(*override)
+	kvs, err := o.load([]string{
+		"APP_SERVER__HTTP__PORT=8080",
+		"APP_SERVER__HTTP__ADDR=127.0.0.1",
+		"APP_DEBUG=true",
+		"APP_=ignored",
+		"OTHER_KEY=ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 3 {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	c := config.New(config.WithSource(&staticSource{kvs: kvs}))
+	if err = c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Server struct {
+			HTTP struct {
+				Addr string `json:"addr"`
+				Port int    `json:"port"`
+			} `json:"http"`
+		} `json:"server"`
+		Debug bool `json:"debug"`
+	}
+	if err = c.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Server.HTTP.Port != 8080 || v.Server.HTTP.Addr != "127.0.0.1" || !v.Debug {
+		t.Fatalf("unexpected config: %+v", v)
+	}
+}
+
+type staticSource struct {
+	kvs []*config.KeyValue
+}
+
+func (s *staticSource) Load() ([]*config.KeyValue, error) {
+	return s.kvs, nil
+}
+
+func (s *staticSource) Watch() (config.Watcher, error) {
+	return NewWatcher()
+}
@@ -0,0 +1,95 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+// Option is etcd config option.
+type Option func(o *options)
+
+type options struct {
+	ctx    context.Context
+	path   string
+	prefix bool
+}
+
+// WithContext with config context.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithPath is config path.
+func WithPath(p string) Option {
+	return func(o *options) {
+		o.path = p
+	}
+}
+
+// WithPrefix loads and watches every key under path.
+func WithPrefix(prefix bool) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+type source struct {
+	client  *clientv3.Client
+	options *options
+}
+
+// New creates an etcd config source. The format of each key is detected from its extension,
+// e.g. "/configs/app.yaml" is decoded as yaml.
+func New(client *clientv3.Client, opts ...Option) (config.Source, error) {
+	o := &options{
+		ctx: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.path == "" {
+		return nil, errors.New("path invalid")
+	}
+	return &source{
+		client:  client,
+		options: o,
+	}, nil
+}
+
+func (s *source) opOptions() []clientv3.OpOption {
+	if s.options.prefix {
+		return []clientv3.OpOption{clientv3.WithPrefix()}
+	}
+	return nil
+}
+
+// Load return the config values
+func (s *source) Load() ([]*config.KeyValue, error) {
+	rsp, err := s.client.Get(s.options.ctx, s.options.path, s.opOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	kvs := make([]*config.KeyValue, 0, len(rsp.Kvs))
+	for _, item := range rsp.Kvs {
+		k := string(item.Key)
+		kvs = append(kvs, &config.KeyValue{
+			Key:    k,
+			Value:  item.Value,
+			Format: strings.TrimPrefix(filepath.Ext(k), "."),
+		})
+	}
+	return kvs, nil
+}
+
+// Watch return the watcher
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s), nil
+}
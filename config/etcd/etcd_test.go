@@ -0,0 +1,135 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// testKV is an in-memory stand-in for the etcd KV API.
+type testKV struct {
+	clientv3.KV
+	kvs []*mvccpb.KeyValue
+}
+
+func (kv *testKV) Get(_ context.Context, _ string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	return &clientv3.GetResponse{Kvs: kv.kvs}, nil
+}
+
+type testWatcher struct {
+	clientv3.Watcher
+	ch chan clientv3.WatchResponse
+	// revs 每次 Watch 的起始版本
+	revs []int64
+}
+
+func (w *testWatcher) Watch(_ context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	op := clientv3.OpGet(key, opts...)
+	w.revs = append(w.revs, op.Rev())
+	return w.ch
+}
+
+func TestSource(t *testing.T) {
+	kv := &testKV{kvs: []*mvccpb.KeyValue{
+		{Key: []byte("/configs/app.yaml"), Value: []byte("name: foo")},
+		{Key: []byte("/configs/db.json"), Value: []byte(`{"driver":"mysql"}`)},
+	}}
+	w := &testWatcher{ch: make(chan clientv3.WatchResponse, 1)}
+	client := &clientv3.Client{KV: kv, Watcher: w}
+
+	s, err := New(client, WithPath("/configs"), WithPrefix(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 2 || kvs[0].Format != "yaml" || kvs[1].Format != "json" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	watcher, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kv.kvs = kv.kvs[:1]
+	w.ch <- clientv3.WatchResponse{}
+	kvs, err = watcher.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != "name: foo" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	if err = watcher.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := watcher.Next()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("expect error after stop")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next is not canceled")
+	}
+
+	if _, err = New(client); err == nil {
+		t.Fatal("expect path error")
+	}
+}
+
+func TestWatcher_Rewatch(t *testing.T) {
+	kv := &testKV{kvs: []*mvccpb.KeyValue{
+		{Key: []byte("/configs/app.yaml"), Value: []byte("name: foo")},
+	}}
+	w := &testWatcher{ch: make(chan clientv3.WatchResponse, 1)}
+	client := &clientv3.Client{KV: kv, Watcher: w}
+
+	s, err := New(client, WithPath("/configs"), WithPrefix(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	w.ch <- clientv3.WatchResponse{Header: etcdserverpb.ResponseHeader{Revision: 10}}
+	if _, err = watcher.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	// watch 被关闭 (例如 leader 丢失) 时从下一个版本重新 watch
+	closed := w.ch
+	w.ch = make(chan clientv3.WatchResponse, 1)
+	close(closed)
+	kvs, err := watcher.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	// 版本被压缩时从压缩后的版本重新 watch
+	w.ch <- clientv3.WatchResponse{Canceled: true, CompactRevision: 20}
+	if _, err = watcher.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(w.revs) != 3 || w.revs[0] != 0 || w.revs[1] != 11 || w.revs[2] != 20 {
+		t.Fatalf("unexpected watch revisions: %v", w.revs)
+	}
+}
@@ -0,0 +1,75 @@
+package etcd
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+type watcher struct {
+	source *source
+	ch     clientv3.WatchChan
+	// rev is the last revision received, a re-created watch starts after it.
+	rev int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) *watcher {
+	ctx, cancel := context.WithCancel(s.options.ctx)
+	return &watcher{
+		source: s,
+		ch:     s.client.Watch(ctx, s.options.path, s.opOptions()...),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Next blocks until any watched key changes and returns all the values under the path.
+// Keys deleted from etcd are not removed from the merged config, they keep their last value
+// until the process restarts.
+// A watch closed by etcd, e.g. on compaction or leader loss, is re-created from the last
+// revision and the values are reloaded; context.Canceled is only returned after Stop.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case resp, ok := <-w.ch:
+		if !ok || resp.CompactRevision != 0 {
+			if w.ctx.Err() != nil {
+				return nil, w.ctx.Err()
+			}
+			w.rewatch(resp.CompactRevision)
+			return w.source.Load()
+		}
+		if err := resp.Err(); err != nil {
+			return nil, err
+		}
+		w.rev = resp.Header.Revision
+		return w.source.Load()
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+// rewatch re-creates the watch after the last revision, or from compactRev if that revision was compacted.
+func (w *watcher) rewatch(compactRev int64) {
+	var rev int64
+	if w.rev > 0 {
+		rev = w.rev + 1
+	}
+	if compactRev > rev {
+		rev = compactRev
+	}
+	opts := w.source.opOptions()
+	if rev > 0 {
+		opts = append(opts, clientv3.WithRev(rev))
+	}
+	w.ch = w.source.client.Watch(w.ctx, w.source.options.path, opts...)
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
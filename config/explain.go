@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Origin is where the final value of a config key comes from.
+type Origin struct {
+	// Key is the config key, e.g. "server.http.addr".
+	Key string
+	// Source is the source key of the value, e.g. the file name "app.prod.yaml" or the environment variable name.
+	Source string
+	// Secret reports whether the value is resolved from a secret reference.
+	Secret bool
+}
+
+type explainer interface {
+	explain() []Origin
+}
+
+// Explain returns the origin of every loaded config key, sorted by key.
+func Explain(c Config) ([]Origin, error) {
+	cc, ok := c.(*config)
+	if !ok {
+		return nil, errors.New("config does not support explain")
+	}
+	e, ok := cc.reader.(explainer)
+	if !ok {
+		return nil, errors.New("config reader does not support explain")
+	}
+	return e.explain(), nil
+}
+
+// WriteExplain writes the origin of every loaded config key to w as a table.
+// Values are not written, so that secrets are never printed.
+func WriteExplain(w io.Writer, c Config) error {
+	origins, err := Explain(c)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0) //nolint:gomnd
+	fmt.Fprintln(tw, "KEY\tSOURCE")
+	for _, o := range origins {
+		src := o.Source
+		if o.Secret {
+			src += " (secret)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", o.Key, src)
+	}
+	return tw.Flush()
+}
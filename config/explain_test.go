@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testKVSource struct {
+	kvs []*KeyValue
+}
+
+func (s *testKVSource) Load() ([]*KeyValue, error) {
+	return s.kvs, nil
+}
+
+func (s *testKVSource) Watch() (Watcher, error) {
+	return newTestWatcher(make(chan struct{}), make(chan struct{})), nil
+}
+
+func TestSecretsAndExplain(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(secretFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_CONFIG_API_KEY", "key-from-env")
+	t.Setenv("TEST_CONFIG_HOST", "db.local")
+
+	c := New(
+		WithSource(&testKVSource{kvs: []*KeyValue{
+			{Key: "app.yaml", Format: "json", Value: []byte(`{
+				"db": {"host": "${TEST_CONFIG_HOST}", "port": 3306, "password": "secret://file` + secretFile + `"},
+				"api": {"key": "secret://env/TEST_CONFIG_API_KEY", "token": "secret://vault/api#token"}
+			}`)},
+			{Key: "app.prod.yaml", Format: "json", Value: []byte(`{"db": {"port": 3307}}`)},
+		}}),
+		WithSecretResolver("vault", SecretResolverFunc(func(ref string) (string, error) {
+			return "vault" + ref, nil
+		})),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for key, want := range map[string]string{
+		"db.host":     "db.local",
+		"db.password": "s3cret",
+		"api.key":     "key-from-env",
+		"api.token":   "vault/api#token",
+	} {
+		got, err := c.Value(key).String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s want: %s, got: %s", key, want, got)
+		}
+	}
+
+	origins, err := Explain(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Origin{
+		{Key: "api.key", Source: "app.yaml", Secret: true},
+		{Key: "api.token", Source: "app.yaml", Secret: true},
+		{Key: "db.host", Source: "app.yaml"},
+		{Key: "db.password", Source: "app.yaml", Secret: true},
+		{Key: "db.port", Source: "app.prod.yaml"},
+	}
+	if !reflect.DeepEqual(origins, want) {
+		t.Fatalf("want: %+v, got: %+v", want, origins)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteExplain(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "s3cret") || !strings.Contains(buf.String(), "app.prod.yaml") {
+		t.Fatalf("unexpected explain output:\n%s", buf.String())
+	}
+}
+
+func TestSecretsUnsupportedScheme(t *testing.T) {
+	c := New(WithSource(&testKVSource{kvs: []*KeyValue{
+		{Key: "app.json", Format: "json", Value: []byte(`{"password": "secret://unknown/x"}`)},
+	}}))
+	if err := c.Load(); err == nil {
+		t.Fatal("expect unsupported secret scheme error")
+	}
+}
+
+func TestSecretsReloadFailure(t *testing.T) {
+	fail := false
+	r := newReader(options{
+		decoder:  defaultDecoder,
+		resolver: defaultResolver,
+		secrets: map[string]SecretResolver{
+			"vault": SecretResolverFunc(func(ref string) (string, error) {
+				if fail {
+					return "", errors.New("vault unavailable")
+				}
+				return "vault" + ref, nil
+			}),
+		},
+	})
+	if err := r.Merge(&KeyValue{Key: "app.json", Format: "json", Value: []byte(`{"token": "secret://vault/v1"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a reload whose secret cannot be resolved keeps serving the previous values
+	fail = true
+	err := r.Merge(&KeyValue{Key: "app.json", Format: "json", Value: []byte(`{"token": "secret://vault/v2", "port": 80}`)})
+	if err == nil {
+		t.Fatal("expect resolve error")
+	}
+	v, ok := r.Value("token")
+	if !ok {
+		t.Fatal("token not found")
+	}
+	if got, _ := v.String(); got != "vault/v1" {
+		t.Fatalf("want: vault/v1, got: %s", got)
+	}
+	if _, ok = r.Value("port"); ok {
+		t.Fatal("unexpected port of the failed reload")
+	}
+
+	// and resolves the next successful one before it is served
+	fail = false
+	err = r.Merge(&KeyValue{Key: "app.json", Format: "json", Value: []byte(`{"token": "secret://vault/v3"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _ = r.Value("token")
+	if got, _ := v.String(); got != "vault/v3" {
+		t.Fatalf("want: vault/v3, got: %s", got)
+	}
+}
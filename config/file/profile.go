@@ -0,0 +1,111 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+var _ config.Source = (*profile)(nil)
+
+type profile struct {
+	base     *file
+	profiles []*file
+}
+
+// NewProfileSource new a file source layered by profiles.
+// For path "configs/app.yaml" and profiles "prod", "local", it loads app.yaml, app.prod.yaml and app.local.yaml
+// in order, so later profiles override earlier ones. Missing profile files are ignored.
+func NewProfileSource(path string, profiles ...string) config.Source {
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(path, ext)
+	p := &profile{base: &file{path: path}}
+	for _, pf := range profiles {
+		if pf == "" {
+			continue
+		}
+		p.profiles = append(p.profiles, &file{path: name + "." + pf + ext})
+	}
+	return p
+}
+
+func (p *profile) Load() ([]*config.KeyValue, error) {
+	kvs, err := p.base.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range p.profiles {
+		kv, err := f.loadFile(f.path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs, nil
+}
+
+func (p *profile) Watch() (config.Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directory, so that profile files created later are also watched
+	if err := fw.Add(filepath.Dir(p.base.path)); err != nil {
+		_ = fw.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &profileWatcher{p: p, fw: fw, ctx: ctx, cancel: cancel}, nil
+}
+
+type profileWatcher struct {
+	p  *profile
+	fw *fsnotify.Watcher
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Next returns all the layers once any of them changes, so that the merge order is kept.
+func (w *profileWatcher) Next() ([]*config.KeyValue, error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case event := <-w.fw.Events:
+			if !w.watched(event.Name) {
+				continue
+			}
+			return w.p.Load()
+		case err := <-w.fw.Errors:
+			return nil, err
+		}
+	}
+}
+
+func (w *profileWatcher) watched(name string) bool {
+	name = filepath.Clean(name)
+	if name == filepath.Clean(w.p.base.path) {
+		return true
+	}
+	for _, f := range w.p.profiles {
+		if name == filepath.Clean(f.path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *profileWatcher) Stop() error {
+	w.cancel()
+	return w.fw.Close()
+}
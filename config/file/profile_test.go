@@ -0,0 +1,54 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+func TestProfileSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(base, []byte("server:\n  addr: 0.0.0.0\n  port: 80\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.prod.yaml"), []byte("server:\n  port: 8080\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewProfileSource(base, "prod", "local")
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 2 || kvs[0].Key != "app.yaml" || kvs[1].Key != "app.prod.yaml" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	c := config.New(config.WithSource(s))
+	if err = c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if port, _ := c.Value("server.port").Int(); port != 8080 {
+		t.Fatalf("want port 8080, got %d", port)
+	}
+
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err = os.WriteFile(filepath.Join(dir, "app.local.yaml"), []byte("server:\n  port: 9090\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	kvs, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 3 || kvs[2].Key != "app.local.yaml" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+}
@@ -0,0 +1,83 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+// Option is nacos config option.
+type Option func(o *options)
+
+type options struct {
+	group  string
+	dataID string
+}
+
+// WithGroup with config group, default is DEFAULT_GROUP.
+func WithGroup(group string) Option {
+	return func(o *options) {
+		o.group = group
+	}
+}
+
+// WithDataID with config data id.
+func WithDataID(dataID string) Option {
+	return func(o *options) {
+		o.dataID = dataID
+	}
+}
+
+type source struct {
+	client  config_client.IConfigClient
+	options options
+}
+
+// New creates a nacos config source. The format is detected from the extension of the data id,
+// e.g. "app.yaml" is decoded as yaml.
+func New(client config_client.IConfigClient, opts ...Option) (config.Source, error) {
+	o := options{
+		group: "DEFAULT_GROUP",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.dataID == "" {
+		return nil, errors.New("data id invalid")
+	}
+	return &source{
+		client:  client,
+		options: o,
+	}, nil
+}
+
+func (s *source) keyValue(content string) *config.KeyValue {
+	return &config.KeyValue{
+		Key:    s.options.dataID,
+		Value:  []byte(content),
+		Format: strings.TrimPrefix(filepath.Ext(s.options.dataID), "."),
+	}
+}
+
+// Load return the config values
+func (s *source) Load() ([]*config.KeyValue, error) {
+	content, err := s.client.GetConfig(vo.ConfigParam{
+		DataId: s.options.dataID,
+		Group:  s.options.group,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*config.KeyValue{s.keyValue(content)}, nil
+}
+
+// Watch return the watcher
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(context.Background(), s)
+}
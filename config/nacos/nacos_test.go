@@ -0,0 +1,79 @@
+package nacos
+
+import (
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+// testConfigClient is a local stand-in for the nacos config client.
+type testConfigClient struct {
+	config_client.IConfigClient
+	content   string
+	listeners []vo.ConfigParam
+}
+
+func (c *testConfigClient) GetConfig(vo.ConfigParam) (string, error) {
+	return c.content, nil
+}
+
+func (c *testConfigClient) ListenConfig(params vo.ConfigParam) error {
+	c.listeners = append(c.listeners, params)
+	return nil
+}
+
+func (c *testConfigClient) CancelListenConfig(vo.ConfigParam) error {
+	c.listeners = nil
+	return nil
+}
+
+func (c *testConfigClient) publish(content string) {
+	c.content = content
+	for _, l := range c.listeners {
+		l.OnChange("", l.Group, l.DataId, content)
+	}
+}
+
+func TestSource(t *testing.T) {
+	client := &testConfigClient{content: "name: foo"}
+	s, err := New(client, WithDataID("app.yaml"), WithGroup("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || kvs[0].Format != "yaml" || string(kvs[0].Value) != "name: foo" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.publish("name: bar")
+	kvs, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kvs[0].Value) != "name: bar" {
+		t.Fatalf("unexpected kvs: %+v", kvs)
+	}
+
+	if err = w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Next(); err == nil {
+		t.Fatal("expect error after stop")
+	}
+	if len(client.listeners) != 0 {
+		t.Fatal("listener is not canceled")
+	}
+
+	if _, err = New(client); err == nil {
+		t.Fatal("expect data id error")
+	}
+}
@@ -0,0 +1,62 @@
+package nacos
+
+import (
+	"context"
+
+	"github.com/nacos-group/nacos-sdk-go/vo"
+
+	"github.com/apus-run/sea-kit/config"
+)
+
+type watcher struct {
+	source  *source
+	content chan string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(ctx context.Context, s *source) (*watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{
+		source:  s,
+		content: make(chan string, 100),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	err := s.client.ListenConfig(vo.ConfigParam{
+		DataId: s.options.dataID,
+		Group:  s.options.group,
+		OnChange: func(_, group, dataID, data string) {
+			if dataID != s.options.dataID || group != s.options.group {
+				return
+			}
+			select {
+			case w.content <- data:
+			case <-ctx.Done():
+			}
+		},
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case content := <-w.content:
+		return []*config.KeyValue{w.source.keyValue(content)}, nil
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return w.source.client.CancelListenConfig(vo.ConfigParam{
+		DataId: w.source.options.dataID,
+		Group:  w.source.options.group,
+	})
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
@@ -22,6 +23,7 @@ type options struct {
 	sources  []Source
 	decoder  Decoder
 	resolver Resolver
+	secrets  map[string]SecretResolver
 }
 
 // WithSource with config source.
@@ -49,6 +51,17 @@ func WithResolver(r Resolver) Option {
 	}
 }
 
+// WithSecretResolver with secret resolver for references like "secret://<scheme>/<ref>".
+// Secrets are resolved after placeholders, "file" and "env" resolvers are registered by default.
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(o *options) {
+		if o.secrets == nil {
+			o.secrets = defaultSecretResolvers()
+		}
+		o.secrets[scheme] = r
+	}
+}
+
 // WithLogger with config logger.
 // Deprecated: use global logger instead.
 func WithLogger(_ log.Logger) Option {
@@ -72,20 +85,31 @@ func defaultDecoder(src *KeyValue, target map[string]interface{}) error {
 		}
 		return nil
 	}
-	if codec := encoding.GetCodec(src.Format); codec != nil {
+	if codec := encoding.GetCodec(codecName(src.Format)); codec != nil {
 		return codec.Unmarshal(src.Value, &target)
 	}
 	return fmt.Errorf("unsupported key: %s format: %s", src.Key, src.Format)
 }
 
+// codecName returns the registered codec name of a file format.
+func codecName(format string) string {
+	if format == "yml" {
+		return "yaml"
+	}
+	return format
+}
+
 // defaultResolver resolve placeholder in safemap value,
-// placeholder format in ${key:default}.
+// placeholder format in ${key:default}. The key is looked up in the merged config of all sources first,
+// then in the environment variables.
 func defaultResolver(input map[string]interface{}) error {
 	mapper := func(name string) string {
 		args := strings.SplitN(strings.TrimSpace(name), ":", 2) //nolint:gomnd
 		if v, has := readValue(input, args[0]); has {
 			s, _ := v.String()
 			return s
+		} else if env, ok := os.LookupEnv(args[0]); ok {
+			return env
 		} else if len(args) > 1 { // default value
 			return args[1]
 		}
@@ -42,6 +42,23 @@ func TestDefaultDecoder(t *testing.T) {
 	}
 }
 
+func TestDefaultDecoder_Codecs(t *testing.T) {
+	tests := []*KeyValue{
+		{Key: "app.yml", Value: []byte("name: foo"), Format: "yml"},
+		{Key: "app.toml", Value: []byte(`name = "foo"`), Format: "toml"},
+		{Key: "app.json", Value: []byte(`{"name": "foo"}`), Format: "json"},
+	}
+	for _, src := range tests {
+		target := make(map[string]interface{})
+		if err := defaultDecoder(src, target); err != nil {
+			t.Fatalf("%s: %v", src.Key, err)
+		}
+		if target["name"] != "foo" {
+			t.Fatalf("%s: unexpected target %v", src.Key, target)
+		}
+	}
+}
+
 func TestDefaultResolver(t *testing.T) {
 	var (
 		portString = "8080"
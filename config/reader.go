@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -27,13 +28,23 @@ type reader struct {
 	opts   options
 	values map[string]interface{}
 	lock   sync.Mutex
+
+	// origins is the source key of every leaf key, e.g. "server.http.addr" => "app.prod.yaml".
+	origins map[string]string
+	// secrets is the keys resolved from secret references.
+	secrets map[string]struct{}
+	// resolved reports Resolve has succeeded once, the values merged afterwards
+	// are resolved before they are swapped in.
+	resolved bool
 }
 
 func newReader(opts options) Reader {
 	return &reader{
-		opts:   opts,
-		values: make(map[string]interface{}),
-		lock:   sync.Mutex{},
+		opts:    opts,
+		values:  make(map[string]interface{}),
+		lock:    sync.Mutex{},
+		origins: make(map[string]string),
+		secrets: make(map[string]struct{}),
 	}
 }
 
@@ -42,19 +53,41 @@ func (r *reader) Merge(kvs ...*KeyValue) error {
 	if err != nil {
 		return err
 	}
+	origins := r.cloneOrigins()
 	for _, kv := range kvs {
 		next := make(map[string]interface{})
 		if err := r.opts.decoder(kv, next); err != nil {
 			log.Errorf("Failed to config decode error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
 			return err
 		}
-		if err := mergo.Map(&merged, convertMap(next), mergo.WithOverride); err != nil {
+		converted := convertMap(next)
+		if err := mergo.Map(&merged, converted, mergo.WithOverride); err != nil {
 			log.Errorf("Failed to config merge error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
 			return err
 		}
+		walkLeaves("", converted, func(path string) {
+			origins[path] = kv.Key
+		})
+	}
+
+	// on reload, a failing resolver leaves the previous values in place
+	// instead of serving the unresolved ones
+	r.lock.Lock()
+	resolved := r.resolved
+	r.lock.Unlock()
+	var secrets map[string]struct{}
+	if resolved {
+		if secrets, err = r.resolve(merged); err != nil {
+			return err
+		}
 	}
+
 	r.lock.Lock()
 	r.values = merged
+	r.origins = origins
+	if secrets != nil {
+		r.secrets = secrets
+	}
 	r.lock.Unlock()
 	return nil
 }
@@ -71,10 +104,86 @@ func (r *reader) Source() ([]byte, error) {
 	return marshalJSON(convertMap(r.values))
 }
 
+// Resolve resolves a copy of the values and swaps it in on success,
+// so the values are left untouched when a resolver fails.
 func (r *reader) Resolve() error {
+	values, err := r.cloneMap()
+	if err != nil {
+		return err
+	}
+	secrets, err := r.resolve(values)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.values = values
+	r.secrets = secrets
+	r.resolved = true
+	r.lock.Unlock()
+	return nil
+}
+
+// resolve resolves the placeholders and the secret references of values in place,
+// and returns the keys resolved from secret references so far.
+func (r *reader) resolve(values map[string]interface{}) (map[string]struct{}, error) {
+	if err := r.opts.resolver(values); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	secrets := make(map[string]struct{}, len(r.secrets))
+	for k := range r.secrets {
+		secrets[k] = struct{}{}
+	}
+	r.lock.Unlock()
+	if len(r.opts.secrets) == 0 {
+		return secrets, nil
+	}
+	err := resolveSecrets(values, r.opts.secrets, func(path string) {
+		secrets[path] = struct{}{}
+	})
+	return secrets, err
+}
+
+func (r *reader) explain() []Origin {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]Origin, 0, len(r.origins))
+	for key, src := range r.origins {
+		// keys overridden by a value of another type no longer exist
+		if _, ok := readValue(r.values, key); !ok {
+			continue
+		}
+		_, secret := r.secrets[key]
+		res = append(res, Origin{Key: key, Source: src, Secret: secret})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
+
+func (r *reader) cloneOrigins() map[string]string {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	return r.opts.resolver(r.values)
+	origins := make(map[string]string, len(r.origins))
+	for k, v := range r.origins {
+		origins[k] = v
+	}
+	return origins
+}
+
+// walkLeaves calls fn with the path of every non-map value in src.
+func walkLeaves(prefix string, src interface{}, fn func(path string)) {
+	m, ok := src.(map[string]interface{})
+	if !ok {
+		if prefix != "" {
+			fn(prefix)
+		}
+		return
+	}
+	for k, v := range m {
+		walkLeaves(joinPath(prefix, k), v, fn)
+	}
 }
 
 func (r *reader) cloneMap() (map[string]interface{}, error) {
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SecretPrefix is the prefix of secret references in config values,
+// e.g. "secret://file/run/secrets/db_password" or "secret://env/DB_PASSWORD".
+const SecretPrefix = "secret://"
+
+// SecretResolver resolves a secret reference to its value.
+// ref is the part after "secret://<scheme>", e.g. "/run/secrets/db_password".
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretResolverFunc is a function adapter of SecretResolver.
+type SecretResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f SecretResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// FileSecretResolver reads the secret from the file at ref, trailing newlines are trimmed.
+func FileSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(ref string) (string, error) {
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	})
+}
+
+// EnvSecretResolver reads the secret from the environment variable named ref.
+func EnvSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(ref string) (string, error) {
+		name := strings.TrimPrefix(ref, "/")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s not found", name)
+		}
+		return v, nil
+	})
+}
+
+func defaultSecretResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{
+		"file": FileSecretResolver(),
+		"env":  EnvSecretResolver(),
+	}
+}
+
+// resolveSecrets replaces secret references in input with the resolved values,
+// and calls fn with the path of every resolved key.
+func resolveSecrets(input map[string]interface{}, resolvers map[string]SecretResolver, fn func(path string)) error {
+	resolve := func(path, s string) (string, error) {
+		ref := strings.TrimPrefix(s, SecretPrefix)
+		scheme, ref, _ := strings.Cut(ref, "/")
+		r, ok := resolvers[scheme]
+		if !ok {
+			return "", fmt.Errorf("unsupported secret scheme %q of key %s", scheme, path)
+		}
+		v, err := r.Resolve("/" + ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret of key %s: %w", path, err)
+		}
+		fn(path)
+		return v, nil
+	}
+
+	var walk func(path string, v interface{}) (interface{}, error)
+	walk = func(path string, v interface{}) (interface{}, error) {
+		switch vt := v.(type) {
+		case string:
+			if !strings.HasPrefix(vt, SecretPrefix) {
+				return vt, nil
+			}
+			return resolve(path, vt)
+		case map[string]interface{}:
+			for k, sub := range vt {
+				nv, err := walk(joinPath(path, k), sub)
+				if err != nil {
+					return nil, err
+				}
+				vt[k] = nv
+			}
+		case []interface{}:
+			for i, sub := range vt {
+				nv, err := walk(fmt.Sprintf("%s[%d]", path, i), sub)
+				if err != nil {
+					return nil, err
+				}
+				vt[i] = nv
+			}
+		}
+		return v, nil
+	}
+	_, err := walk("", input)
+	return err
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/apus-run/sea-kit/collection v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/idempotent v0.0.0-00010101000000-000000000000
 	github.com/apus-run/sea-kit/jwtx v0.0.0-20230908142142-a6b719f02c24
 	github.com/apus-run/sea-kit/ratelimit v0.0.0-00010101000000-000000000000
 	github.com/gavv/httpexpect/v2 v2.16.0
@@ -100,6 +101,7 @@ require (
 
 replace (
 	github.com/apus-run/sea-kit/collection => ../collection
+	github.com/apus-run/sea-kit/idempotent => ../idempotent
 	github.com/apus-run/sea-kit/ratelimit => ../ratelimit
 	github.com/ugorji/go => github.com/ugorji/go v1.2.11
 )
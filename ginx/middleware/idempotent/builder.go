@@ -0,0 +1,141 @@
+package idempotent
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apus-run/sea-kit/idempotent"
+)
+
+// HeaderKey 默认的幂等 key 请求头
+const HeaderKey = "Idempotency-Key"
+
+// Builder 基于 Idempotency-Key 请求头的幂等中间件.
+// 第一次请求的响应会被保存, 相同 key 的重试请求直接重放该响应,
+// 第一次请求尚未处理完时, 并发的重复请求返回 409.
+type Builder struct {
+	idem   *idempotent.Idempotent
+	header string
+
+	genKeyFn func(ctx *gin.Context, key string) string
+}
+
+func NewBuilder(idem *idempotent.Idempotent) *Builder {
+	return &Builder{
+		idem:   idem,
+		header: HeaderKey,
+		genKeyFn: func(ctx *gin.Context, key string) string {
+			var b strings.Builder
+			b.WriteString(ctx.Request.Method)
+			b.WriteString(":")
+			b.WriteString(ctx.Request.URL.Path)
+			b.WriteString(":")
+			b.WriteString(key)
+			return b.String()
+		},
+	}
+}
+
+// SetHeader 设置幂等 key 的请求头
+func (b *Builder) SetHeader(header string) *Builder {
+	b.header = header
+	return b
+}
+
+// SetKeyGenFunc 设置存储使用的 key, 默认为 method:path:Idempotency-Key
+func (b *Builder) SetKeyGenFunc(fn func(ctx *gin.Context, key string) string) *Builder {
+	b.genKeyFn = fn
+	return b
+}
+
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(b.header)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		key = b.genKeyFn(ctx, key)
+
+		rec, acquired, err := b.idem.Begin(ctx, key)
+		if err != nil {
+			log.Println(err)
+			// 严格模式下存储不可用时拒绝请求, 否则放行
+			if b.idem.Strict() {
+				ctx.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+			ctx.Next()
+			return
+		}
+		if !acquired {
+			b.replay(ctx, rec)
+			return
+		}
+
+		// 处理 panic 或者保存失败时删除处理中的记录, 否则重试请求会一直返回 409 直到记录过期
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			if err := b.idem.Abort(ctx, key); err != nil {
+				log.Println(err)
+			}
+		}()
+
+		w := &responseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Next()
+
+		// 服务端错误不保存, 允许客户端重试
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		if err = b.idem.Finish(ctx, key, &idempotent.Record{
+			Code:   w.Status(),
+			Header: w.Header().Clone(),
+			Body:   w.body.Bytes(),
+		}); err != nil {
+			log.Println(err)
+			return
+		}
+		finished = true
+	}
+}
+
+func (b *Builder) replay(ctx *gin.Context, rec *idempotent.Record) {
+	if rec.Status != idempotent.StatusDone {
+		ctx.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	for k, values := range rec.Header {
+		for _, v := range values {
+			ctx.Writer.Header().Add(k, v)
+		}
+	}
+	ctx.Writer.WriteHeader(rec.Code)
+	_, _ = ctx.Writer.Write(rec.Body)
+	ctx.Abort()
+}
+
+// responseWriter 记录响应 body
+type responseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
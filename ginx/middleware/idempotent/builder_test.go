@@ -0,0 +1,126 @@
+package idempotent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/idempotent"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls int64
+	server := gin.New()
+	idem := idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))
+	server.Use(NewBuilder(idem).Build())
+	server.POST("/orders", func(ctx *gin.Context) {
+		n := atomic.AddInt64(&calls, 1)
+		ctx.Header("X-Order", "1")
+		ctx.JSON(http.StatusCreated, gin.H{"calls": n})
+	})
+	server.POST("/fail", func(ctx *gin.Context) {
+		atomic.AddInt64(&calls, 1)
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	do := func(path, key string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			req.Header.Set(HeaderKey, key)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 第一次请求正常处理
+	resp := do("/orders", "abc")
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, `{"calls":1}`, resp.Body.String())
+
+	// 重试请求重放第一次的响应
+	resp = do("/orders", "abc")
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("X-Order"))
+	assert.Equal(t, `{"calls":1}`, resp.Body.String())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// 不同的 key 重新处理
+	resp = do("/orders", "def")
+	assert.Equal(t, `{"calls":2}`, resp.Body.String())
+
+	// 没有 key 不做幂等
+	do("/orders", "")
+	assert.Equal(t, int64(3), atomic.LoadInt64(&calls))
+
+	// 服务端错误不保存
+	do("/fail", "abc")
+	do("/fail", "abc")
+	assert.Equal(t, int64(5), atomic.LoadInt64(&calls))
+}
+
+func TestBuilder_BuildInProgress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := idempotent.NewMemoryStore()
+	idem := idempotent.New(idempotent.WithStore(store))
+	server := gin.New()
+	b := NewBuilder(idem)
+	server.Use(b.Build())
+	server.POST("/orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	// 模拟第一次请求仍在处理中
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/orders", nil)
+	_, acquired, err := idem.Begin(ctx, b.genKeyFn(ctx, "abc"))
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(HeaderKey, "abc")
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestBuilder_BuildPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls int64
+	server := gin.New()
+	server.Use(gin.CustomRecovery(func(ctx *gin.Context, _ any) {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	server.Use(NewBuilder(idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))).Build())
+	server.POST("/orders", func(ctx *gin.Context) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			panic("mock panic")
+		}
+		ctx.Status(http.StatusCreated)
+	})
+
+	do := func() int {
+		req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set(HeaderKey, "abc")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// panic 之后记录被删除, 重试请求可以再次处理
+	assert.Equal(t, http.StatusInternalServerError, do())
+	assert.Equal(t, http.StatusCreated, do())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+}
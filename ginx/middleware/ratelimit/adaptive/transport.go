@@ -0,0 +1,102 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	ratelimit "github.com/apus-run/sea-kit/ratelimit/adaptive"
+)
+
+// Transport 出站请求的自适应并发限流, 保护下游不被打垮
+// 超过并发上限的请求直接返回 ratelimit.ErrLimitExceed, 不会发送到下游
+//
+//	client := &http.Client{Transport: adaptive.NewTransport(limiter, nil)}
+type Transport struct {
+	limiter *ratelimit.Limiter
+	next    http.RoundTripper
+
+	// overloadCodes 视为下游过载的 http 状态码
+	overloadCodes map[int]struct{}
+}
+
+// NewTransport next 为 nil 时使用 http.DefaultTransport
+// 一个下游服务使用一个 limiter
+func NewTransport(limiter *ratelimit.Limiter, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		limiter: limiter,
+		next:    next,
+		overloadCodes: map[int]struct{}{
+			http.StatusTooManyRequests:    {},
+			http.StatusServiceUnavailable: {},
+			http.StatusGatewayTimeout:     {},
+		},
+	}
+}
+
+// OverloadCode 添加视为下游过载的 http 状态码
+func (t *Transport) OverloadCode(codes ...int) *Transport {
+	for _, c := range codes {
+		t.overloadCodes[c] = struct{}{}
+	}
+	return t
+}
+
+// RoundTrip 在响应 body 关闭时才释放配额并采样 RT, 流式响应的 RT 包含读取 body 的时间.
+// 调用方需要像使用 http.DefaultTransport 一样关闭 body
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.limiter.Allow()
+	if err != nil {
+		// RoundTripper 出错时也要关闭 body
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		done(ratelimit.DoneInfo{Err: err, Overload: isTimeout(err), Canceled: canceled(req, err)})
+		return nil, err
+	}
+	_, overload := t.overloadCodes[resp.StatusCode]
+	resp.Body = &doneBody{
+		ReadCloser: resp.Body,
+		done: func() {
+			done(ratelimit.DoneInfo{Overload: overload, Canceled: canceled(req, nil)})
+		},
+	}
+	return resp, nil
+}
+
+// doneBody 在 body 第一次关闭时调用 done
+type doneBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *doneBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// canceled 请求是否被调用方取消, 取消的请求不能反映下游的 RT
+func canceled(req *http.Request, err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(req.Context().Err(), context.Canceled)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
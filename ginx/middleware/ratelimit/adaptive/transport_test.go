@@ -0,0 +1,141 @@
+package adaptive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ratelimit "github.com/apus-run/sea-kit/ratelimit/adaptive"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	block := make(chan struct{})
+	code := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			<-block
+		}
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(1), ratelimit.WithBackoff(0.5))
+	require.NoError(t, err)
+	client := &http.Client{Transport: NewTransport(limiter, nil)}
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		close(started)
+		resp, err := client.Get(server.URL + "/block")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	assert.Eventually(t, func() bool {
+		return limiter.Stat().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	// 并发已满, 直接拒绝
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, ratelimit.ErrLimitExceed)
+
+	close(block)
+	<-finished
+
+	// 下游过载, limit 减半
+	limiter, err = ratelimit.NewLimiter(ratelimit.WithInitialLimit(4), ratelimit.WithBackoff(0.5))
+	require.NoError(t, err)
+	client = &http.Client{Transport: NewTransport(limiter, nil)}
+	code = http.StatusServiceUnavailable
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int64(2), limiter.Stat().Limit)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTransport_RoundTripClosesBody(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(1))
+	require.NoError(t, err)
+	done, err := limiter.Allow()
+	require.NoError(t, err)
+	defer done(ratelimit.DoneInfo{})
+
+	// 被拒绝的请求也要关闭 body
+	body := &closeRecorder{Reader: strings.NewReader("data")}
+	req, err := http.NewRequest(http.MethodPost, "http://localhost", body)
+	require.NoError(t, err)
+	_, err = NewTransport(limiter, nil).RoundTrip(req)
+	assert.ErrorIs(t, err, ratelimit.ErrLimitExceed)
+	assert.True(t, body.closed)
+}
+
+func TestTransport_RoundTripReleaseOnBodyClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(1))
+	require.NoError(t, err)
+	client := &http.Client{Transport: NewTransport(limiter, nil)}
+
+	// 读取 body 期间一直占用配额
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), limiter.Stat().InFlight)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, int64(0), limiter.Stat().InFlight)
+	// 重复关闭不会重复释放
+	_ = resp.Body.Close()
+	assert.Equal(t, int64(0), limiter.Stat().InFlight)
+}
+
+func TestTransport_RoundTripCanceled(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(4), ratelimit.WithBackoff(0.5),
+		ratelimit.WithWindow(100*time.Millisecond), ratelimit.WithBucket(10))
+	require.NoError(t, err)
+	client := &http.Client{Transport: NewTransport(limiter, nil)}
+
+	// 调用方取消的请求不视为下游过载
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	stat := limiter.Stat()
+	assert.Equal(t, int64(4), stat.Limit)
+	assert.Equal(t, int64(0), stat.InFlight)
+}
@@ -5,10 +5,13 @@ import (
 	"net/http"
 	"strings"
 
-	ratelimit "github.com/apus-run/sea-kit/ratelimit/redis"
 	"github.com/gin-gonic/gin"
+
+	"github.com/apus-run/sea-kit/ratelimit"
 )
 
+// Builder 限流中间件, limiter 可以是 ratelimit 下的任意实现
+// 每次请求都会写入 RateLimit-* 响应头, 被限流时额外写入 Retry-After
 type Builder struct {
 	limiter ratelimit.Limiter
 
@@ -35,14 +38,16 @@ func (b *Builder) SetKeyGenFunc(fn func(*gin.Context) string) *Builder {
 
 func (b *Builder) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		limited, err := b.limit(ctx)
+		res, err := b.limit(ctx)
 		if err != nil {
 			log.Println(err)
 			ctx.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
-		if limited {
-			log.Println(err)
+		for k, v := range res.Headers() {
+			ctx.Header(k, v)
+		}
+		if !res.Allowed {
 			ctx.AbortWithStatus(http.StatusTooManyRequests)
 			return
 		}
@@ -50,6 +55,6 @@ func (b *Builder) Build() gin.HandlerFunc {
 	}
 }
 
-func (b *Builder) limit(ctx *gin.Context) (bool, error) {
-	return b.limiter.Limit(ctx, b.genKeyFn(ctx))
+func (b *Builder) limit(ctx *gin.Context) (ratelimit.Result, error) {
+	return b.limiter.Allow(ctx, b.genKeyFn(ctx))
 }
@@ -5,12 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
-	ratelimit "github.com/apus-run/sea-kit/ratelimit/redis"
+	"github.com/apus-run/sea-kit/ratelimit"
 	limitmocks "github.com/apus-run/sea-kit/ratelimit/redis/mocks"
 )
 
@@ -75,14 +76,15 @@ func TestBuilder_Build(t *testing.T) {
 		reqBuilder func(t *testing.T) *http.Request
 
 		// 预期响应
-		wantCode int
+		wantCode   int
+		wantHeader map[string]string
 	}{
 		{
 			name: "不限流",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(false, nil)
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second}, nil)
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -93,13 +95,18 @@ func TestBuilder_Build(t *testing.T) {
 				return req
 			},
 			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				ratelimit.HeaderLimit:     "10",
+				ratelimit.HeaderRemaining: "9",
+				ratelimit.HeaderReset:     "1",
+			},
 		},
 		{
 			name: "限流",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(true, nil)
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{Limit: 10, RetryAfter: 1500 * time.Millisecond, ResetAfter: 2 * time.Second}, nil)
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -110,13 +117,19 @@ func TestBuilder_Build(t *testing.T) {
 				return req
 			},
 			wantCode: http.StatusTooManyRequests,
+			wantHeader: map[string]string{
+				ratelimit.HeaderLimit:      "10",
+				ratelimit.HeaderRemaining:  "0",
+				ratelimit.HeaderReset:      "2",
+				ratelimit.HeaderRetryAfter: "2",
+			},
 		},
 		{
 			name: "系统错误",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(false, errors.New("模拟系统错误"))
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{}, errors.New("模拟系统错误"))
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -145,6 +158,9 @@ func TestBuilder_Build(t *testing.T) {
 			server.ServeHTTP(recorder, req)
 
 			assert.Equal(t, tt.wantCode, recorder.Code)
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, recorder.Header().Get(k))
+			}
 		})
 	}
 }
@@ -157,15 +173,15 @@ func TestBuilder_limit(t *testing.T) {
 		reqBuilder func(t *testing.T) *http.Request
 
 		// 预期响应
-		want    bool
+		want    ratelimit.Result
 		wantErr error
 	}{
 		{
 			name: "不限流",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(false, nil)
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second}, nil)
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -176,14 +192,14 @@ func TestBuilder_limit(t *testing.T) {
 				req.RemoteAddr = "127.0.0.1:80"
 				return req
 			},
-			want: false,
+			want: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second},
 		},
 		{
 			name: "限流",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(true, nil)
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{Limit: 10, RetryAfter: 1500 * time.Millisecond, ResetAfter: 2 * time.Second}, nil)
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -194,14 +210,14 @@ func TestBuilder_limit(t *testing.T) {
 				req.RemoteAddr = "127.0.0.1:80"
 				return req
 			},
-			want: true,
+			want: ratelimit.Result{Limit: 10, RetryAfter: 1500 * time.Millisecond, ResetAfter: 2 * time.Second},
 		},
 		{
 			name: "限流代码出错",
 			mock: func(ctrl *gomock.Controller) ratelimit.Limiter {
 				limiter := limitmocks.NewMockLimiter(ctrl)
-				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).
-					Return(false, errors.New("模拟系统错误"))
+				limiter.EXPECT().Allow(gomock.Any(), gomock.Any()).
+					Return(ratelimit.Result{}, errors.New("模拟系统错误"))
 				return limiter
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -212,7 +228,6 @@ func TestBuilder_limit(t *testing.T) {
 				req.RemoteAddr = "127.0.0.1:80"
 				return req
 			},
-			want:    false,
 			wantErr: errors.New("模拟系统错误"),
 		},
 	}
@@ -0,0 +1,90 @@
+package outlier
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/serviceconfig"
+
+	// 注册客户端的 gRPC health 检测, service config 里配置了 healthCheckConfig 时,
+	// 服务端 (grpcx/server.WithIsHealth) 报告 NOT_SERVING 的节点不会进入 ReadySCs
+	_ "google.golang.org/grpc/health"
+)
+
+// LBConfig 负载均衡器的 service config
+type LBConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	OutlierDetection Config `json:"outlierDetection"`
+}
+
+// NewBalancerBuilder 返回带离群检测的负载均衡器 Builder,
+// 每个 ClientConn 有自己的 Detector, 节点的检测数据在 picker 重建时保留.
+// 离群检测默认关闭, 在 service config 里配置 outlierDetection 后开启, 例如 {"outlierDetection": {}} 使用默认配置开启
+func NewBalancerBuilder(name string, newPickerBuilder func(*Detector) base.PickerBuilder, config base.Config) balancer.Builder {
+	return &builder{
+		name:             name,
+		newPickerBuilder: newPickerBuilder,
+		config:           config,
+	}
+}
+
+type builder struct {
+	name             string
+	newPickerBuilder func(*Detector) base.PickerBuilder
+	config           base.Config
+}
+
+func (b *builder) Name() string {
+	return b.name
+}
+
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	// service config 里配置了 outlierDetection 之前不做离群检测
+	detector := NewDetector(Config{Disabled: true})
+	bb := base.NewBalancerBuilder(b.name, b.newPickerBuilder(detector), b.config)
+	return &outlierBalancer{
+		Balancer: bb.Build(cc, opts),
+		detector: detector,
+	}
+}
+
+// ParseConfig 解析 service config 里的 outlierDetection
+func (b *builder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var raw struct {
+		OutlierDetection json.RawMessage `json:"outlierDetection"`
+	}
+	if len(js) > 0 {
+		if err := json.Unmarshal(js, &raw); err != nil {
+			return nil, err
+		}
+	}
+	// 没有配置 outlierDetection 时不开启, 离群检测需要显式开启
+	if len(raw.OutlierDetection) == 0 || string(raw.OutlierDetection) == "null" {
+		return &LBConfig{OutlierDetection: Config{Disabled: true}}, nil
+	}
+	cfg, err := ParseConfig(raw.OutlierDetection)
+	if err != nil {
+		return nil, err
+	}
+	return &LBConfig{OutlierDetection: cfg}, nil
+}
+
+type outlierBalancer struct {
+	balancer.Balancer
+	detector *Detector
+}
+
+func (b *outlierBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	if cfg, ok := s.BalancerConfig.(*LBConfig); ok {
+		b.detector.UpdateConfig(cfg.OutlierDetection)
+	}
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+func (b *outlierBalancer) ExitIdle() {
+	if e, ok := b.Balancer.(balancer.ExitIdler); ok {
+		e.ExitIdle()
+	}
+}
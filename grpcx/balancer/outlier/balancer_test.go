@@ -0,0 +1,38 @@
+package outlier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+func TestBuilder_ParseConfig(t *testing.T) {
+	b := NewBalancerBuilder("test_outlier", func(*Detector) base.PickerBuilder {
+		return nil
+	}, base.Config{HealthCheck: true})
+	parser, ok := b.(balancer.ConfigParser)
+	assert.True(t, ok)
+
+	// 没有配置 outlierDetection 时不开启
+	cfg, err := parser.ParseConfig([]byte(`{}`))
+	assert.NoError(t, err)
+	assert.True(t, cfg.(*LBConfig).OutlierDetection.Disabled)
+
+	cfg, err = parser.ParseConfig([]byte(`{"outlierDetection": {}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg.(*LBConfig).OutlierDetection)
+
+	cfg, err = parser.ParseConfig([]byte(`{"outlierDetection": {"disabled": true}}`))
+	assert.NoError(t, err)
+	assert.True(t, cfg.(*LBConfig).OutlierDetection.Disabled)
+
+	cfg, err = parser.ParseConfig([]byte(`{"outlierDetection": {"maxEjectionTime": "1m"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(time.Minute), cfg.(*LBConfig).OutlierDetection.MaxEjectionTime)
+
+	_, err = parser.ParseConfig([]byte(`{"outlierDetection": {"baseEjectionTime": "1h"}}`))
+	assert.Error(t, err)
+}
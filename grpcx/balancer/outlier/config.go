@@ -0,0 +1,112 @@
+package outlier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config 离群检测配置, 可以放在 service config 的负载均衡配置里:
+//
+//	{"loadBalancingConfig": [{"p2c_ewma": {"outlierDetection": {"consecutiveErrors": 3, "baseEjectionTime": "10s"}}}]}
+//
+// 没有出现的字段使用 DefaultConfig 里的默认值, 没有配置 outlierDetection 时不做离群检测
+type Config struct {
+	// Disabled 关闭离群检测
+	Disabled bool `json:"disabled,omitempty"`
+	// Interval 成功率检测的周期
+	Interval Duration `json:"interval,omitempty"`
+	// BaseEjectionTime 第 n 次被摘除的时长为 BaseEjectionTime * 2^(n-1)
+	BaseEjectionTime Duration `json:"baseEjectionTime,omitempty"`
+	// MaxEjectionTime 摘除时长的上限
+	MaxEjectionTime Duration `json:"maxEjectionTime,omitempty"`
+	// MaxEjectionPercent 最多摘除的节点百分比, 大于 0 时至少可以摘除 1 个节点
+	MaxEjectionPercent int `json:"maxEjectionPercent,omitempty"`
+	// ConsecutiveErrors 连续失败多少次后摘除, 0 表示关闭
+	ConsecutiveErrors int `json:"consecutiveErrors,omitempty"`
+	// SuccessRate 成功率检测, 为 null 表示关闭
+	SuccessRate *SuccessRateConfig `json:"successRate,omitempty"`
+}
+
+// SuccessRateConfig 成功率检测配置
+// 每个周期内成功率低于 mean - StdevFactor * stdev 的节点会被摘除
+type SuccessRateConfig struct {
+	// StdevFactor 标准差系数
+	StdevFactor float64 `json:"stdevFactor,omitempty"`
+	// MinHosts 请求数达标的节点少于 MinHosts 时不做检测
+	MinHosts int `json:"minHosts,omitempty"`
+	// MinRequests 一个周期内请求数少于 MinRequests 的节点不参与检测
+	MinRequests int `json:"minRequests,omitempty"`
+}
+
+// DefaultConfig 默认配置, 参考 envoy 的 outlier detection
+func DefaultConfig() Config {
+	return Config{
+		Interval:           Duration(10 * time.Second),
+		BaseEjectionTime:   Duration(30 * time.Second),
+		MaxEjectionTime:    Duration(300 * time.Second),
+		MaxEjectionPercent: 10,
+		ConsecutiveErrors:  5,
+		SuccessRate: &SuccessRateConfig{
+			StdevFactor: 1.9,
+			MinHosts:    5,
+			MinRequests: 100,
+		},
+	}
+}
+
+// ParseConfig 在默认配置的基础上解析 JSON 配置
+func ParseConfig(js []byte) (Config, error) {
+	cfg := DefaultConfig()
+	if len(js) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(js, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Disabled {
+		return nil
+	}
+	if c.Interval <= 0 || c.BaseEjectionTime <= 0 {
+		return errors.New("outlier: interval and baseEjectionTime must be positive")
+	}
+	if c.MaxEjectionTime < c.BaseEjectionTime {
+		return errors.New("outlier: maxEjectionTime must not be less than baseEjectionTime")
+	}
+	if c.MaxEjectionPercent < 0 || c.MaxEjectionPercent > 100 {
+		return fmt.Errorf("outlier: invalid maxEjectionPercent %d", c.MaxEjectionPercent)
+	}
+	if c.ConsecutiveErrors < 0 {
+		return fmt.Errorf("outlier: invalid consecutiveErrors %d", c.ConsecutiveErrors)
+	}
+	if sr := c.SuccessRate; sr != nil && (sr.StdevFactor <= 0 || sr.MinHosts < 1 || sr.MinRequests < 1) {
+		return errors.New("outlier: stdevFactor, minHosts and minRequests of successRate must be positive")
+	}
+	return nil
+}
+
+// Duration 支持 "10s" 这种格式的 JSON 时长
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("outlier: duration must be a string like \"10s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
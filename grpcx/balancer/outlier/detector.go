@@ -0,0 +1,313 @@
+// Package outlier 实现负载均衡层的离群检测 (outlier detection):
+//
+//   - 连续失败: 节点连续失败 ConsecutiveErrors 次立即摘除
+//   - 成功率: 每个 Interval 统计各节点的成功率, 低于 mean - StdevFactor * stdev 的节点被摘除
+//
+// 第 n 次被摘除的时长为 BaseEjectionTime * 2^(n-1), 不超过 MaxEjectionTime,
+// 节点在一个周期内没有被摘除时 n 减一. 被摘除的节点数不超过 MaxEjectionPercent,
+// 所有节点都被摘除时 Filter 返回全部节点, 避免无节点可用.
+//
+// p2c 和 wrr 负载均衡器通过 NewBalancerBuilder 为每个 ClientConn 创建一个 Detector,
+// 只有 service config 里配置了 outlierDetection 时才开启检测.
+package outlier
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Detector 离群检测器, nil 表示不做离群检测.
+// 配置、节点列表和被摘除的节点都以不可变快照的形式原子地发布, Filter 和 Report 的常规路径不加锁,
+// 只有摘除节点和周期检测时才需要 mu.
+type Detector struct {
+	// mu 保护摘除和周期检测, 以及 host 的 multiplier 和 ejectedUntil
+	mu  sync.Mutex
+	cfg atomic.Pointer[Config]
+	// hosts 节点列表的快照, 只在 Update 时替换
+	hosts atomic.Pointer[map[string]*host]
+	// ejected 被摘除节点及其恢复时间的快照, 没有被摘除的节点时为 nil
+	ejected atomic.Pointer[map[string]time.Time]
+	// lastEval 上一次周期检测的时间, UnixNano
+	lastEval atomic.Int64
+	now      func() time.Time
+}
+
+type host struct {
+	// 连续失败次数
+	consecutive atomic.Int64
+	// 当前周期内的成功和失败次数
+	success atomic.Int64
+	failure atomic.Int64
+
+	// 被摘除的次数, 决定摘除时长
+	multiplier   int
+	ejectedUntil time.Time
+}
+
+func (h *host) ejected(now time.Time) bool {
+	return now.Before(h.ejectedUntil)
+}
+
+func (h *host) reset() {
+	h.consecutive.Store(0)
+	h.success.Store(0)
+	h.failure.Store(0)
+	h.multiplier = 0
+	h.ejectedUntil = time.Time{}
+}
+
+// NewDetector 创建离群检测器
+func NewDetector(cfg Config) *Detector {
+	d := &Detector{now: time.Now}
+	d.cfg.Store(&cfg)
+	d.hosts.Store(&map[string]*host{})
+	d.lastEval.Store(time.Now().UnixNano())
+	return d
+}
+
+// UpdateConfig 更新配置, 已有的统计数据保留
+func (d *Detector) UpdateConfig(cfg Config) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg.Store(&cfg)
+	if cfg.Disabled {
+		for _, h := range *d.hosts.Load() {
+			h.reset()
+		}
+	}
+	d.publish(d.now())
+}
+
+// Update 同步节点列表, 新节点加入检测, 下线的节点删除
+func (d *Detector) Update(addrs []string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old := *d.hosts.Load()
+	hosts := make(map[string]*host, len(addrs))
+	for _, addr := range addrs {
+		if h, ok := old[addr]; ok {
+			hosts[addr] = h
+		} else {
+			hosts[addr] = &host{}
+		}
+	}
+	d.hosts.Store(&hosts)
+	d.publish(d.now())
+}
+
+// Report 记录一次调用的结果
+func (d *Detector) Report(addr string, err error) {
+	if d == nil {
+		return
+	}
+	cfg := d.cfg.Load()
+	if cfg.Disabled {
+		return
+	}
+	h, ok := (*d.hosts.Load())[addr]
+	if !ok {
+		return
+	}
+	now := d.now()
+	if !Failure(err) {
+		h.success.Add(1)
+		h.consecutive.Store(0)
+	} else {
+		h.failure.Add(1)
+		if n := h.consecutive.Add(1); cfg.ConsecutiveErrors > 0 && n >= int64(cfg.ConsecutiveErrors) {
+			d.mu.Lock()
+			// 加锁期间其他请求可能已经摘除了该节点
+			if h.consecutive.Load() >= int64(cfg.ConsecutiveErrors) && !h.ejected(now) && d.eject(h, now) {
+				d.publish(now)
+			}
+			d.mu.Unlock()
+		}
+	}
+	d.maybeEvaluate(now)
+}
+
+// Ejected 节点当前是否被摘除
+func (d *Detector) Ejected(addr string) bool {
+	if d == nil || d.cfg.Load().Disabled {
+		return false
+	}
+	now := d.now()
+	d.maybeEvaluate(now)
+	ejected := d.ejected.Load()
+	if ejected == nil {
+		return false
+	}
+	until, ok := (*ejected)[addr]
+	return ok && now.Before(until)
+}
+
+// Filter 过滤掉被摘除的节点, 全部被摘除时返回 conns. 没有被摘除的节点时不分配内存
+func Filter[T any](d *Detector, conns []T, addr func(T) string) []T {
+	if d == nil || len(conns) == 0 || d.cfg.Load().Disabled {
+		return conns
+	}
+	now := d.now()
+	d.maybeEvaluate(now)
+	snapshot := d.ejected.Load()
+	if snapshot == nil {
+		return conns
+	}
+	ejected := *snapshot
+
+	var res []T
+	for i, c := range conns {
+		if until, ok := ejected[addr(c)]; !ok || !now.Before(until) {
+			if res != nil {
+				res = append(res, c)
+			}
+			continue
+		}
+		if res == nil {
+			res = make([]T, i, len(conns))
+			copy(res, conns[:i])
+		}
+	}
+	if len(res) == 0 {
+		return conns
+	}
+	return res
+}
+
+// publish 发布被摘除节点的快照, 调用方需要持有 mu
+func (d *Detector) publish(now time.Time) {
+	var ejected map[string]time.Time
+	for addr, h := range *d.hosts.Load() {
+		if !h.ejected(now) {
+			continue
+		}
+		if ejected == nil {
+			ejected = make(map[string]time.Time)
+		}
+		ejected[addr] = h.ejectedUntil
+	}
+	if ejected == nil {
+		d.ejected.Store(nil)
+		return
+	}
+	d.ejected.Store(&ejected)
+}
+
+// eject 摘除节点, 超过 MaxEjectionPercent 时不摘除. 调用方需要持有 mu
+func (d *Detector) eject(h *host, now time.Time) bool {
+	cfg := d.cfg.Load()
+	hosts := *d.hosts.Load()
+	ejected := 0
+	for _, o := range hosts {
+		if o.ejected(now) {
+			ejected++
+		}
+	}
+	max := len(hosts) * cfg.MaxEjectionPercent / 100
+	if max == 0 && cfg.MaxEjectionPercent > 0 {
+		max = 1
+	}
+	if ejected >= max {
+		return false
+	}
+
+	h.multiplier++
+	ejection := time.Duration(cfg.BaseEjectionTime) << (h.multiplier - 1)
+	if ejection > time.Duration(cfg.MaxEjectionTime) || ejection <= 0 {
+		ejection = time.Duration(cfg.MaxEjectionTime)
+	}
+	h.ejectedUntil = now.Add(ejection)
+	h.consecutive.Store(0)
+	return true
+}
+
+// maybeEvaluate 到了检测周期时加锁检测一次
+func (d *Detector) maybeEvaluate(now time.Time) {
+	if now.UnixNano()-d.lastEval.Load() < int64(d.cfg.Load().Interval) {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.evaluate(now)
+}
+
+// evaluate 每个周期检测一次成功率, 调用方需要持有 mu
+func (d *Detector) evaluate(now time.Time) {
+	cfg := d.cfg.Load()
+	if now.UnixNano()-d.lastEval.Load() < int64(cfg.Interval) {
+		return
+	}
+	d.lastEval.Store(now.UnixNano())
+
+	hosts := *d.hosts.Load()
+	if sr := cfg.SuccessRate; sr != nil {
+		var rates []float64
+		for _, h := range hosts {
+			success, failure := h.success.Load(), h.failure.Load()
+			if total := success + failure; !h.ejected(now) && total >= int64(sr.MinRequests) {
+				rates = append(rates, float64(success)/float64(total))
+			}
+		}
+		if len(rates) >= sr.MinHosts {
+			mean, stdev := meanStdev(rates)
+			threshold := mean - sr.StdevFactor*stdev
+			for _, h := range hosts {
+				success, failure := h.success.Load(), h.failure.Load()
+				total := success + failure
+				if h.ejected(now) || total < int64(sr.MinRequests) {
+					continue
+				}
+				if float64(success)/float64(total) < threshold {
+					d.eject(h, now)
+				}
+			}
+		}
+	}
+
+	for _, h := range hosts {
+		// 健康了一个周期, 下次摘除的时长减半
+		if !h.ejected(now) && h.multiplier > 0 && h.ejectedUntil.Add(time.Duration(cfg.Interval)).Before(now) {
+			h.multiplier--
+		}
+		h.success.Store(0)
+		h.failure.Store(0)
+	}
+	d.publish(now)
+}
+
+func meanStdev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// Failure 判断调用结果是否算作节点的失败, 业务错误不算
+func Failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
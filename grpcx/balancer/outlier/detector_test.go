@@ -0,0 +1,238 @@
+package outlier
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "unavailable")
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestDetector(cfg Config, hosts int) (*Detector, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	d := NewDetector(cfg)
+	d.now = clock.now
+	d.lastEval.Store(clock.t.UnixNano())
+
+	var addrs []string
+	for i := 0; i < hosts; i++ {
+		addrs = append(addrs, strconv.Itoa(i))
+	}
+	d.Update(addrs)
+	return d, clock
+}
+
+func TestDetector_ConsecutiveErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SuccessRate = nil
+	cfg.MaxEjectionPercent = 50
+	d, clock := newTestDetector(cfg, 4)
+
+	for i := 0; i < 4; i++ {
+		d.Report("0", errUnavailable)
+	}
+	// 业务错误不算失败, 成功会重置连续失败次数
+	d.Report("0", status.Error(codes.NotFound, "not found"))
+	d.Report("0", nil)
+	for i := 0; i < 4; i++ {
+		d.Report("0", errUnavailable)
+	}
+	assert.False(t, d.Ejected("0"))
+
+	d.Report("0", errUnavailable)
+	assert.True(t, d.Ejected("0"))
+
+	// 摘除时间到了之后恢复
+	clock.add(30 * time.Second)
+	assert.False(t, d.Ejected("0"))
+
+	// 第二次摘除时长翻倍
+	for i := 0; i < 5; i++ {
+		d.Report("0", errUnavailable)
+	}
+	clock.add(59 * time.Second)
+	assert.True(t, d.Ejected("0"))
+	clock.add(time.Second)
+	assert.False(t, d.Ejected("0"))
+}
+
+func TestDetector_MaxEjectionTime(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SuccessRate = nil
+	cfg.ConsecutiveErrors = 1
+	cfg.MaxEjectionTime = Duration(45 * time.Second)
+	d, clock := newTestDetector(cfg, 1)
+
+	d.Report("0", errUnavailable)
+	clock.add(30 * time.Second)
+	d.Report("0", errUnavailable)
+	clock.add(44 * time.Second)
+	assert.True(t, d.Ejected("0"))
+	clock.add(time.Second)
+	assert.False(t, d.Ejected("0"))
+}
+
+func TestDetector_MaxEjectionPercent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SuccessRate = nil
+	cfg.ConsecutiveErrors = 1
+	d, _ := newTestDetector(cfg, 4)
+
+	// 10% 的 4 个节点也至少可以摘除 1 个
+	d.Report("0", errUnavailable)
+	d.Report("1", errUnavailable)
+	assert.True(t, d.Ejected("0"))
+	assert.False(t, d.Ejected("1"))
+}
+
+func TestDetector_SuccessRate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConsecutiveErrors = 0
+	cfg.MaxEjectionPercent = 50
+	d, clock := newTestDetector(cfg, 6)
+
+	for i := 0; i < 6; i++ {
+		addr := strconv.Itoa(i)
+		for j := 0; j < 100; j++ {
+			var err error
+			if addr == "5" && j%2 == 0 {
+				err = errUnavailable
+			}
+			d.Report(addr, err)
+		}
+	}
+	assert.False(t, d.Ejected("5"))
+
+	clock.add(10 * time.Second)
+	assert.True(t, d.Ejected("5"))
+	for i := 0; i < 5; i++ {
+		assert.False(t, d.Ejected(strconv.Itoa(i)))
+	}
+}
+
+func TestDetector_Update(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConsecutiveErrors = 1
+	d, _ := newTestDetector(cfg, 2)
+
+	d.Report("0", errUnavailable)
+	assert.True(t, d.Ejected("0"))
+
+	// 下线的节点删除, 重新上线后重新统计
+	d.Update([]string{"1"})
+	assert.False(t, d.Ejected("0"))
+	d.Update([]string{"0", "1"})
+	assert.False(t, d.Ejected("0"))
+}
+
+func TestFilter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConsecutiveErrors = 1
+	cfg.MaxEjectionPercent = 100
+	d, _ := newTestDetector(cfg, 2)
+	self := func(s string) string { return s }
+
+	conns := []string{"0", "1"}
+	assert.Equal(t, conns, Filter[string](nil, conns, self))
+
+	d.Report("0", errUnavailable)
+	assert.Equal(t, []string{"1"}, Filter(d, conns, self))
+
+	// 全部被摘除时返回全部节点
+	d.Report("1", errUnavailable)
+	assert.Equal(t, conns, Filter(d, conns, self))
+
+	d.UpdateConfig(Config{Disabled: true})
+	assert.Equal(t, conns, Filter(d, conns, self))
+}
+
+func TestFilter_NoAlloc(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConsecutiveErrors = 1
+	d, _ := newTestDetector(cfg, 3)
+	self := func(s string) string { return s }
+	conns := []string{"0", "1", "2"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = Filter(d, conns, self)
+		d.Report("1", nil)
+	})
+	assert.Zero(t, allocs)
+
+	d.Report("0", errUnavailable)
+	assert.Equal(t, []string{"1", "2"}, Filter(d, conns, self))
+}
+
+func TestDetector_Concurrent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ConsecutiveErrors = 3
+	cfg.MaxEjectionPercent = 50
+	d := NewDetector(cfg)
+	d.Update([]string{"0", "1", "2", "3"})
+	self := func(s string) string { return s }
+	conns := []string{"0", "1", "2", "3"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				var err error
+				if i%2 == 0 {
+					err = errUnavailable
+				}
+				_ = Filter(d, conns, self)
+				d.Report(strconv.Itoa(i%4), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// 只有 0 和 2 失败, 最多摘除一半的节点
+	assert.True(t, d.Ejected("0") || d.Ejected("2"))
+	assert.False(t, d.Ejected("1"))
+	assert.False(t, d.Ejected("3"))
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+
+	cfg, err = ParseConfig([]byte(`{"consecutiveErrors": 3, "baseEjectionTime": "10s", "successRate": {"minHosts": 3}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.ConsecutiveErrors)
+	assert.Equal(t, Duration(10*time.Second), cfg.BaseEjectionTime)
+	assert.Equal(t, 3, cfg.SuccessRate.MinHosts)
+	assert.Equal(t, 100, cfg.SuccessRate.MinRequests)
+
+	cfg, err = ParseConfig([]byte(`{"successRate": null}`))
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.SuccessRate)
+
+	_, err = ParseConfig([]byte(`{"interval": 10}`))
+	assert.Error(t, err)
+	_, err = ParseConfig([]byte(`{"maxEjectionPercent": 200}`))
+	assert.Error(t, err)
+}
+
+func TestFailure(t *testing.T) {
+	assert.False(t, Failure(nil))
+	assert.False(t, Failure(status.Error(codes.InvalidArgument, "")))
+	assert.True(t, Failure(errUnavailable))
+	assert.True(t, Failure(errors.New("unknown")))
+}
@@ -15,6 +15,9 @@ import (
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/resolver"
+
+	"github.com/apus-run/sea-kit/grpcx/balancer/outlier"
+	"github.com/apus-run/sea-kit/grpcx/balancer/routing"
 )
 
 const (
@@ -36,7 +39,10 @@ func init() {
 	balancer.Register(newBuilder())
 }
 
-type p2cPickerBuilder struct{}
+type p2cPickerBuilder struct {
+	// 离群检测, 同一个 ClientConn 的 picker 共享
+	detector *outlier.Detector
+}
 
 // Build  每次有后端节点新增/下线都会触发初始化
 func (b *p2cPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
@@ -46,63 +52,83 @@ func (b *p2cPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 	}
 
 	var conns []*subConn
+	addrs := make([]string, 0, len(readySCs))
 	for conn, connInfo := range readySCs {
 		conns = append(conns, &subConn{
 			addr:    connInfo.Address,
 			conn:    conn,
 			success: initSuccess,
 		})
+		addrs = append(addrs, connInfo.Address.Addr)
 	}
+	b.detector.Update(addrs)
 
 	// 每次有后端节点上下线时会调用，重新初始化 `[]*subConn`
 	return &p2cPicker{
 		conns: conns,
-		r:     rand.New(rand.NewSource(time.Now().UnixNano())),
-		stamp: NewAtomicDuration(),
+		selector: routing.NewSelector(conns, func(c *subConn) resolver.Address {
+			return c.addr
+		}),
+		detector: b.detector,
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		stamp:    NewAtomicDuration(),
 	}
 }
 
 func newBuilder() balancer.Builder {
-	return base.NewBalancerBuilder(Name, new(p2cPickerBuilder), base.Config{HealthCheck: true})
+	return outlier.NewBalancerBuilder(Name, func(detector *outlier.Detector) base.PickerBuilder {
+		return &p2cPickerBuilder{detector: detector}
+	}, base.Config{HealthCheck: true})
 }
 
 type p2cPicker struct {
-	conns []*subConn // 保存所有服务的节点信息
-	r     *rand.Rand
-	stamp *AtomicDuration
-	mu    sync.Mutex
+	conns    []*subConn // 保存所有服务的节点信息
+	selector *routing.Selector[*subConn]
+	detector *outlier.Detector
+	r        *rand.Rand
+	stamp    *AtomicDuration
+	mu       sync.Mutex
 }
 
 // Pick 会在每次请求时调用，用于选择一个节点进行请求。
 // 在 Pick 方法里实现了 P2C [算法](https://github.com/zeromicro/go-zero/blob/master/zrpc/internal/balancer/p2c/p2c.go#L75)，挑选合适的节点，并通过节点的 EWMA 值计算负载情况，返回负载低的节点供 gRPC 使用。go-zero 是使用的下面的 Pick 逻辑：
 // 1.多选二，基于 P2C 算法
 // 2.二再选一，基于 EWMA 负载低的原则
-func (p *p2cPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
+// 在此之前会先按请求的路由提示 (zone、版本、标签) 过滤节点, 详见 routing.Selector, 再去掉被离群检测摘除的节点
+func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	conns, err := p.selector.Select(info.Ctx)
+	if err != nil {
+		return emptyPickResult, err
+	}
+	conns = outlier.Filter(p.detector, conns, func(c *subConn) string {
+		return c.addr.Addr
+	})
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	var chosen *subConn
-	switch len(p.conns) {
+	switch len(conns) {
 	case 0:
 		// 无节点
 		return emptyPickResult, balancer.ErrNoSubConnAvailable
 	case 1:
 		// only one，直接返回
-		chosen = p.choose(p.conns[0], nil)
+		chosen = p.choose(conns[0], nil)
 	case 2:
 		// only two，直接进入 choose，通过 EWMA 值 计算负载，并返回负载低的节点返回供 gRPC 使用
-		chosen = p.choose(p.conns[0], p.conns[1])
+		chosen = p.choose(conns[0], conns[1])
 	default:
 		// 超过 3 个节点，P2C 选择两个
 		var node1, node2 *subConn
 		for i := 0; i < pickTimes; i++ {
-			a := p.r.Intn(len(p.conns))
-			b := p.r.Intn(len(p.conns) - 1)
+			a := p.r.Intn(len(conns))
+			b := p.r.Intn(len(conns) - 1)
 			if b >= a {
 				b++
 			}
-			node1 = p.conns[a]
-			node2 = p.conns[b]
+			node1 = conns[a]
+			node2 = conns[b]
 			// 如果这次选择的节点达到了健康要求, 就中断选择
 			if node1.healthy() && node2.healthy() {
 				break
@@ -161,6 +187,7 @@ func (p *p2cPicker) buildDoneFunc(c *subConn) func(info balancer.DoneInfo) {
 		}
 		osucc := atomic.LoadUint64(&c.success)
 		atomic.StoreUint64(&c.success, uint64(float64(osucc)*w+float64(success)*(1-w)))
+		p.detector.Report(c.addr.Addr, info.Err)
 
 		// 按需打印节点日志
 		stamp := p.stamp.Load()
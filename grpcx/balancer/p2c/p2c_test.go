@@ -10,11 +10,14 @@ import (
 
 	"github.com/apus-run/sea-kit/utils"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/sea-kit/grpcx/balancer/routing"
 )
 
 func TestP2cPicker_PickNil(t *testing.T) {
@@ -117,6 +120,41 @@ func TestP2cPicker_Pick(t *testing.T) {
 	}
 }
 
+func TestP2cPicker_PickWithRouting(t *testing.T) {
+	builder := new(p2cPickerBuilder)
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	for i, version := range []string{"v1", "v1", "v2"} {
+		ready[mockClientConn{
+			id: utils.NewULID(),
+		}] = base.SubConnInfo{
+			Address: resolver.Address{
+				Addr:       strconv.Itoa(i),
+				Attributes: attributes.New(routing.MetadataVersion, version),
+			},
+		}
+	}
+	picker := builder.Build(base.PickerBuildInfo{
+		ReadySCs: ready,
+	})
+
+	ctx := routing.NewContext(context.Background(), routing.Hints{Version: "v2"})
+	for i := 0; i < 100; i++ {
+		result, err := picker.Pick(balancer.PickInfo{
+			FullMethodName: "/",
+			Ctx:            ctx,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", ready[result.SubConn].Address.Attributes.Value(routing.MetadataVersion))
+		result.Done(balancer.DoneInfo{})
+	}
+
+	_, err := picker.Pick(balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            routing.NewContext(context.Background(), routing.Hints{Version: "v3"}),
+	})
+	assert.ErrorIs(t, err, routing.ErrNoMatchedInstance)
+}
+
 func TestPickerWithEmptyConns(t *testing.T) {
 	var picker p2cPicker
 	_, err := picker.Pick(balancer.PickInfo{
@@ -0,0 +1,184 @@
+// Package routing 基于 registry.ServiceInstance 的 Version 和 Metadata 实现请求级别的路由,
+// 供 p2c、wrr 等负载均衡器在 Pick 时过滤节点:
+//
+//  1. 按 Hints.Version 和 Hints.Labels 过滤实例, 用于金丝雀和蓝绿发布
+//  2. 没有指定版本时, 按实例 metadata 里的 traffic_weight 在各版本之间分流
+//  3. 优先选择与 Hints.Zone (默认为 SetLocalZone 设置的本地 zone) 相同 zone 的实例
+//
+// 路由提示可以通过 NewContext 放在 context 里, 也可以放在 outgoing metadata 里,
+// 后者方便网关按租户等维度把流量导向金丝雀版本.
+package routing
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// 实例 metadata 里的路由相关 key
+const (
+	// MetadataZone 实例所在的 zone
+	MetadataZone = "zone"
+	// MetadataVersion 实例版本, 优先使用 ServiceInstance.Version
+	MetadataVersion = "version"
+	// MetadataTrafficWeight 实例所在版本的流量权重, 同一版本的实例取最大值
+	MetadataTrafficWeight = "traffic_weight"
+
+	// InstanceAttributeKey resolver 把 *registry.ServiceInstance 放在 resolver.Address.Attributes 里的 key
+	InstanceAttributeKey = "rawServiceInstance"
+)
+
+// outgoing metadata 里的路由提示
+const (
+	HeaderZone    = "x-route-zone"
+	HeaderVersion = "x-route-version"
+	// HeaderLabels 格式为 k1=v1,k2=v2
+	HeaderLabels = "x-route-labels"
+	// HeaderKey 分流的粘性 key, 例如租户 ID
+	HeaderKey = "x-route-key"
+)
+
+// Hints 单次请求的路由提示
+type Hints struct {
+	// Zone 优先选择的 zone, 为空时使用本地 zone
+	Zone string
+	// Version 只选择该版本的实例
+	Version string
+	// Labels 只选择 metadata 全部匹配的实例
+	Labels map[string]string
+	// Key 不为空时, 版本分流按 Key 的哈希值选择, 同一个 Key 总是落到同一个版本
+	Key string
+	// Fallback 按版本和标签过滤不到实例时, 退回到全部实例, 默认直接返回错误
+	Fallback bool
+}
+
+func (h Hints) empty() bool {
+	return h.Zone == "" && h.Version == "" && len(h.Labels) == 0 && h.Key == ""
+}
+
+type hintsKey struct{}
+
+// NewContext 返回携带路由提示的 context
+func NewContext(ctx context.Context, hints Hints) context.Context {
+	return context.WithValue(ctx, hintsKey{}, hints)
+}
+
+// FromContext 读取路由提示, context 里的优先, 其次是 outgoing metadata
+func FromContext(ctx context.Context) (Hints, bool) {
+	if ctx == nil {
+		return Hints{}, false
+	}
+	if hints, ok := ctx.Value(hintsKey{}).(Hints); ok {
+		return hints, true
+	}
+	// 先检查是否有路由相关的 header, 没有时不复制 metadata
+	if !hasRouteHeader(ctx) {
+		return Hints{}, false
+	}
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return Hints{}, false
+	}
+	hints := Hints{
+		Zone:    first(md, HeaderZone),
+		Version: first(md, HeaderVersion),
+		Labels:  parseLabels(first(md, HeaderLabels)),
+		Key:     first(md, HeaderKey),
+	}
+	return hints, !hints.empty()
+}
+
+// AppendToOutgoingContext 把路由提示写入 outgoing metadata, 以便透传给下游
+func AppendToOutgoingContext(ctx context.Context, hints Hints) context.Context {
+	var kv []string
+	if hints.Zone != "" {
+		kv = append(kv, HeaderZone, hints.Zone)
+	}
+	if hints.Version != "" {
+		kv = append(kv, HeaderVersion, hints.Version)
+	}
+	if len(hints.Labels) > 0 {
+		kv = append(kv, HeaderLabels, formatLabels(hints.Labels))
+	}
+	if hints.Key != "" {
+		kv = append(kv, HeaderKey, hints.Key)
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+var localZone atomic.Value
+
+// SetLocalZone 设置本地 zone, 没有路由提示时也会优先选择同 zone 的实例
+func SetLocalZone(zone string) {
+	localZone.Store(zone)
+}
+
+// LocalZone 返回本地 zone
+func LocalZone() string {
+	zone, _ := localZone.Load().(string)
+	return zone
+}
+
+// headerPrefix 路由相关 header 的公共前缀
+const headerPrefix = "x-route-"
+
+func hasRouteHeader(ctx context.Context) bool {
+	md, added, ok := metadata.FromOutgoingContextRaw(ctx)
+	if !ok {
+		return false
+	}
+	for k := range md {
+		if isRouteHeader(k) {
+			return true
+		}
+	}
+	for _, kv := range added {
+		for i := 0; i < len(kv); i += 2 {
+			if isRouteHeader(kv[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isRouteHeader(key string) bool {
+	return len(key) >= len(headerPrefix) && strings.EqualFold(key[:len(headerPrefix)], headerPrefix)
+}
+
+func first(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func parseLabels(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
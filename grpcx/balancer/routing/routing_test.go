@@ -0,0 +1,214 @@
+package routing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+func newAddr(addr, version string, md map[string]string) resolver.Address {
+	ins := &registry.ServiceInstance{
+		ID:       addr,
+		Name:     "test",
+		Version:  version,
+		Metadata: md,
+	}
+	return resolver.Address{
+		Addr:       addr,
+		Attributes: attributes.New(InstanceAttributeKey, ins),
+	}
+}
+
+func self(a resolver.Address) resolver.Address { return a }
+
+func addrs(conns []resolver.Address) []string {
+	res := make([]string, 0, len(conns))
+	for _, c := range conns {
+		res = append(res, c.Addr)
+	}
+	return res
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	hints := Hints{Zone: "z1", Version: "v2"}
+	got, ok := FromContext(NewContext(context.Background(), hints))
+	assert.True(t, ok)
+	assert.Equal(t, hints, got)
+
+	ctx := AppendToOutgoingContext(context.Background(), Hints{
+		Version: "canary",
+		Labels:  map[string]string{"tenant": "t1", "env": "gray"},
+		Key:     "t1",
+	})
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"env=gray,tenant=t1"}, md.Get(HeaderLabels))
+
+	got, ok = FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Hints{
+		Version: "canary",
+		Labels:  map[string]string{"tenant": "t1", "env": "gray"},
+		Key:     "t1",
+	}, got)
+}
+
+func TestSelect(t *testing.T) {
+	conns := []resolver.Address{
+		newAddr("a", "v1", map[string]string{"zone": "z1"}),
+		newAddr("b", "v1", map[string]string{"zone": "z2"}),
+		newAddr("c", "v2", map[string]string{"zone": "z2", "tenant": "t1"}),
+	}
+
+	tests := []struct {
+		name  string
+		hints *Hints
+		want  []string
+		err   error
+	}{
+		{
+			name: "no hints",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:  "zone",
+			hints: &Hints{Zone: "z2"},
+			want:  []string{"b", "c"},
+		},
+		{
+			name:  "zone not found",
+			hints: &Hints{Zone: "z3"},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "version",
+			hints: &Hints{Version: "v1"},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "version and zone",
+			hints: &Hints{Version: "v1", Zone: "z2"},
+			want:  []string{"b"},
+		},
+		{
+			name:  "labels",
+			hints: &Hints{Labels: map[string]string{"tenant": "t1"}},
+			want:  []string{"c"},
+		},
+		{
+			name:  "not matched",
+			hints: &Hints{Version: "v3"},
+			err:   ErrNoMatchedInstance,
+		},
+		{
+			name:  "fallback",
+			hints: &Hints{Version: "v3", Fallback: true},
+			want:  []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.hints != nil {
+				ctx = NewContext(ctx, *tt.hints)
+			}
+			got, err := Select(ctx, conns, self)
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, tt.want, addrs(got))
+			}
+		})
+	}
+}
+
+func TestSelect_LocalZone(t *testing.T) {
+	SetLocalZone("z1")
+	defer SetLocalZone("")
+
+	conns := []resolver.Address{
+		newAddr("a", "v1", map[string]string{"zone": "z1"}),
+		// 没有 ServiceInstance 时从 Attributes 读取
+		{Addr: "b", Attributes: attributes.New(MetadataZone, "z2")},
+	}
+	got, err := Select(context.Background(), conns, self)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, addrs(got))
+
+	got, err = Select(NewContext(context.Background(), Hints{Zone: "z2"}), conns, self)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, addrs(got))
+}
+
+func TestSelect_Split(t *testing.T) {
+	conns := []resolver.Address{
+		newAddr("a", "stable", map[string]string{"traffic_weight": "90"}),
+		newAddr("b", "stable", map[string]string{"traffic_weight": "90"}),
+		newAddr("c", "canary", map[string]string{"traffic_weight": "10"}),
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		got, err := Select(context.Background(), conns, self)
+		assert.NoError(t, err)
+		counts[got[0].Addr]++
+	}
+	assert.InDelta(t, 9000, counts["a"], 300)
+	assert.InDelta(t, 1000, counts["c"], 300)
+	assert.Zero(t, counts["b"])
+
+	// 相同的 key 总是落到同一个版本
+	ctx := NewContext(context.Background(), Hints{Key: "tenant-1"})
+	first, _ := Select(ctx, conns, self)
+	for i := 0; i < 100; i++ {
+		got, _ := Select(ctx, conns, self)
+		assert.Equal(t, addrs(first), addrs(got))
+	}
+
+	// 指定版本时不分流
+	got, err := Select(NewContext(context.Background(), Hints{Version: "canary"}), conns, self)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, addrs(got))
+}
+
+func TestSelector_NoAlloc(t *testing.T) {
+	SetLocalZone("z1")
+	defer SetLocalZone("")
+
+	conns := []resolver.Address{
+		newAddr("a", "stable", map[string]string{"zone": "z1", "traffic_weight": "90"}),
+		newAddr("b", "stable", map[string]string{"zone": "z2", "traffic_weight": "90"}),
+		newAddr("c", "canary", map[string]string{"zone": "z1", "traffic_weight": "10"}),
+	}
+	s := NewSelector(conns, self)
+	ctxs := map[string]context.Context{
+		"no hints":           context.Background(),
+		"unrelated metadata": metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "1"),
+		"version and zone":   NewContext(context.Background(), Hints{Version: "canary", Zone: "z1"}),
+		"key":                NewContext(context.Background(), Hints{Key: "tenant-1"}),
+	}
+	for name, ctx := range ctxs {
+		t.Run(name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				_, _ = s.Select(ctx)
+			})
+			assert.Zero(t, allocs)
+		})
+	}
+
+	got, err := s.Select(NewContext(context.Background(), Hints{Version: "stable"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, addrs(got))
+	var empty *Selector[resolver.Address]
+	got, err = empty.Select(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
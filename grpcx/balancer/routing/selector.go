@@ -0,0 +1,232 @@
+package routing
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+// ErrNoMatchedInstance 没有实例匹配路由提示里的版本和标签
+var ErrNoMatchedInstance = status.Error(codes.Unavailable, "routing: no instance matches the routing hints")
+
+// Selector 按请求的路由提示选出候选节点. 版本分组、分流权重和 zone 分组在创建时计算好,
+// 没有路由提示或者只指定了版本和 zone 时 Select 不分配内存, 适合在 picker 创建时构建、每次 Pick 时调用.
+type Selector[T any] struct {
+	addr func(T) resolver.Address
+	// all 全部节点
+	all *group[T]
+	// versions 按版本分组的节点
+	versions map[string]*group[T]
+	// weights 设置了 traffic_weight 的版本, 按版本号排序
+	weights []versionWeight[T]
+	total   int
+}
+
+// group 一组节点以及按 zone 的分组
+type group[T any] struct {
+	conns []T
+	zones map[string][]T
+}
+
+type versionWeight[T any] struct {
+	weight int
+	group  *group[T]
+}
+
+// NewSelector 创建 Selector, addr 返回节点的地址
+func NewSelector[T any](conns []T, addr func(T) resolver.Address) *Selector[T] {
+	s := &Selector[T]{
+		addr:     addr,
+		all:      newGroup(conns, addr),
+		versions: make(map[string]*group[T]),
+	}
+
+	byVersion := make(map[string][]T)
+	weights := make(map[string]int)
+	for _, c := range conns {
+		a := addr(c)
+		v := version(a)
+		byVersion[v] = append(byVersion[v], c)
+		w, ok := label(a, MetadataTrafficWeight)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil && n > weights[v] {
+			s.total += n - weights[v]
+			weights[v] = n
+		}
+	}
+	for v, vc := range byVersion {
+		s.versions[v] = newGroup(vc, addr)
+	}
+
+	versions := make([]string, 0, len(weights))
+	for v := range weights {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	for _, v := range versions {
+		s.weights = append(s.weights, versionWeight[T]{weight: weights[v], group: s.versions[v]})
+	}
+	return s
+}
+
+func newGroup[T any](conns []T, addr func(T) resolver.Address) *group[T] {
+	g := &group[T]{conns: conns}
+	for _, c := range conns {
+		z, ok := label(addr(c), MetadataZone)
+		if !ok || z == "" {
+			continue
+		}
+		if g.zones == nil {
+			g.zones = make(map[string][]T)
+		}
+		g.zones[z] = append(g.zones[z], c)
+	}
+	return g
+}
+
+// Select 按 ctx 里的路由提示选出本次请求的候选节点, 没有任何路由规则生效时返回全部节点.
+// nil Selector 没有任何节点
+func (s *Selector[T]) Select(ctx context.Context) ([]T, error) {
+	if s == nil || len(s.all.conns) == 0 {
+		return nil, nil
+	}
+
+	hints, _ := FromContext(ctx)
+	sel, g := s, s.all
+	switch {
+	case len(hints.Labels) > 0:
+		// 标签是任意的, 只能在请求时过滤
+		if matched := filter(s.all.conns, func(c T) bool {
+			return match(s.addr(c), hints)
+		}); len(matched) > 0 {
+			sel = NewSelector(matched, s.addr)
+			g = sel.all
+		} else if !hints.Fallback {
+			return nil, ErrNoMatchedInstance
+		}
+	case hints.Version != "":
+		if vg, ok := s.versions[hints.Version]; ok {
+			g = vg
+		} else if !hints.Fallback {
+			return nil, ErrNoMatchedInstance
+		}
+	}
+
+	// 显式指定了版本就不再分流
+	if hints.Version == "" {
+		g = sel.split(g, hints.Key)
+	}
+
+	zone := hints.Zone
+	if zone == "" {
+		zone = LocalZone()
+	}
+	// 同 zone 没有实例时跨 zone 访问
+	if local := g.zones[zone]; zone != "" && len(local) > 0 {
+		return local, nil
+	}
+	return g.conns, nil
+}
+
+// Select 按 ctx 里的路由提示从 conns 里选出本次请求的候选节点, addr 返回节点的地址.
+// 没有任何路由规则生效时原样返回 conns. 需要在多次请求间复用时使用 NewSelector
+func Select[T any](ctx context.Context, conns []T, addr func(T) resolver.Address) ([]T, error) {
+	return NewSelector(conns, addr).Select(ctx)
+}
+
+// split 按各版本的 traffic_weight 选出一个版本的实例,
+// 没有任何实例设置 traffic_weight 时不分流, 没有设置权重的版本不分配流量
+func (s *Selector[T]) split(g *group[T], key string) *group[T] {
+	if s.total <= 0 {
+		return g
+	}
+
+	var n int
+	if key != "" {
+		n = int(fnv32a(key) % uint32(s.total))
+	} else {
+		n = rand.Intn(s.total)
+	}
+	for _, w := range s.weights {
+		if n < w.weight {
+			return w.group
+		}
+		n -= w.weight
+	}
+	return g
+}
+
+// fnv32a 同 hash/fnv 的 32 位 FNV-1a, 不分配内存
+func fnv32a(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+	return h
+}
+
+func match(addr resolver.Address, hints Hints) bool {
+	if hints.Version != "" && version(addr) != hints.Version {
+		return false
+	}
+	for k, v := range hints.Labels {
+		if val, ok := label(addr, k); !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+func filter[T any](conns []T, keep func(T) bool) []T {
+	var res []T
+	for _, c := range conns {
+		if keep(c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func instance(addr resolver.Address) *registry.ServiceInstance {
+	if addr.Attributes == nil {
+		return nil
+	}
+	ins, _ := addr.Attributes.Value(InstanceAttributeKey).(*registry.ServiceInstance)
+	return ins
+}
+
+func version(addr resolver.Address) string {
+	if ins := instance(addr); ins != nil && ins.Version != "" {
+		return ins.Version
+	}
+	v, _ := label(addr, MetadataVersion)
+	return v
+}
+
+// label 读取实例 metadata, 优先从 ServiceInstance 读取, 其次是地址的 Attributes
+func label(addr resolver.Address, key string) (string, bool) {
+	if ins := instance(addr); ins != nil {
+		if v, ok := ins.Metadata[key]; ok {
+			return v, true
+		}
+	}
+	if addr.Attributes == nil {
+		return "", false
+	}
+	v, ok := addr.Attributes.Value(key).(string)
+	return v, ok
+}
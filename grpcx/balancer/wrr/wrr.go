@@ -8,6 +8,10 @@ import (
 	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/apus-run/sea-kit/grpcx/balancer/outlier"
+	"github.com/apus-run/sea-kit/grpcx/balancer/routing"
 )
 
 const Name = "weighted_round_robin"
@@ -17,26 +21,39 @@ func init() {
 }
 
 func newBuilder() balancer.Builder {
-	return base.NewBalancerBuilder(
+	return outlier.NewBalancerBuilder(
 		Name,
-		&WeightedPickerBuilder{},
+		func(detector *outlier.Detector) base.PickerBuilder {
+			return &WeightedPickerBuilder{detector: detector}
+		},
 		base.Config{HealthCheck: true},
 	)
 }
 
 type WeightedPicker struct {
-	mutex sync.Mutex
-	conns []*weightConn
+	mutex    sync.Mutex
+	conns    []*weightConn
+	selector *routing.Selector[*weightConn]
+	detector *outlier.Detector
 }
 
-func (w *WeightedPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
-	if len(w.conns) == 0 {
+// Pick 先按请求的路由提示 (zone、版本、标签) 过滤节点并去掉被离群检测摘除的节点,
+// 再在剩下的节点里做平滑加权轮询
+func (w *WeightedPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	conns, err := w.selector.Select(info.Ctx)
+	if err != nil {
+		return balancer.PickResult{}, err
+	}
+	conns = outlier.Filter(w.detector, conns, func(c *weightConn) string {
+		return c.addr
+	})
+	if len(conns) == 0 {
 		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
 	}
 
 	// only one connection
-	if len(w.conns) == 1 {
-		return balancer.PickResult{SubConn: w.conns[0].SubConn}, nil
+	if len(conns) == 1 {
+		return balancer.PickResult{SubConn: conns[0].SubConn, Done: w.buildDoneFunc(conns[0])}, nil
 	}
 
 	// 这里实时计算 totalWeight 是为了方便你作业动态调整权重
@@ -44,7 +61,7 @@ func (w *WeightedPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error)
 	var selected *weightConn
 
 	w.mutex.Lock()
-	for _, node := range w.conns {
+	for _, node := range conns {
 		totalWeight += node.weight
 		node.currentWeight += node.weight
 		if selected == nil || node.currentWeight > selected.currentWeight {
@@ -57,15 +74,21 @@ func (w *WeightedPicker) Pick(_ balancer.PickInfo) (balancer.PickResult, error)
 
 	return balancer.PickResult{
 		SubConn: selected.SubConn,
-		Done: func(info balancer.DoneInfo) {
-			// 在这里执行 failover 有关的事情
-			// 例如说把 selected 的 currentWeight 进一步调低到一个非常低的值
-			// 也可以直接把 selected 从 w.conns 删除
-		},
+		Done:    w.buildDoneFunc(selected),
 	}, nil
 }
 
-type WeightedPickerBuilder struct{}
+// buildDoneFunc 把调用结果交给离群检测, 连续失败或者成功率过低的节点会被暂时摘除
+func (w *WeightedPicker) buildDoneFunc(c *weightConn) func(info balancer.DoneInfo) {
+	return func(info balancer.DoneInfo) {
+		w.detector.Report(c.addr, info.Err)
+	}
+}
+
+type WeightedPickerBuilder struct {
+	// 离群检测, 同一个 ClientConn 的 picker 共享
+	detector *outlier.Detector
+}
 
 func (b *WeightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 	if len(info.ReadySCs) == 0 {
@@ -73,6 +96,7 @@ func (b *WeightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker
 	}
 
 	conns := make([]*weightConn, 0, len(info.ReadySCs))
+	addrs := make([]string, 0, len(info.ReadySCs))
 	for sc, scInfo := range info.ReadySCs {
 		// 如果不存在，那么权重就是 0
 		weight := 0
@@ -91,10 +115,16 @@ func (b *WeightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker
 			SubConn:     sc,
 			SubConnInfo: scInfo,
 		})
+		addrs = append(addrs, scInfo.Address.Addr)
 	}
+	b.detector.Update(addrs)
 
 	return &WeightedPicker{
 		conns: conns,
+		selector: routing.NewSelector(conns, func(c *weightConn) resolver.Address {
+			return c.SubConnInfo.Address
+		}),
+		detector: b.detector,
 	}
 }
 
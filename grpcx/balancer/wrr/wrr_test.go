@@ -5,8 +5,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+
+	"github.com/apus-run/sea-kit/grpcx/balancer/outlier"
 )
 
 func TestWrrPicker_PickNil(t *testing.T) {
@@ -27,3 +33,42 @@ func TestPickerWithEmptyConns(t *testing.T) {
 	})
 	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
 }
+
+func TestWrrPicker_PickWithOutlier(t *testing.T) {
+	cfg := outlier.DefaultConfig()
+	cfg.ConsecutiveErrors = 1
+	cfg.MaxEjectionPercent = 50
+	builder := &WeightedPickerBuilder{detector: outlier.NewDetector(cfg)}
+
+	ready := make(map[balancer.SubConn]base.SubConnInfo)
+	for _, addr := range []string{"a", "b"} {
+		ready[&mockSubConn{addr: addr}] = base.SubConnInfo{
+			Address: resolver.Address{
+				Addr:       addr,
+				Attributes: attributes.New("weight", 10),
+			},
+		}
+	}
+	picker := builder.Build(base.PickerBuildInfo{ReadySCs: ready})
+	info := balancer.PickInfo{
+		FullMethodName: "/",
+		Ctx:            context.Background(),
+	}
+
+	res, err := picker.Pick(info)
+	assert.NoError(t, err)
+	ejected := res.SubConn.(*mockSubConn).addr
+	res.Done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "unavailable")})
+
+	for i := 0; i < 10; i++ {
+		res, err = picker.Pick(info)
+		assert.NoError(t, err)
+		assert.NotEqual(t, ejected, res.SubConn.(*mockSubConn).addr)
+		res.Done(balancer.DoneInfo{})
+	}
+}
+
+type mockSubConn struct {
+	balancer.SubConn
+	addr string
+}
@@ -10,11 +10,11 @@ import (
 )
 
 func NewClient(ctx context.Context, opts ...Option) (*grpc.ClientConn, error) {
-	var grpcServiceConfig = fmt.Sprintf(`{"loadBalancingConfig": [{"%s":{}}],"healthCheckConfig":{"serviceName":""}}`, "round_robin")
 	var uints []grpc.UnaryClientInterceptor
 	var sints []grpc.StreamClientInterceptor
 
 	options := Apply(opts...)
+	var grpcServiceConfig = fmt.Sprintf(`{"loadBalancingConfig": [{"%s":{}}],"healthCheckConfig":{"serviceName":""}}`, options.balancerName)
 
 	if len(options.unaryInts) > 0 {
 		uints = append(uints, options.unaryInts...)
@@ -31,3 +31,14 @@ func TestNewClient(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestWithBalancer(t *testing.T) {
+	o := Apply()
+	if o.balancerName != "round_robin" {
+		t.Errorf("expect round_robin but got %s", o.balancerName)
+	}
+	WithBalancer("p2c_ewma")(o)
+	if o.balancerName != "p2c_ewma" {
+		t.Errorf("expect p2c_ewma but got %s", o.balancerName)
+	}
+}
@@ -23,6 +23,9 @@ type Options struct {
 
 	dialOpts []grpc.DialOption
 
+	// 负载均衡器, 默认 round_robin
+	balancerName string
+
 	// other options for implementations of the interface
 	// can be stored in a context
 	ctx context.Context
@@ -31,8 +34,9 @@ type Options struct {
 // defaultOptions .
 func defaultOptions() *Options {
 	return &Options{
-		ctx:    context.Background(),
-		secure: false,
+		ctx:          context.Background(),
+		secure:       false,
+		balancerName: "round_robin",
 	}
 }
 
@@ -58,6 +62,14 @@ func WithAddr(addr string) Option {
 	}
 }
 
+// WithBalancer 设置负载均衡器, 例如 p2c.Name 或 wrr.Name,
+// service config 里开启了 gRPC health 检测, 服务端需要 server.WithIsHealth
+func WithBalancer(name string) Option {
+	return func(o *Options) {
+		o.balancerName = name
+	}
+}
+
 // WithTLSConfig with TLS config.
 func WithTLSConfig(conf *tls.Config) Option {
 	return func(o *Options) {
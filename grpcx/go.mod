@@ -5,8 +5,10 @@ go 1.21
 require (
 	github.com/apus-run/sea-kit/algo v0.0.0-00010101000000-000000000000
 	github.com/apus-run/sea-kit/collection v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/idempotent v0.0.0-00010101000000-000000000000
 	github.com/apus-run/sea-kit/ratelimit v0.0.0-00010101000000-000000000000
 	github.com/apus-run/sea-kit/redisx v0.0.0-20240129095155-f3b44ab2b264
+	github.com/apus-run/sea-kit/retry v0.0.0-00010101000000-000000000000
 	github.com/apus-run/sea-kit/utils v0.0.0-20240128090029-73c1b57ba004
 	github.com/apus-run/sea-kit/zlog v0.0.0-00010101000000-000000000000
 	github.com/grpc-ecosystem/go-grpc-middleware/v2 v2.0.1
@@ -25,6 +27,7 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20231030173426-d783a09b4405
 	google.golang.org/grpc v1.59.0
 	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -99,13 +102,14 @@ require (
 	google.golang.org/genproto v0.0.0-20231030173426-d783a09b4405 // indirect
 	gopkg.in/ini.v1 v1.42.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace (
 	github.com/apus-run/sea-kit/algo => ../algo
 	github.com/apus-run/sea-kit/collection => ../collection
+	github.com/apus-run/sea-kit/idempotent => ../idempotent
 	github.com/apus-run/sea-kit/ratelimit => ../ratelimit
+	github.com/apus-run/sea-kit/retry => ../retry
 	github.com/apus-run/sea-kit/timex => ../timex
 	github.com/apus-run/sea-kit/zlog => ../zlog
 )
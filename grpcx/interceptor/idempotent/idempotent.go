@@ -0,0 +1,126 @@
+package idempotent
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/apus-run/sea-kit/idempotent"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+// MetadataKey 默认的幂等 key metadata
+const MetadataKey = "idempotency-key"
+
+// InterceptorBuilder 基于 idempotency-key metadata 的幂等拦截器.
+// 第一次请求的响应会被保存, 相同 key 的重试请求直接重放该响应,
+// 第一次请求尚未处理完时, 并发的重复请求返回 codes.Aborted.
+type InterceptorBuilder struct {
+	idem *idempotent.Idempotent
+	key  string
+	log  zlog.Logger
+}
+
+func NewIdempotentInterceptorBuilder(idem *idempotent.Idempotent, log zlog.Logger) *InterceptorBuilder {
+	return &InterceptorBuilder{
+		idem: idem,
+		key:  MetadataKey,
+		log:  log,
+	}
+}
+
+// MetadataKey 设置幂等 key 的 metadata
+func (b *InterceptorBuilder) MetadataKey(key string) *InterceptorBuilder {
+	b.key = key
+	return b
+}
+
+func (b *InterceptorBuilder) BuildUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp any, err error) {
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(b.key); len(values) > 0 {
+				key = values[0]
+			}
+		}
+		if key == "" {
+			return handler(ctx, req)
+		}
+		key = info.FullMethod + ":" + key
+
+		rec, acquired, err := b.idem.Begin(ctx, key)
+		if err != nil {
+			b.log.Error("幂等检查失败", zlog.Error(err))
+			// 严格模式下存储不可用时拒绝请求, 否则放行
+			if b.idem.Strict() {
+				return nil, status.Error(codes.Unavailable, "幂等检查失败")
+			}
+			return handler(ctx, req)
+		}
+		if !acquired {
+			return b.replay(rec)
+		}
+
+		// 处理失败, panic 或者保存失败时释放 key, 允许客户端重试
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			if er := b.idem.Abort(ctx, key); er != nil {
+				b.log.Error("释放幂等 key 失败", zlog.Error(er))
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		if er := b.save(ctx, key, resp); er != nil {
+			b.log.Error("保存幂等响应失败", zlog.Error(er))
+			return resp, nil
+		}
+		finished = true
+		return resp, nil
+	}
+}
+
+func (b *InterceptorBuilder) save(ctx context.Context, key string, resp any) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "响应 %T 不是 proto.Message", resp)
+	}
+	a, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return b.idem.Finish(ctx, key, &idempotent.Record{Body: data})
+}
+
+func (b *InterceptorBuilder) replay(rec *idempotent.Record) (any, error) {
+	if rec.Status != idempotent.StatusDone {
+		return nil, status.Error(codes.Aborted, "请求正在处理中")
+	}
+
+	var a anypb.Any
+	if err := proto.Unmarshal(rec.Body, &a); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	msg, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return msg, nil
+}
@@ -0,0 +1,114 @@
+package idempotent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/apus-run/sea-kit/idempotent"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+func TestBuildUnaryServerInterceptor(t *testing.T) {
+	idem := idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))
+	interceptor := NewIdempotentInterceptorBuilder(idem, zlog.L()).BuildUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/order.OrderService/Create",
+	}
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return wrapperspb.Int64(int64(calls)), nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "abc"))
+	for i := 0; i < 2; i++ {
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(wrapperspb.Int64(1), resp.(proto.Message)))
+	}
+	assert.Equal(t, 1, calls)
+
+	// 没有 key 不做幂等
+	_, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBuildUnaryServerInterceptorError(t *testing.T) {
+	idem := idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))
+	interceptor := NewIdempotentInterceptorBuilder(idem, zlog.L()).BuildUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/order.OrderService/Create",
+	}
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return nil, errors.New("db error")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "abc"))
+	for i := 0; i < 2; i++ {
+		_, err := interceptor(ctx, nil, info, handler)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestBuildUnaryServerInterceptorInProgress(t *testing.T) {
+	idem := idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))
+	interceptor := NewIdempotentInterceptorBuilder(idem, zlog.L()).BuildUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/order.OrderService/Create",
+	}
+
+	_, acquired, err := idem.Begin(context.Background(), info.FullMethod+":abc")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "abc"))
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return wrapperspb.Int64(1), nil
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+func TestBuildUnaryServerInterceptorAbort(t *testing.T) {
+	idem := idempotent.New(idempotent.WithStore(idempotent.NewMemoryStore()))
+	interceptor := NewIdempotentInterceptorBuilder(idem, zlog.L()).BuildUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/order.OrderService/Create",
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "abc"))
+
+	// panic 之后释放 key
+	assert.Panics(t, func() {
+		_, _ = interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			panic("mock panic")
+		})
+	})
+
+	// 保存失败之后释放 key
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return "not proto", nil
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "not proto", resp)
+	}
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,111 @@
+package adaptive
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ratelimit "github.com/apus-run/sea-kit/ratelimit/adaptive"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+// Kind is the type of Interceptor
+const Kind string = "Adaptive"
+
+// InterceptorBuilder 客户端自适应并发限流, 保护下游不被打垮
+// 超过并发上限的请求直接返回 codes.ResourceExhausted, 不会发送到下游
+type InterceptorBuilder struct {
+	log     zlog.Logger
+	limiter *ratelimit.Limiter
+
+	// rpc code for overload, default already includes
+	// codes.ResourceExhausted, codes.Unavailable and codes.DeadlineExceeded
+	overloadCodes map[codes.Code]struct{}
+}
+
+// NewRatelimitInterceptorBuilder 一个下游服务 (一个 grpc.ClientConn) 使用一个 limiter
+func NewRatelimitInterceptorBuilder(l *ratelimit.Limiter, log zlog.Logger) *InterceptorBuilder {
+	return &InterceptorBuilder{
+		log:     log,
+		limiter: l,
+		overloadCodes: map[codes.Code]struct{}{
+			codes.ResourceExhausted: {},
+			codes.Unavailable:       {},
+			codes.DeadlineExceeded:  {},
+		},
+	}
+}
+
+// Kind return the name of interceptor
+func (b *InterceptorBuilder) Kind() string {
+	return Kind
+}
+
+// OverloadCode 添加视为下游过载的 rpc code
+func (b *InterceptorBuilder) OverloadCode(codes ...codes.Code) *InterceptorBuilder {
+	for _, c := range codes {
+		b.overloadCodes[c] = struct{}{}
+	}
+	return b
+}
+
+func (b *InterceptorBuilder) BuildUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption) error {
+		done, err := b.allow(method)
+		if err != nil {
+			return err
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(b.doneInfo(err))
+		return err
+	}
+}
+
+// BuildStreamClientInterceptor 流式调用只限制建立流的并发, 流建立之后立即释放配额
+func (b *InterceptorBuilder) BuildStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		done, err := b.allow(method)
+		if err != nil {
+			return nil, err
+		}
+
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		done(b.doneInfo(err))
+		return clientStream, err
+	}
+}
+
+func (b *InterceptorBuilder) allow(method string) (ratelimit.DoneFunc, error) {
+	done, err := b.limiter.Allow()
+	if err != nil {
+		b.log.Warn("触发自适应限流", zlog.String("method", method))
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return done, nil
+}
+
+// doneInfo 调用方主动取消的请求不代表下游的响应时间, 不参与采样
+func (b *InterceptorBuilder) doneInfo(err error) ratelimit.DoneInfo {
+	code := status.Code(err)
+	_, overload := b.overloadCodes[code]
+	return ratelimit.DoneInfo{
+		Err:      err,
+		Overload: err != nil && overload,
+		Canceled: code == codes.Canceled,
+	}
+}
@@ -0,0 +1,87 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ratelimit "github.com/apus-run/sea-kit/ratelimit/adaptive"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+func TestBuildUnaryClientInterceptor(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(1))
+	require.NoError(t, err)
+	builder := NewRatelimitInterceptorBuilder(limiter, zlog.L())
+	interceptor := builder.BuildUnaryClientInterceptor()
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = interceptor(context.Background(), "/test", nil, nil, nil,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				close(blocked)
+				<-release
+				return nil
+			})
+	}()
+	<-blocked
+
+	// 并发已满, 直接拒绝
+	err = interceptor(context.Background(), "/test", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			t.Fatal("unexpected invoke")
+			return nil
+		})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, int64(1), limiter.Stat().Rejected)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return limiter.Stat().InFlight == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestBuildUnaryClientInterceptorOverload(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(10), ratelimit.WithBackoff(0.5))
+	require.NoError(t, err)
+	builder := NewRatelimitInterceptorBuilder(limiter, zlog.L())
+	interceptor := builder.BuildUnaryClientInterceptor()
+
+	err = interceptor(context.Background(), "/test", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.NotFound, "not found")
+		})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, int64(10), limiter.Stat().Limit)
+
+	err = interceptor(context.Background(), "/test", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int64(5), limiter.Stat().Limit)
+}
+
+func TestBuildUnaryClientInterceptorCanceled(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.WithInitialLimit(10), ratelimit.WithBackoff(0.5))
+	require.NoError(t, err)
+	builder := NewRatelimitInterceptorBuilder(limiter, zlog.L())
+	interceptor := builder.BuildUnaryClientInterceptor()
+
+	// 调用方取消不视为下游过载
+	err = interceptor(context.Background(), "/test", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Canceled, "canceled")
+		})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	stat := limiter.Stat()
+	assert.Equal(t, int64(10), stat.Limit)
+	assert.Equal(t, int64(0), stat.InFlight)
+}
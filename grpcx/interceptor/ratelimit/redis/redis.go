@@ -4,10 +4,11 @@ import (
 	"context"
 	"strings"
 
-	ratelimit "github.com/apus-run/sea-kit/ratelimit/redis"
+	"github.com/apus-run/sea-kit/ratelimit"
 	"github.com/apus-run/sea-kit/zlog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -21,7 +22,7 @@ type InterceptorBuilder struct {
 	log zlog.Logger
 }
 
-// NewRatelimitInterceptorBuilder key: user-service
+// NewRatelimitInterceptorBuilder l 可以是 ratelimit 下的任意实现, key: user-service
 // "limiter:service:user" 整个应用、集群限流
 // "limiter:service:user:UserService" user 里面的 UserService 限流
 func NewRatelimitInterceptorBuilder(l ratelimit.Limiter, key string, log zlog.Logger) *InterceptorBuilder {
@@ -41,16 +42,11 @@ func (b *InterceptorBuilder) BuildUnaryServerInterceptor() grpc.UnaryServerInter
 	return func(ctx context.Context, req any,
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (resp any, err error) {
-		limited, err := b.limiter.Limit(ctx, b.key)
+		ctx, err = b.limit(ctx)
 		if err != nil {
-			b.log.Error("触发限流", zlog.Error(err))
 			// 这里采用保守措施，在触发限流之后直接返回
 			return nil, status.Errorf(codes.ResourceExhausted, "限流")
 		}
-		if limited {
-			b.log.Error("触发限流", zlog.Error(err))
-			ctx = context.WithValue(ctx, "limited", "true")
-		}
 		return handler(ctx, req)
 	}
 }
@@ -60,16 +56,11 @@ func (b *InterceptorBuilder) BuildUnaryServerInterceptorService(prefix string) g
 	return func(ctx context.Context, req any,
 		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		if strings.HasPrefix(info.FullMethod, prefix) {
-			limited, err := b.limiter.Limit(ctx, b.key)
+			ctx, err = b.limit(ctx)
 			if err != nil {
-				b.log.Error("触发限流", zlog.Error(err))
 				// 这里采用保守措施，在触发限流之后直接返回
 				return nil, status.Errorf(codes.ResourceExhausted, "限流")
 			}
-			if limited {
-				b.log.Error("触发限流", zlog.Error(err))
-				ctx = context.WithValue(ctx, "limited", "true")
-			}
 		}
 		return handler(ctx, req)
 	}
@@ -84,13 +75,13 @@ func (b *InterceptorBuilder) BuildUnaryClientInterceptor() grpc.UnaryClientInter
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption) error {
 
-		limited, err := b.limiter.Limit(ctx, b.key)
+		res, err := b.limiter.Allow(ctx, b.key)
 		if err != nil {
 			b.log.Error("触发限流", zlog.Error(err))
 			// 这里采用保守措施，在触发限流之后直接返回
 			return status.Errorf(codes.ResourceExhausted, "触发限流")
 		}
-		if limited {
+		if !res.Allowed {
 			b.log.Error("触发限流", zlog.Error(err))
 			ctx = context.WithValue(ctx, "limited", "true")
 		}
@@ -98,3 +89,19 @@ func (b *InterceptorBuilder) BuildUnaryClientInterceptor() grpc.UnaryClientInter
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
+
+// limit 服务端限流, 并通过 header metadata 返回 RateLimit-* 和 Retry-After
+func (b *InterceptorBuilder) limit(ctx context.Context) (context.Context, error) {
+	res, err := b.limiter.Allow(ctx, b.key)
+	if err != nil {
+		b.log.Error("触发限流", zlog.Error(err))
+		return ctx, err
+	}
+	// 非 grpc 服务端调用时没有 stream, 忽略错误
+	_ = grpc.SetHeader(ctx, metadata.New(res.Headers()))
+	if !res.Allowed {
+		b.log.Error("触发限流", zlog.Error(err))
+		ctx = context.WithValue(ctx, "limited", "true")
+	}
+	return ctx, nil
+}
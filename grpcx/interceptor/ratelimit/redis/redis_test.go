@@ -7,18 +7,22 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
-	ratelimit "github.com/apus-run/sea-kit/ratelimit/redis"
+	"github.com/apus-run/sea-kit/ratelimit"
+	"github.com/apus-run/sea-kit/ratelimit/local"
+	ratelimit_redis "github.com/apus-run/sea-kit/ratelimit/redis"
 	"github.com/apus-run/sea-kit/zlog"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildUnaryServerInterceptor(t *testing.T) {
 	ctx := context.Background()
 	req := "test request"
-	r := ratelimit.NewRedisSlidingWindowLimiter(
+	r := ratelimit_redis.NewRedisSlidingWindowLimiter(
 		initRedis(),
 		500*time.Millisecond,
 		5,
@@ -45,7 +49,7 @@ func TestBuildUnaryServerInterceptor(t *testing.T) {
 func TestBuildUnaryServerInterceptorService(t *testing.T) {
 	ctx := context.Background()
 	req := "test request"
-	r := ratelimit.NewRedisSlidingWindowLimiter(
+	r := ratelimit_redis.NewRedisSlidingWindowLimiter(
 		initRedis(),
 		500*time.Millisecond,
 		1,
@@ -67,6 +71,46 @@ func TestBuildUnaryServerInterceptorService(t *testing.T) {
 	assert.Equal(t, nil, resp)
 }
 
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestBuildUnaryServerInterceptorHeaders(t *testing.T) {
+	limiter, err := local.NewGCRALimiter(time.Second, 1, 1)
+	require.NoError(t, err)
+	builder := NewRatelimitInterceptorBuilder(limiter, "foo", zlog.L())
+	interceptor := builder.BuildUnaryServerInterceptor()
+
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/test",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return ctx.Value("limited"), nil
+	}
+
+	stream := &headerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	resp, err := interceptor(ctx, "test request", info, handler)
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, []string{"1"}, stream.header.Get(ratelimit.HeaderLimit))
+	assert.Equal(t, []string{"0"}, stream.header.Get(ratelimit.HeaderRemaining))
+	assert.Empty(t, stream.header.Get(ratelimit.HeaderRetryAfter))
+
+	stream = &headerStream{}
+	ctx = grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	resp, err = interceptor(ctx, "test request", info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", resp)
+	assert.Equal(t, []string{"1"}, stream.header.Get(ratelimit.HeaderRetryAfter))
+}
+
 func initRedis() redis.Cmdable {
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     "localhost:16379",
@@ -0,0 +1,120 @@
+package retry
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// budget 重试预算, 令牌数不超过上限的一半时停止重试
+// nil 表示不限制
+type budget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+func newBudget(maxTokens float64, ratio float64) *budget {
+	return &budget{
+		tokens:    maxTokens,
+		maxTokens: maxTokens,
+		ratio:     ratio,
+	}
+}
+
+func (b *budget) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.maxTokens, b.tokens+b.ratio)
+}
+
+// failure 消耗一个令牌, 返回是否还允许重试
+func (b *budget) failure() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Max(0, b.tokens-1)
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *budget) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+// 每个方法保留的延迟样本数, 样本不足 minSamples 时不对冲
+const (
+	maxSamples = 128
+	minSamples = 16
+)
+
+// hedging 记录每个方法最近的成功调用延迟, 用于计算对冲延迟
+type hedging struct {
+	percentile  float64
+	maxAttempts int
+
+	methods sync.Map // method -> *latency
+}
+
+type latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newHedging(percentile float64, maxAttempts int) *hedging {
+	return &hedging{
+		percentile:  percentile,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (h *hedging) observe(method string, d time.Duration) {
+	if h == nil {
+		return
+	}
+	v, _ := h.methods.LoadOrStore(method, &latency{})
+	l := v.(*latency)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.samples) < maxSamples {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % maxSamples
+}
+
+// delay 返回方法历史延迟的 percentile 分位
+func (h *hedging) delay(method string) (time.Duration, bool) {
+	v, ok := h.methods.Load(method)
+	if !ok {
+		return 0, false
+	}
+	l := v.(*latency)
+	l.mu.Lock()
+	samples := make([]time.Duration, len(l.samples))
+	copy(samples, l.samples)
+	l.mu.Unlock()
+
+	if len(samples) < minSamples {
+		return 0, false
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	idx := int(math.Ceil(h.percentile*float64(len(samples)))) - 1
+	idx = max(0, min(idx, len(samples)-1))
+	return samples[idx], true
+}
@@ -0,0 +1,297 @@
+package retry
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/apus-run/sea-kit/grpcx/interceptor/idempotent"
+	"github.com/apus-run/sea-kit/retry"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+// Kind is the type of Interceptor
+const Kind string = "Retry"
+
+// InterceptorBuilder 客户端重试拦截器
+//
+// 只有幂等的调用才会重试或者对冲, 以下任意一种方式都可以标记调用是幂等的:
+//   - proto 里 method 的 idempotency_level 选项为 NO_SIDE_EFFECTS 或 IDEMPOTENT
+//   - 调用时传入 WithIdempotent() CallOption
+//   - outgoing metadata 里带有幂等 key (idempotency-key)
+//
+// 重试预算参考 gRPC 的 retryThrottling: 每次成功增加 ratio 个令牌,
+// 每次可重试的失败消耗 1 个令牌, 令牌数不超过一半时停止重试和对冲.
+type InterceptorBuilder struct {
+	newStrategy func() retry.Strategy
+	log         zlog.Logger
+
+	// rpc code for retry, default is codes.Unavailable
+	retryCodes map[codes.Code]struct{}
+	// 幂等 key 的 metadata
+	idempotencyKey string
+
+	budget *budget
+	hedge  *hedging
+}
+
+// NewRetryInterceptorBuilder newStrategy 为每次调用创建一个新的重试策略
+func NewRetryInterceptorBuilder(newStrategy func() retry.Strategy, log zlog.Logger) *InterceptorBuilder {
+	return &InterceptorBuilder{
+		newStrategy: newStrategy,
+		log:         log,
+		retryCodes: map[codes.Code]struct{}{
+			codes.Unavailable: {},
+		},
+		idempotencyKey: idempotent.MetadataKey,
+	}
+}
+
+// Kind return the name of interceptor
+func (b *InterceptorBuilder) Kind() string {
+	return Kind
+}
+
+// RetryCodes 添加需要重试的 rpc code
+func (b *InterceptorBuilder) RetryCodes(codes ...codes.Code) *InterceptorBuilder {
+	for _, c := range codes {
+		b.retryCodes[c] = struct{}{}
+	}
+	return b
+}
+
+// IdempotencyKey 设置幂等 key 的 metadata
+func (b *InterceptorBuilder) IdempotencyKey(key string) *InterceptorBuilder {
+	b.idempotencyKey = key
+	return b
+}
+
+// Budget 开启重试预算, maxTokens 为令牌上限, ratio 为每次成功增加的令牌数
+// 例如 Budget(10, 0.1) 表示持续失败时最多重试 5 次, 之后每 10 次成功才允许 1 次重试
+func (b *InterceptorBuilder) Budget(maxTokens int, ratio float64) *InterceptorBuilder {
+	b.budget = newBudget(float64(maxTokens), ratio)
+	return b
+}
+
+// Hedging 开启对冲请求: 调用耗时超过该方法历史延迟的 percentile 分位 (0-1) 仍未返回时,
+// 再并发发起一次请求, 最多 maxAttempts 个请求, 采用第一个成功 (或不可重试) 的结果.
+// 对冲请求不使用重试策略, 可重试的失败会立即发起下一次请求.
+// 只有 reply 是 proto.Message 时才会对冲.
+func (b *InterceptorBuilder) Hedging(percentile float64, maxAttempts int) *InterceptorBuilder {
+	b.hedge = newHedging(percentile, maxAttempts)
+	return b
+}
+
+func (b *InterceptorBuilder) BuildUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption) error {
+		if !b.idempotent(ctx, method, opts) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if b.hedge != nil {
+			if msg, ok := reply.(proto.Message); ok {
+				if delay, ok := b.hedge.delay(method); ok {
+					return b.hedged(ctx, method, req, msg, cc, invoker, delay, opts...)
+				}
+			}
+		}
+		return b.retry(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+func (b *InterceptorBuilder) retry(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+	strategy := b.newStrategy()
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		recordAttempt(ctx, attempt, err)
+		if err == nil {
+			b.hedge.observe(method, time.Since(start))
+			b.budget.success()
+			return nil
+		}
+		if !b.retryable(err) || !b.budget.failure() {
+			return err
+		}
+
+		interval, ok := strategy.Next()
+		if !ok {
+			return err
+		}
+		// 等不到下一次重试就超时了, 直接返回
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= interval {
+			return err
+		}
+		b.log.Warn("grpc 调用失败, 准备重试",
+			zlog.String("method", method), zlog.Int("attempt", attempt), zlog.Error(err))
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+type result struct {
+	attempt int
+	reply   proto.Message
+	err     error
+	elapsed time.Duration
+}
+
+func (b *InterceptorBuilder) hedged(
+	ctx context.Context,
+	method string,
+	req any,
+	reply proto.Message,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	delay time.Duration,
+	opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	// 返回之后取消其他还在进行中的请求
+	defer cancel()
+
+	results := make(chan result, b.hedge.maxAttempts)
+	launched := 0
+	launch := func() {
+		launched++
+		attempt := launched
+		r := proto.Clone(reply)
+		go func() {
+			start := time.Now()
+			err := invoker(ctx, method, req, r, cc, opts...)
+			results <- result{attempt: attempt, reply: r, err: err, elapsed: time.Since(start)}
+		}()
+	}
+	canLaunch := func() bool {
+		return launched < b.hedge.maxAttempts && ctx.Err() == nil && b.budget.allow()
+	}
+
+	launch()
+	inFlight := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var lastErr error
+	for inFlight > 0 {
+		select {
+		case <-timer.C:
+			if canLaunch() {
+				launch()
+				inFlight++
+				timer.Reset(delay)
+			}
+		case res := <-results:
+			inFlight--
+			recordAttempt(ctx, res.attempt, res.err)
+			if res.err == nil {
+				b.hedge.observe(method, res.elapsed)
+				b.budget.success()
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply)
+				return nil
+			}
+			if !b.retryable(res.err) {
+				return res.err
+			}
+			lastErr = res.err
+			if b.budget.failure() && canLaunch() {
+				launch()
+				inFlight++
+				timer.Reset(delay)
+			}
+		}
+	}
+	return lastErr
+}
+
+func (b *InterceptorBuilder) retryable(err error) bool {
+	_, ok := b.retryCodes[status.Code(err)]
+	return ok
+}
+
+// idempotent 判断调用是否幂等, 依次检查 CallOption, metadata 和 proto 的 method 选项
+func (b *InterceptorBuilder) idempotent(ctx context.Context, method string, opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(IdempotentCallOption); ok {
+			return true
+		}
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(b.idempotencyKey)) > 0 {
+		return true
+	}
+	return idempotentMethod(method)
+}
+
+var idempotentMethods sync.Map
+
+// idempotentMethod 从注册的 proto 描述里读取 method 的 idempotency_level 选项
+func idempotentMethod(method string) bool {
+	if v, ok := idempotentMethods.Load(method); ok {
+		return v.(bool)
+	}
+
+	// /package.Service/Method -> package.Service.Method
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	res := false
+	if desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+		if md, ok := desc.(protoreflect.MethodDescriptor); ok {
+			if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok {
+				res = opts.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+			}
+		}
+	}
+	idempotentMethods.Store(method, res)
+	return res
+}
+
+// recordAttempt 在 trace span 上记录每次尝试
+func recordAttempt(ctx context.Context, attempt int, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("grpc.attempt", trace.WithAttributes(
+		attribute.Int("rpc.grpc.attempt", attempt),
+		attribute.String("rpc.grpc.status_code", status.Code(err).String()),
+	))
+	span.SetAttributes(attribute.Int("rpc.grpc.attempts", attempt))
+}
+
+// IdempotentCallOption is a call option that marks the call idempotent.
+type IdempotentCallOption struct {
+	grpc.EmptyCallOption
+}
+
+// WithIdempotent returns a call option that allows the call to be retried or hedged.
+func WithIdempotent() grpc.CallOption {
+	return IdempotentCallOption{}
+}
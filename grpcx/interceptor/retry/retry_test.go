@@ -0,0 +1,177 @@
+package retry
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/apus-run/sea-kit/retry"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+func newStrategy() retry.Strategy {
+	s, err := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 3)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// failingInvoker 前 failures 次调用返回 code, 之后成功
+func failingInvoker(calls *int32, failures int32, code codes.Code) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return status.Error(code, "mock error")
+		}
+		return nil
+	}
+}
+
+func TestBuildUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		opts     []grpc.CallOption
+		failures int32
+		code     codes.Code
+
+		wantCalls int32
+		wantCode  codes.Code
+	}{
+		{
+			name:      "非幂等调用不重试",
+			ctx:       context.Background(),
+			failures:  1,
+			code:      codes.Unavailable,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name:      "幂等调用重试成功",
+			ctx:       context.Background(),
+			opts:      []grpc.CallOption{WithIdempotent()},
+			failures:  2,
+			code:      codes.Unavailable,
+			wantCalls: 3,
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "metadata 幂等 key",
+			ctx:       metadata.AppendToOutgoingContext(context.Background(), "idempotency-key", "abc"),
+			failures:  1,
+			code:      codes.Unavailable,
+			wantCalls: 2,
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "不可重试的 code",
+			ctx:       context.Background(),
+			opts:      []grpc.CallOption{WithIdempotent()},
+			failures:  1,
+			code:      codes.InvalidArgument,
+			wantCalls: 1,
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "超过最大重试次数",
+			ctx:       context.Background(),
+			opts:      []grpc.CallOption{WithIdempotent()},
+			failures:  10,
+			code:      codes.Unavailable,
+			wantCalls: 4,
+			wantCode:  codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewRetryInterceptorBuilder(newStrategy, zlog.L()).BuildUnaryClientInterceptor()
+
+			var calls int32
+			err := interceptor(tt.ctx, "/test.Service/Method", nil, nil, nil,
+				failingInvoker(&calls, tt.failures, tt.code), tt.opts...)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestBuildUnaryClientInterceptorDeadline(t *testing.T) {
+	interceptor := NewRetryInterceptorBuilder(func() retry.Strategy {
+		s, err := retry.NewFixedIntervalRetryStrategy(time.Second, 3)
+		require.NoError(t, err)
+		return s
+	}, zlog.L()).BuildUnaryClientInterceptor()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var calls int32
+	start := time.Now()
+	err := interceptor(ctx, "/test.Service/Method", nil, nil, nil,
+		failingInvoker(&calls, 10, codes.Unavailable), WithIdempotent())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestBuildUnaryClientInterceptorBudget(t *testing.T) {
+	// 上限 4 个令牌, 持续失败时最多重试 1 次
+	interceptor := NewRetryInterceptorBuilder(newStrategy, zlog.L()).
+		Budget(4, 0.5).
+		BuildUnaryClientInterceptor()
+
+	var calls int32
+	err := interceptor(context.Background(), "/test.Service/Method", nil, nil, nil,
+		failingInvoker(&calls, 10, codes.Unavailable), WithIdempotent())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(2), calls)
+
+	// 预算耗尽之后不再重试
+	calls = 0
+	err = interceptor(context.Background(), "/test.Service/Method", nil, nil, nil,
+		failingInvoker(&calls, 10, codes.Unavailable), WithIdempotent())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestBuildUnaryClientInterceptorHedging(t *testing.T) {
+	interceptor := NewRetryInterceptorBuilder(newStrategy, zlog.L()).
+		Hedging(0.9, 2).
+		BuildUnaryClientInterceptor()
+
+	fast := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		time.Sleep(time.Millisecond)
+		reply.(*wrapperspb.StringValue).Value = "fast"
+		return nil
+	}
+	// 积累延迟样本
+	for i := 0; i < minSamples; i++ {
+		require.NoError(t, interceptor(context.Background(), "/test.Service/Method",
+			nil, &wrapperspb.StringValue{}, nil, fast, WithIdempotent()))
+	}
+
+	var calls int32
+	slowFirst := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 第一次请求卡住, 直到被取消
+			<-ctx.Done()
+			return status.Error(codes.Canceled, ctx.Err().Error())
+		}
+		reply.(*wrapperspb.StringValue).Value = "hedged"
+		return nil
+	}
+	reply := &wrapperspb.StringValue{}
+	err := interceptor(context.Background(), "/test.Service/Method", nil, reply, nil, slowFirst, WithIdempotent())
+	require.NoError(t, err)
+	assert.Equal(t, "hedged", reply.Value)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,387 @@
+// Package file 实现基于本地文件的服务注册中心, 适用于本地多进程开发.
+//
+// 所有实例保存在一个 JSON 或 YAML 文件里 (按扩展名 .json/.yaml/.yml 区分), 内容是实例列表:
+//
+//	[
+//	  {
+//	    "id": "helloworld-1",
+//	    "name": "helloworld",
+//	    "version": "v1",
+//	    "metadata": {"zone": "z1"},
+//	    "endpoints": ["grpc://127.0.0.1:9000?isSecure=false"],
+//	    "heartbeat": "2024-01-01T00:00:00+08:00"
+//	  }
+//	]
+//
+// Register 和 Deregister 会修改文件, 其他进程通过 utils/fswatcher 感知文件变化,
+// 也可以直接手动编辑文件. 同一台机器上的进程通过 .lock 文件互斥写入.
+//
+// Register 注册的实例会定期更新 heartbeat 续约, 超过 TTL 没有续约的实例 (例如进程崩溃)
+// 在读取时被忽略, 并在下次写文件时删除. 没有 heartbeat 的实例 (例如手动添加的) 不会过期.
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+	"github.com/apus-run/sea-kit/grpcx/registry/memory"
+	"github.com/apus-run/sea-kit/utils/fswatcher"
+	log "github.com/apus-run/sea-kit/zlog"
+)
+
+var (
+	_ registry.Registrar = (*Registry)(nil)
+	_ registry.Discovery = (*Registry)(nil)
+)
+
+// ErrLockTimeout 等待文件锁超时
+var ErrLockTimeout = errors.New("file registry: lock timeout")
+
+// Option is file registry option.
+type Option func(o *options)
+
+type options struct {
+	logger      log.Logger
+	lockTimeout time.Duration
+	ttl         time.Duration
+}
+
+// WithLogger with logger option.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// LockTimeout 等待文件锁的超时时间, 超过 2 倍超时时间的锁被认为是残留的锁
+func LockTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.lockTimeout = timeout }
+}
+
+// TTL 实例的租期, 默认 30s. 注册的实例每 TTL/3 续约一次, 超过 TTL 没有续约的实例被认为已下线,
+// 0 表示实例不会过期
+func TTL(ttl time.Duration) Option {
+	return func(o *options) { o.ttl = ttl }
+}
+
+// Registry is file registry.
+type Registry struct {
+	opts    *options
+	path    string
+	store   *memory.Registry
+	watcher *fswatcher.FSWatcher
+
+	// leases 本进程注册的实例, 定期续约
+	mu     sync.Mutex
+	leases map[string]struct{}
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// entry 文件里的一个实例, Heartbeat 为空时不会过期
+type entry struct {
+	registry.ServiceInstance `yaml:",inline"`
+	// Heartbeat 最近一次续约的时间
+	Heartbeat *time.Time `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty"`
+}
+
+// New creates file registry, 文件不存在时会创建一个空的实例列表
+func New(path string, opts ...Option) (*Registry, error) {
+	op := &options{
+		logger:      log.L(),
+		lockTimeout: time.Second * 5,
+		ttl:         time.Second * 30,
+	}
+	for _, o := range opts {
+		o(op)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{
+		opts:   op,
+		path:   path,
+		store:  memory.New(),
+		leases: make(map[string]struct{}),
+		closed: make(chan struct{}),
+	}
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		if err = r.update(func(items []*entry) []*entry {
+			return items
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err = r.reload(); err != nil {
+		return nil, err
+	}
+
+	r.watcher, err = fswatcher.New([]string{path}, func() {
+		if err := r.reload(); err != nil {
+			r.opts.logger.Warn("file registry: failed to reload", log.String("file", r.path), log.Error(err))
+		}
+	}, op.logger)
+	if err != nil {
+		return nil, err
+	}
+	if op.ttl > 0 {
+		r.wg.Add(1)
+		go r.keepalive()
+	}
+	return r, nil
+}
+
+// Register the registration.
+func (r *Registry) Register(_ context.Context, service *registry.ServiceInstance) error {
+	if service == nil || service.Name == "" || service.ID == "" {
+		return memory.ErrInvalidInstance
+	}
+	r.mu.Lock()
+	r.leases[leaseKey(service)] = struct{}{}
+	r.mu.Unlock()
+
+	item := &entry{ServiceInstance: *service}
+	if r.opts.ttl > 0 {
+		now := time.Now()
+		item.Heartbeat = &now
+	}
+	err := r.update(func(items []*entry) []*entry {
+		for i, it := range items {
+			if it.Name == service.Name && it.ID == service.ID {
+				items[i] = item
+				return items
+			}
+		}
+		return append(items, item)
+	})
+	if err != nil {
+		return err
+	}
+	// 不等文件变化的通知, 本进程立即可见
+	return r.reload()
+}
+
+// Deregister the registration.
+func (r *Registry) Deregister(_ context.Context, service *registry.ServiceInstance) error {
+	if service == nil || service.Name == "" || service.ID == "" {
+		return memory.ErrInvalidInstance
+	}
+	r.mu.Lock()
+	delete(r.leases, leaseKey(service))
+	r.mu.Unlock()
+
+	err := r.update(func(items []*entry) []*entry {
+		res := items[:0]
+		for _, item := range items {
+			if item.Name != service.Name || item.ID != service.ID {
+				res = append(res, item)
+			}
+		}
+		return res
+	})
+	if err != nil {
+		return err
+	}
+	return r.reload()
+}
+
+// GetService return the service instances in memory according to the service name.
+func (r *Registry) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return r.store.GetService(ctx, serviceName)
+}
+
+// Watch creates a watcher according to the service name.
+// 文件里的实例有变化时 watcher 返回最新的实例
+func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return r.store.Watch(ctx, serviceName)
+}
+
+// Close 停止监听文件和续约, 不会注销本进程注册的实例, 它们在 TTL 之后过期
+func (r *Registry) Close() error {
+	select {
+	case <-r.closed:
+	default:
+		close(r.closed)
+	}
+	r.wg.Wait()
+	return r.watcher.Close()
+}
+
+// keepalive 定期为本进程注册的实例续约, 并重新读取文件使其他进程过期的实例下线
+func (r *Registry) keepalive() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.opts.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+		if err := r.renew(); err != nil {
+			r.opts.logger.Warn("file registry: failed to renew", log.String("file", r.path), log.Error(err))
+		}
+		if err := r.reload(); err != nil {
+			r.opts.logger.Warn("file registry: failed to reload", log.String("file", r.path), log.Error(err))
+		}
+	}
+}
+
+// renew 更新本进程注册的实例的 heartbeat
+func (r *Registry) renew() error {
+	r.mu.Lock()
+	n := len(r.leases)
+	r.mu.Unlock()
+	if n == 0 {
+		return nil
+	}
+	now := time.Now()
+	return r.update(func(items []*entry) []*entry {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, item := range items {
+			if _, ok := r.leases[leaseKey(&item.ServiceInstance)]; ok {
+				item.Heartbeat = &now
+			}
+		}
+		return items
+	})
+}
+
+// reload 读取文件并更新内存里的实例, 过期的实例被忽略
+// 空文件通常是编辑器写了一半, 保留之前的实例, 没有实例时文件内容是 []
+func (r *Registry) reload() error {
+	items, err := r.read()
+	if err != nil {
+		return err
+	}
+	if items == nil {
+		return nil
+	}
+	services := make([]*registry.ServiceInstance, 0, len(items))
+	for _, item := range r.alive(items) {
+		services = append(services, &item.ServiceInstance)
+	}
+	return r.store.Replace(services)
+}
+
+// alive 过滤掉过期的实例
+func (r *Registry) alive(items []*entry) []*entry {
+	if r.opts.ttl <= 0 {
+		return items
+	}
+	deadline := time.Now().Add(-r.opts.ttl)
+	res := make([]*entry, 0, len(items))
+	for _, item := range items {
+		if item.Heartbeat == nil || item.Heartbeat.After(deadline) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// update 持有文件锁, 读取实例列表, 删除过期的实例并修改之后原子地写回文件
+func (r *Registry) update(fn func([]*entry) []*entry) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	items, err := r.read()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data, err := r.marshal(fn(r.alive(items)))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// lock 通过独占创建 .lock 文件实现跨进程的互斥
+func (r *Registry) lock() (func(), error) {
+	name := r.path + ".lock"
+	deadline := time.Now().Add(r.opts.lockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(name) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		// 持有锁的进程异常退出时残留的锁
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > 2*r.opts.lockTimeout {
+			_ = os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLockTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (r *Registry) read() ([]*entry, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	items := []*entry{}
+	if r.yaml() {
+		err = yaml.Unmarshal(data, &items)
+	} else {
+		err = json.Unmarshal(data, &items)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file registry: failed to parse %s: %w", r.path, err)
+	}
+	return items, nil
+}
+
+func (r *Registry) marshal(items []*entry) ([]byte, error) {
+	if items == nil {
+		items = []*entry{}
+	}
+	if r.yaml() {
+		return yaml.Marshal(items)
+	}
+	return json.MarshalIndent(items, "", "  ")
+}
+
+func (r *Registry) yaml() bool {
+	ext := strings.ToLower(filepath.Ext(r.path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func leaseKey(service *registry.ServiceInstance) string {
+	return service.Name + "/" + service.ID
+}
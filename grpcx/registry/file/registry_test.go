@@ -0,0 +1,188 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+func next(t *testing.T, w registry.Watcher) []*registry.ServiceInstance {
+	t.Helper()
+	ch := make(chan []*registry.ServiceInstance, 1)
+	go func() {
+		items, err := w.Next()
+		assert.NoError(t, err)
+		ch <- items
+	}()
+	select {
+	case items := <-ch:
+		return items
+	case <-time.After(3 * time.Second):
+		t.Fatal("watcher did not return")
+		return nil
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"registry.json", "registry.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), name)
+
+			// 模拟两个进程共享同一个文件
+			server, err := New(path)
+			assert.NoError(t, err)
+			defer server.Close()
+			client, err := New(path)
+			assert.NoError(t, err)
+			defer client.Close()
+
+			w, err := client.Watch(ctx, "helloworld")
+			assert.NoError(t, err)
+			defer w.Stop()
+
+			ins := &registry.ServiceInstance{
+				ID:        "1",
+				Name:      "helloworld",
+				Version:   "v1",
+				Metadata:  map[string]string{"zone": "z1"},
+				Endpoints: []string{"grpc://127.0.0.1:9000?isSecure=false"},
+			}
+			assert.NoError(t, server.Register(ctx, ins))
+
+			items := next(t, w)
+			assert.Len(t, items, 1)
+			assert.True(t, ins.Equal(items[0]))
+
+			items, err = client.GetService(ctx, "helloworld")
+			assert.NoError(t, err)
+			assert.Len(t, items, 1)
+
+			assert.NoError(t, server.Deregister(ctx, ins))
+			assert.Empty(t, next(t, w))
+		})
+	}
+}
+
+func TestRegistry_Edit(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+- id: "1"
+  name: helloworld
+  endpoints:
+    - grpc://127.0.0.1:9000
+`), 0o644))
+
+	r, err := New(path)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	w, err := r.Watch(ctx, "helloworld")
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Len(t, next(t, w), 1)
+
+	// 手动编辑文件
+	assert.NoError(t, os.WriteFile(path, []byte(`
+- id: "1"
+  name: helloworld
+  endpoints:
+    - grpc://127.0.0.1:9000
+- id: "2"
+  name: helloworld
+  endpoints:
+    - grpc://127.0.0.1:9001
+`), 0o644))
+	assert.Len(t, next(t, w), 2)
+
+	// 格式错误时保留之前的实例
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	time.Sleep(100 * time.Millisecond)
+	items, err := r.GetService(ctx, "helloworld")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+}
+
+func TestRegistry_Lock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	r, err := New(path, LockTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	defer r.Close()
+
+	unlock, err := r.lock()
+	assert.NoError(t, err)
+	err = r.Register(context.Background(), &registry.ServiceInstance{ID: "1", Name: "helloworld"})
+	assert.ErrorIs(t, err, ErrLockTimeout)
+	unlock()
+
+	assert.NoError(t, r.Register(context.Background(), &registry.ServiceInstance{ID: "1", Name: "helloworld"}))
+}
+
+func TestRegistry_Expire(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	stale := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	assert.NoError(t, os.WriteFile(path, []byte(`
+- id: "1"
+  name: helloworld
+  endpoints:
+    - grpc://127.0.0.1:9000
+- id: "2"
+  name: helloworld
+  endpoints:
+    - grpc://127.0.0.1:9001
+  heartbeat: "`+stale+`"
+`), 0o644))
+
+	// 没有 heartbeat 的实例不会过期, 超过 TTL 没有续约的实例被忽略
+	r, err := New(path, TTL(time.Minute))
+	assert.NoError(t, err)
+	defer r.Close()
+	items, err := r.GetService(ctx, "helloworld")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "1", items[0].ID)
+
+	// 写文件时删除过期的实例
+	assert.NoError(t, r.Register(ctx, &registry.ServiceInstance{ID: "3", Name: "helloworld"}))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "127.0.0.1:9001")
+	assert.Contains(t, string(data), "heartbeat")
+}
+
+func TestRegistry_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "registry.json")
+	ttl := 300 * time.Millisecond
+
+	server, err := New(path, TTL(ttl))
+	assert.NoError(t, err)
+	client, err := New(path, TTL(ttl))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	w, err := client.Watch(ctx, "helloworld")
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	assert.NoError(t, server.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "helloworld"}))
+	assert.Len(t, next(t, w), 1)
+
+	// 持续续约的实例不会过期
+	time.Sleep(2 * ttl)
+	items, err := client.GetService(ctx, "helloworld")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	// 进程退出而没有注销, 实例在 TTL 之后下线
+	assert.NoError(t, server.Close())
+	assert.Empty(t, next(t, w))
+}
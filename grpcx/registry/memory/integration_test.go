@@ -0,0 +1,86 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+
+	"github.com/apus-run/sea-kit/grpcx/balancer/p2c"
+	"github.com/apus-run/sea-kit/grpcx/registry"
+	"github.com/apus-run/sea-kit/grpcx/registry/memory"
+	"github.com/apus-run/sea-kit/grpcx/resolver/discov"
+	"github.com/apus-run/sea-kit/zlog"
+)
+
+func startServer(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+// TestDiscovBalancer 使用内存注册中心测试 discov resolver 和 p2c 负载均衡器
+func TestDiscovBalancer(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+
+	var instances []*registry.ServiceInstance
+	for i := 0; i < 2; i++ {
+		ins := &registry.ServiceInstance{
+			ID:        fmt.Sprint(i),
+			Name:      "helloworld",
+			Endpoints: []string{fmt.Sprintf("grpc://%s?isSecure=false", startServer(t))},
+		}
+		assert.NoError(t, r.Register(ctx, ins))
+		instances = append(instances, ins)
+	}
+
+	conn, err := grpc.DialContext(ctx, "discovery:///helloworld",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(discov.NewBuilder(r, discov.WithInsecure(true), discov.WithLogger(zlog.L()), discov.PrintDebugLog(false))),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{"%s":{}}]}`, p2c.Name)),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	call := func() string {
+		var p peer.Peer
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p), grpc.WaitForReady(true))
+		assert.NoError(t, err)
+		return p.Addr.String()
+	}
+	assert.Eventually(t, func() bool {
+		seen := make(map[string]struct{})
+		for i := 0; i < 20; i++ {
+			seen[call()] = struct{}{}
+		}
+		return len(seen) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// 下线一个实例之后只会调用另一个实例
+	assert.NoError(t, r.Deregister(ctx, instances[0]))
+	alive := instances[1].Endpoints[0]
+	assert.Eventually(t, func() bool {
+		for i := 0; i < 20; i++ {
+			if fmt.Sprintf("grpc://%s?isSecure=false", call()) != alive {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
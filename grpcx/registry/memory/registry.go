@@ -0,0 +1,199 @@
+// Package memory 实现进程内的服务注册中心, 不依赖任何外部服务,
+// 适用于单元测试以及 discov resolver 和负载均衡器的集成测试.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+var (
+	_ registry.Registrar = (*Registry)(nil)
+	_ registry.Discovery = (*Registry)(nil)
+)
+
+// ErrInvalidInstance 服务实例缺少 Name 或 ID
+var ErrInvalidInstance = errors.New("memory: service instance name and id are required")
+
+// Registry is in-memory registry.
+type Registry struct {
+	mu sync.RWMutex
+	// service name -> instance id -> instance
+	services map[string]map[string]*registry.ServiceInstance
+	watchers map[string]map[*watcher]struct{}
+}
+
+// New creates in-memory registry
+func New() *Registry {
+	return &Registry{
+		services: make(map[string]map[string]*registry.ServiceInstance),
+		watchers: make(map[string]map[*watcher]struct{}),
+	}
+}
+
+// Register the registration.
+// 重复注册相同 ID 的实例会覆盖之前的实例
+func (r *Registry) Register(_ context.Context, service *registry.ServiceInstance) error {
+	if service == nil || service.Name == "" || service.ID == "" {
+		return ErrInvalidInstance
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances, ok := r.services[service.Name]
+	if !ok {
+		instances = make(map[string]*registry.ServiceInstance)
+		r.services[service.Name] = instances
+	}
+	if old, ok := instances[service.ID]; ok && old.Equal(service) {
+		return nil
+	}
+	instances[service.ID] = clone(service)
+	r.notify(service.Name)
+	return nil
+}
+
+// Deregister the registration.
+func (r *Registry) Deregister(_ context.Context, service *registry.ServiceInstance) error {
+	if service == nil || service.Name == "" || service.ID == "" {
+		return ErrInvalidInstance
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances := r.services[service.Name]
+	if _, ok := instances[service.ID]; !ok {
+		return nil
+	}
+	delete(instances, service.ID)
+	if len(instances) == 0 {
+		delete(r.services, service.Name)
+	}
+	r.notify(service.Name)
+	return nil
+}
+
+// Replace 用 services 替换全部实例, 只通知实例有变化的服务的 watcher
+func (r *Registry) Replace(services []*registry.ServiceInstance) error {
+	next := make(map[string]map[string]*registry.ServiceInstance)
+	for _, service := range services {
+		if service == nil || service.Name == "" || service.ID == "" {
+			return ErrInvalidInstance
+		}
+		instances, ok := next[service.Name]
+		if !ok {
+			instances = make(map[string]*registry.ServiceInstance)
+			next[service.Name] = instances
+		}
+		instances[service.ID] = clone(service)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.services
+	r.services = next
+	for name := range prev {
+		if !equal(prev[name], next[name]) {
+			r.notify(name)
+		}
+	}
+	for name := range next {
+		if _, ok := prev[name]; !ok {
+			r.notify(name)
+		}
+	}
+	return nil
+}
+
+// GetService return the service instances in memory according to the service name.
+// 实例按 ID 排序
+func (r *Registry) GetService(_ context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instances(serviceName), nil
+}
+
+// Watch creates a watcher according to the service name.
+func (r *Registry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	w := &watcher{
+		registry:    r,
+		serviceName: serviceName,
+		event:       make(chan struct{}, 1),
+		first:       true,
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watchers, ok := r.watchers[serviceName]
+	if !ok {
+		watchers = make(map[*watcher]struct{})
+		r.watchers[serviceName] = watchers
+	}
+	watchers[w] = struct{}{}
+	return w, nil
+}
+
+// instances 需要持有读锁
+func (r *Registry) instances(serviceName string) []*registry.ServiceInstance {
+	instances := r.services[serviceName]
+	items := make([]*registry.ServiceInstance, 0, len(instances))
+	for _, ins := range instances {
+		items = append(items, clone(ins))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// notify 需要持有写锁, 合并还没有被消费的通知
+func (r *Registry) notify(serviceName string) {
+	for w := range r.watchers[serviceName] {
+		select {
+		case w.event <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *Registry) removeWatcher(w *watcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watchers := r.watchers[w.serviceName]
+	delete(watchers, w)
+	if len(watchers) == 0 {
+		delete(r.watchers, w.serviceName)
+	}
+}
+
+func clone(ins *registry.ServiceInstance) *registry.ServiceInstance {
+	c := *ins
+	if ins.Metadata != nil {
+		c.Metadata = make(map[string]string, len(ins.Metadata))
+		for k, v := range ins.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	c.Endpoints = append([]string(nil), ins.Endpoints...)
+	return &c
+}
+
+func equal(a, b map[string]*registry.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, ins := range a {
+		if !ins.Equal(b[id]) {
+			return false
+		}
+	}
+	return true
+}
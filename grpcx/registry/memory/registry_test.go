@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+func instance(id string, endpoints ...string) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		ID:        id,
+		Name:      "helloworld",
+		Version:   "v1",
+		Metadata:  map[string]string{"zone": "z1"},
+		Endpoints: endpoints,
+	}
+}
+
+func next(t *testing.T, w registry.Watcher) []*registry.ServiceInstance {
+	t.Helper()
+	ch := make(chan []*registry.ServiceInstance, 1)
+	go func() {
+		items, err := w.Next()
+		assert.NoError(t, err)
+		ch <- items
+	}()
+	select {
+	case items := <-ch:
+		return items
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not return")
+		return nil
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+
+	assert.ErrorIs(t, r.Register(ctx, &registry.ServiceInstance{Name: "helloworld"}), ErrInvalidInstance)
+
+	ins1 := instance("1", "grpc://127.0.0.1:9000")
+	assert.NoError(t, r.Register(ctx, ins1))
+	// 修改注册时传入的实例不影响注册中心
+	ins1.Metadata["zone"] = "z2"
+
+	items, err := r.GetService(ctx, "helloworld")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "z1", items[0].Metadata["zone"])
+
+	items, err = r.GetService(ctx, "notfound")
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	assert.NoError(t, r.Deregister(ctx, ins1))
+	items, _ = r.GetService(ctx, "helloworld")
+	assert.Empty(t, items)
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	assert.NoError(t, r.Register(ctx, instance("1", "grpc://127.0.0.1:9000")))
+
+	w, err := r.Watch(ctx, "helloworld")
+	assert.NoError(t, err)
+
+	// 第一次返回当前的实例
+	items := next(t, w)
+	assert.Len(t, items, 1)
+
+	assert.NoError(t, r.Register(ctx, instance("2", "grpc://127.0.0.1:9001")))
+	items = next(t, w)
+	assert.Equal(t, "1", items[0].ID)
+	assert.Equal(t, "2", items[1].ID)
+
+	assert.NoError(t, r.Deregister(ctx, instance("1")))
+	items = next(t, w)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "2", items[0].ID)
+
+	assert.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatcher_Empty(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	w, err := r.Watch(ctx, "helloworld")
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// 没有实例时第一次调用阻塞到有实例注册
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = r.Register(ctx, instance("1", "grpc://127.0.0.1:9000"))
+	}()
+	items := next(t, w)
+	assert.Len(t, items, 1)
+}
+
+func TestRegistry_Replace(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	assert.NoError(t, r.Register(ctx, instance("1", "grpc://127.0.0.1:9000")))
+
+	w, err := r.Watch(ctx, "helloworld")
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Len(t, next(t, w), 1)
+
+	// 实例没有变化时不通知
+	assert.NoError(t, r.Replace([]*registry.ServiceInstance{instance("1", "grpc://127.0.0.1:9000")}))
+	assert.NoError(t, r.Replace([]*registry.ServiceInstance{
+		instance("1", "grpc://127.0.0.1:9000"),
+		instance("2", "grpc://127.0.0.1:9001"),
+	}))
+	assert.Len(t, next(t, w), 2)
+
+	// 其他服务没有变化
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	ow, err := r.Watch(ctx, "other")
+	assert.NoError(t, err)
+	_, err = ow.Next()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/apus-run/sea-kit/grpcx/registry"
+)
+
+var _ registry.Watcher = (*watcher)(nil)
+
+type watcher struct {
+	registry    *Registry
+	serviceName string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	// 实例有变化时收到通知, 缓冲为 1, 多次变化会合并成一次
+	event chan struct{}
+	first bool
+}
+
+// Next 第一次调用时如果已经有实例就直接返回, 否则阻塞到实例有变化或者 ctx 结束
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.first {
+		w.first = false
+		// 先丢掉 Watch 之后到现在的通知, 下面读到的实例已经包含了这些变化
+		select {
+		case <-w.event:
+		default:
+		}
+		if items := w.instances(); len(items) > 0 {
+			return items, nil
+		}
+	}
+
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.event:
+		return w.instances(), nil
+	}
+}
+
+// Stop close the watcher.
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.registry.removeWatcher(w)
+	return nil
+}
+
+func (w *watcher) instances() []*registry.ServiceInstance {
+	w.registry.mu.RLock()
+	defer w.registry.mu.RUnlock()
+	return w.registry.instances(w.serviceName)
+}
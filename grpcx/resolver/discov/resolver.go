@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/resolver"
 
+	"github.com/apus-run/sea-kit/grpcx/balancer/routing"
 	"github.com/apus-run/sea-kit/grpcx/internal/endpoint"
 	"github.com/apus-run/sea-kit/grpcx/registry"
 	log "github.com/apus-run/sea-kit/zlog"
@@ -74,7 +75,7 @@ func (r *discovResolver) update(ins []*registry.ServiceInstance) {
 		endpoints[ept] = struct{}{}
 		addr := resolver.Address{
 			ServerName: in.Name,
-			Attributes: parseAttributes(in.Metadata).WithValue("rawServiceInstance", in),
+			Attributes: parseAttributes(in.Metadata).WithValue(routing.InstanceAttributeKey, in),
 			Addr:       ept,
 		}
 		addrs = append(addrs, addr)
@@ -2,29 +2,30 @@ package idempotent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// redis lua script(read => delete => get delete flag).
+// Result 幂等检查的结果, 用于指标统计
+type Result string
+
 const (
-	lua string = `
-local current = redis.call('GET', KEYS[1])
-if current == false then
-    return '-1';
-end
-local del = redis.call('DEL', KEYS[1])
-if del == 1 then
-     return '1';
-else
-     return '0';
-end
-`
+	// ResultHit token 有效, 或者找到了已有的处理记录
+	ResultHit Result = "hit"
+	// ResultMiss token 不存在, 或者没有已有的处理记录
+	ResultMiss Result = "miss"
+	// ResultExpired token 已过期
+	ResultExpired Result = "expired"
 )
 
+// legacyToken 旧版本 Token 保存的值
+const legacyToken = "1"
+
 type Idempotent struct {
 	ops Options
 }
@@ -34,30 +35,82 @@ func New(options ...func(*Options)) *Idempotent {
 	for _, f := range options {
 		f(ops)
 	}
+	if ops.store == nil && ops.redis != nil {
+		ops.store = NewRedisStore(ops.redis)
+	}
 	return &Idempotent{ops: *ops}
 }
 
+// Strict 是否为严格模式, 严格模式下存储不可用时拒绝请求
+func (i *Idempotent) Strict() bool {
+	return i.ops.strict
+}
+
+// Token 生成一个 token, 存储的值为过期时间的毫秒时间戳, 存储的过期时间为 WithExpire 加上
+// WithExpiredRetention, 保留期内的 token 校验时会被识别为过期而不是不存在
 func (i *Idempotent) Token(ctx context.Context) string {
-	if i.ops.redis == nil {
-		slog.Default().WarnContext(ctx, "please enable redis, otherwise the idempotent is invalid")
+	if i.ops.store == nil {
+		slog.Default().WarnContext(ctx, "please enable store, otherwise the idempotent is invalid")
 		return ""
 	}
 
 	token := uuid.NewString()
-	i.ops.redis.Set(ctx, fmt.Sprintf("%s_%s", i.ops.prefix, token), true, time.Duration(i.ops.expire)*time.Minute)
+	expireAt := time.Now().Add(i.expiration()).UnixMilli()
+	err := i.ops.store.Set(ctx, i.tokenKey(token), []byte(strconv.FormatInt(expireAt, 10)), i.expiration()+i.retention())
+	if err != nil {
+		slog.Default().ErrorContext(ctx, "idempotent token", slog.Any("err", err))
+		return ""
+	}
 	return token
 }
 
+// Check 校验并消费 token, 同一个 token 只有第一次校验会返回 true.
+// 未配置存储时, 非严格模式返回 true, 严格模式返回 false.
 func (i *Idempotent) Check(ctx context.Context, token string) bool {
-	if i.ops.redis == nil {
-		slog.Default().WarnContext(ctx, "please enable redis, otherwise the idempotent is invalid")
-		return true
+	if i.ops.store == nil {
+		slog.Default().WarnContext(ctx, "please enable store, otherwise the idempotent is invalid")
+		return !i.ops.strict
 	}
 
-	res, err := i.ops.redis.Eval(ctx, lua, []string{fmt.Sprintf("%s_%s", i.ops.prefix, token)}).Result()
-	if err != nil || res != "1" {
+	// 读取和删除是原子的, 并发校验同一个 token 时只有一个请求能读到
+	data, err := i.ops.store.GetDel(ctx, i.tokenKey(token))
+	if errors.Is(err, ErrNotFound) {
+		i.observe(ctx, ResultMiss)
+		return false
+	}
+	if err != nil {
+		slog.Default().ErrorContext(ctx, "idempotent check", slog.Any("err", err))
 		return false
 	}
 
+	// 旧版本的 token 保存的是 true (即 "1"), 没有记录过期时间, 在 key 过期前一直有效
+	if expired(data) {
+		i.observe(ctx, ResultExpired)
+		return false
+	}
+	i.observe(ctx, ResultHit)
 	return true
 }
+
+// expired 判断 token 是否已经过期, 存储的值是过期时间的毫秒时间戳
+func expired(data []byte) bool {
+	if string(data) == legacyToken {
+		return false
+	}
+	expireAt, err := strconv.ParseInt(string(data), 10, 64)
+	return err == nil && time.Now().UnixMilli() >= expireAt
+}
+
+func (i *Idempotent) observe(ctx context.Context, result Result) {
+	if i.ops.metrics != nil {
+		i.ops.metrics(ctx, result)
+	}
+}
+
+func (i *Idempotent) retention() time.Duration {
+	return time.Duration(i.ops.retention) * time.Minute
+}
+
+func (i *Idempotent) tokenKey(token string) string {
+	return fmt.Sprintf("%s_%s", i.ops.prefix, token)
+}
@@ -0,0 +1,89 @@
+package idempotent
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotent_Check(t *testing.T) {
+	ctx := context.Background()
+	var results []Result
+	i := New(
+		WithStore(NewMemoryStore()),
+		WithMetrics(func(ctx context.Context, result Result) {
+			results = append(results, result)
+		}),
+	)
+
+	token := i.Token(ctx)
+	require.NotEmpty(t, token)
+	assert.True(t, i.Check(ctx, token))
+	assert.False(t, i.Check(ctx, token))
+
+	// 逻辑上已过期但仍在存储中的 token
+	expireAt := time.Now().Add(-time.Minute).UnixMilli()
+	require.NoError(t, i.ops.store.Set(ctx, i.tokenKey("expired"), []byte(strconv.FormatInt(expireAt, 10)), 0))
+	assert.False(t, i.Check(ctx, "expired"))
+
+	// 旧版本生成的 token, 在 key 过期前有效
+	require.NoError(t, i.ops.store.Set(ctx, i.tokenKey("legacy"), []byte("1"), time.Minute))
+	assert.True(t, i.Check(ctx, "legacy"))
+	assert.False(t, i.Check(ctx, "legacy"))
+
+	assert.Equal(t, []Result{ResultHit, ResultMiss, ResultExpired, ResultHit, ResultMiss}, results)
+}
+
+func TestIdempotent_CheckConcurrent(t *testing.T) {
+	ctx := context.Background()
+	i := New(WithStore(NewMemoryStore()))
+	token := i.Token(ctx)
+	require.NotEmpty(t, token)
+
+	// 并发校验同一个 token, 只有一个成功
+	var passed atomic.Int32
+	var wg sync.WaitGroup
+	for n := 0; n < 16; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i.Check(ctx, token) {
+				passed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), passed.Load())
+}
+
+func TestIdempotent_TokenTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// 默认与旧版本一样, 存储的过期时间为 WithExpire
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	token := New(WithStore(store), WithExpire(10)).Token(ctx)
+	assert.Equal(t, now.Add(10*time.Minute), store.items["idempotent_"+token].expireAt)
+
+	store = NewMemoryStore()
+	store.now = func() time.Time { return now }
+	token = New(WithStore(store), WithExpire(10), WithExpiredRetention(5)).Token(ctx)
+	assert.Equal(t, now.Add(15*time.Minute), store.items["idempotent_"+token].expireAt)
+}
+
+func TestIdempotent_Strict(t *testing.T) {
+	ctx := context.Background()
+
+	assert.True(t, New().Check(ctx, "token"))
+	assert.False(t, New(WithStrict()).Check(ctx, "token"))
+
+	_, _, err := New(WithStrict()).Begin(ctx, "key")
+	assert.Equal(t, ErrNoStore, err)
+}
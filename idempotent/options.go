@@ -1,13 +1,19 @@
 package idempotent
 
 import (
+	"context"
+
 	"github.com/redis/go-redis/v9"
 )
 
 type Options struct {
-	redis  redis.UniversalClient
-	prefix string
-	expire int
+	redis     redis.UniversalClient
+	store     Store
+	prefix    string
+	expire    int
+	retention int
+	strict    bool
+	metrics   func(ctx context.Context, result Result)
 }
 
 func WithRedis(rd redis.UniversalClient) func(*Options) {
@@ -20,6 +26,17 @@ func WithRedis(rd redis.UniversalClient) func(*Options) {
 	}
 }
 
+// WithStore sets the store used to save records, default is a RedisStore if WithRedis is set.
+func WithStore(store Store) func(*Options) {
+	return func(options *Options) {
+		if store == nil {
+			return
+		}
+
+		getOptionsOrSetDefault(options).store = store
+	}
+}
+
 func WithPrefix(prefix string) func(*Options) {
 	return func(options *Options) {
 		if prefix == "" {
@@ -40,6 +57,37 @@ func WithExpire(min int) func(*Options) {
 	}
 }
 
+// WithExpiredRetention keeps expired tokens in the store for min more minutes, so that
+// checking them reports ResultExpired instead of ResultMiss, default 0.
+// Instances older than the pluggable stores accept the retained tokens, enable it once all are upgraded.
+func WithExpiredRetention(min int) func(*Options) {
+	return func(options *Options) {
+		if min <= 0 {
+			return
+		}
+
+		getOptionsOrSetDefault(options).retention = min
+	}
+}
+
+// WithStrict fails closed when no store is configured or the store is unavailable.
+func WithStrict() func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).strict = true
+	}
+}
+
+// WithMetrics sets a hook called with the result of every check, e.g. to count hits, misses and expirations.
+func WithMetrics(fn func(ctx context.Context, result Result)) func(*Options) {
+	return func(options *Options) {
+		if fn == nil {
+			return
+		}
+
+		getOptionsOrSetDefault(options).metrics = fn
+	}
+}
+
 // getOptionsOrSetDefault returns the provided options if they are not nil,
 // otherwise it returns a default set of options.
 func getOptionsOrSetDefault(options *Options) *Options {
@@ -0,0 +1,98 @@
+package idempotent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoStore 未配置存储
+var ErrNoStore = errors.New("idempotent: store is not configured")
+
+// Status 请求的处理状态
+type Status string
+
+const (
+	// StatusInProgress 请求处理中
+	StatusInProgress Status = "in_progress"
+	// StatusDone 请求已处理完成, 记录中保存了响应
+	StatusDone Status = "done"
+)
+
+// Record 一个幂等 key 对应的处理记录, 用于重放第一次请求的响应
+type Record struct {
+	Status Status              `json:"status"`
+	Code   int                 `json:"code,omitempty"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   []byte              `json:"body,omitempty"`
+}
+
+// Begin 将 key 标记为处理中.
+// 返回 true 表示当前请求获得了处理权; 返回 false 时 Record 为 key 已有的记录.
+func (i *Idempotent) Begin(ctx context.Context, key string) (*Record, bool, error) {
+	if i.ops.store == nil {
+		return nil, false, ErrNoStore
+	}
+
+	data, err := json.Marshal(&Record{Status: StatusInProgress})
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := i.ops.store.SetNX(ctx, i.recordKey(key), data, i.expiration())
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		i.observe(ctx, ResultMiss)
+		return nil, true, nil
+	}
+
+	data, err = i.ops.store.Get(ctx, i.recordKey(key))
+	if errors.Is(err, ErrNotFound) {
+		// 记录恰好过期或被释放, 重新抢占
+		return i.Begin(ctx, key)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var rec Record
+	if err = json.Unmarshal(data, &rec); err != nil {
+		return nil, false, err
+	}
+	i.observe(ctx, ResultHit)
+	return &rec, false, nil
+}
+
+// Finish 保存请求的响应, 之后相同 key 的请求会重放该响应
+func (i *Idempotent) Finish(ctx context.Context, key string, rec *Record) error {
+	if i.ops.store == nil {
+		return ErrNoStore
+	}
+
+	rec.Status = StatusDone
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return i.ops.store.Set(ctx, i.recordKey(key), data, i.expiration())
+}
+
+// Abort 释放 key, 一般在请求处理失败时调用, 以便客户端重试
+func (i *Idempotent) Abort(ctx context.Context, key string) error {
+	if i.ops.store == nil {
+		return ErrNoStore
+	}
+
+	_, err := i.ops.store.Delete(ctx, i.recordKey(key))
+	return err
+}
+
+func (i *Idempotent) recordKey(key string) string {
+	return fmt.Sprintf("%s_record_%s", i.ops.prefix, key)
+}
+
+func (i *Idempotent) expiration() time.Duration {
+	return time.Duration(i.ops.expire) * time.Minute
+}
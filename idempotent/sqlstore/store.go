@@ -0,0 +1,181 @@
+// Package sqlstore 基于 gormx 的幂等记录存储, 支持 gorm 支持的所有数据库.
+// db 一般通过 gormx.Helper 获取, 记录表需要先调用 AutoMigrate 创建
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/apus-run/sea-kit/gormx"
+	"github.com/apus-run/sea-kit/idempotent"
+)
+
+var _ idempotent.Store = (*Store)(nil)
+
+// Record 幂等记录表
+type Record struct {
+	Key string `gorm:"primaryKey;type:varchar(255)"`
+	Val []byte
+	// ExpireAt 过期时间的毫秒时间戳, 0 表示永不过期
+	ExpireAt int64 `gorm:"index"`
+}
+
+// Store 基于 gormx 的存储, 过期的记录在读写时惰性删除, 也可以定期调用 DeleteExpired 清理
+type Store struct {
+	db    *gorm.DB
+	table string
+	now   func() time.Time
+}
+
+// Option Store 配置
+type Option func(*Store)
+
+// WithTable 设置表名, 默认 idempotent_records
+func WithTable(table string) Option {
+	return func(s *Store) {
+		if table == "" {
+			return
+		}
+		s.table = table
+	}
+}
+
+func NewStore(db *gorm.DB, opts ...Option) *Store {
+	s := &Store{
+		db:    db,
+		table: "idempotent_records",
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AutoMigrate 创建记录表
+func (s *Store) AutoMigrate() error {
+	return s.db.Table(s.table).AutoMigrate(&Record{})
+}
+
+func (s *Store) SetNX(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	var created bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先删掉已经过期的记录, 否则插入会冲突
+		err := gormx.DeleteByMap[Record](ctx, tx.Table(s.table), keyMap(key), expired(s.now().UnixMilli()))
+		if err != nil {
+			return err
+		}
+
+		res := tx.Table(s.table).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&Record{Key: key, Val: val, ExpireAt: s.expireAt(ttl)})
+		created = res.RowsAffected > 0
+		return res.Error
+	})
+	return created, err
+}
+
+func (s *Store) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	db := s.db.Table(s.table).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"val", "expire_at"}),
+	})
+	_, err := gormx.New(ctx, db, &Record{Key: key, Val: val, ExpireAt: s.expireAt(ttl)})
+	return err
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.get(ctx, s.db, key)
+}
+
+func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
+	var deleted bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		deleted, err = s.delete(ctx, tx, key)
+		return err
+	})
+	return deleted, err
+}
+
+// GetDel 在事务中读取并删除记录, 并发删除同一条记录时只有一个事务删除成功, 其余返回 ErrNotFound
+func (s *Store) GetDel(ctx context.Context, key string) ([]byte, error) {
+	var val []byte
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if val, err = s.get(ctx, tx, key); err != nil {
+			return err
+		}
+		deleted, err := s.delete(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return idempotent.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// DeleteExpired 清理过期的记录
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	res := s.db.WithContext(ctx).Table(s.table).
+		Where(expired(s.now().UnixMilli())).
+		Delete(&Record{})
+	return res.RowsAffected, res.Error
+}
+
+func (s *Store) get(ctx context.Context, db *gorm.DB, key string) ([]byte, error) {
+	rec, err := gormx.GetByMap[Record](ctx, db.Table(s.table), keyMap(key), alive(s.now().UnixMilli()))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, idempotent.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rec.Val, nil
+}
+
+// delete 删除未过期的记录并返回是否删除成功, 过期的记录顺便删除
+func (s *Store) delete(ctx context.Context, tx *gorm.DB, key string) (bool, error) {
+	res := tx.Table(s.table).
+		Where(keyMap(key)).
+		Where(alive(s.now().UnixMilli())).
+		Delete(&Record{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, gormx.DeleteByMap[Record](ctx, tx.Table(s.table), keyMap(key))
+}
+
+func (s *Store) expireAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return s.now().Add(ttl).UnixMilli()
+}
+
+// keyMap key 在部分数据库中是保留字, map 条件的列名会按方言转义
+func keyMap(key string) map[string]any {
+	return map[string]any{"key": key}
+}
+
+func alive(now int64) clause.Expression {
+	return clause.Or(
+		clause.Eq{Column: clause.Column{Name: "expire_at"}, Value: 0},
+		clause.Gt{Column: clause.Column{Name: "expire_at"}, Value: now},
+	)
+}
+
+func expired(now int64) clause.Expression {
+	return clause.And(
+		clause.Gt{Column: clause.Column{Name: "expire_at"}, Value: 0},
+		clause.Lte{Column: clause.Column{Name: "expire_at"}, Value: now},
+	)
+}
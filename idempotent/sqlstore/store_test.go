@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/apus-run/sea-kit/idempotent"
+)
+
+func newStore(t *testing.T) *Store {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	s := NewStore(db)
+	require.NoError(t, s.AutoMigrate())
+	return s
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	ok, err := s.SetNX(ctx, "a", []byte("1"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.SetNX(ctx, "a", []byte("2"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	val, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), val)
+
+	require.NoError(t, s.Set(ctx, "a", []byte("3"), time.Minute))
+	val, err = s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), val)
+
+	// 过期之后读不到, 可以重新 SetNX
+	now = now.Add(2 * time.Minute)
+	_, err = s.Get(ctx, "a")
+	assert.Equal(t, idempotent.ErrNotFound, err)
+	ok, err = s.SetNX(ctx, "a", []byte("4"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.Delete(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.Delete(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStore_GetDel(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	require.NoError(t, s.Set(ctx, "a", []byte("1"), time.Minute))
+	val, err := s.GetDel(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), val)
+	_, err = s.GetDel(ctx, "a")
+	assert.Equal(t, idempotent.ErrNotFound, err)
+
+	// 过期的记录读不到, 由 DeleteExpired 清理
+	require.NoError(t, s.Set(ctx, "b", []byte("2"), time.Minute))
+	now = now.Add(2 * time.Minute)
+	_, err = s.GetDel(ctx, "b")
+	assert.Equal(t, idempotent.ErrNotFound, err)
+	n, err := s.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestStore_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	var results []idempotent.Result
+	i := idempotent.New(
+		idempotent.WithStore(newStore(t)),
+		idempotent.WithMetrics(func(ctx context.Context, result idempotent.Result) {
+			results = append(results, result)
+		}),
+	)
+
+	token := i.Token(ctx)
+	require.NotEmpty(t, token)
+	assert.True(t, i.Check(ctx, token))
+	assert.False(t, i.Check(ctx, token))
+	assert.Equal(t, []idempotent.Result{idempotent.ResultHit, idempotent.ResultMiss}, results)
+}
@@ -0,0 +1,25 @@
+package idempotent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound key 不存在或已过期
+var ErrNotFound = errors.New("idempotent: key not found")
+
+// Store 幂等记录的存储
+type Store interface {
+	// SetNX key 不存在时写入, 返回是否写入成功
+	SetNX(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error)
+	// Set 写入 key, 已存在时覆盖
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	// Get 读取 key, 不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete 删除 key, 返回删除前 key 是否存在
+	Delete(ctx context.Context, key string) (bool, error)
+	// GetDel 原子地读取并删除 key, 不存在时返回 ErrNotFound.
+	// 并发调用时只有一个调用方能读到值
+	GetDel(ctx context.Context, key string) ([]byte, error)
+}
@@ -0,0 +1,100 @@
+package idempotent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore 进程内存储, 一般用于测试或单机部署
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	now   func() time.Time
+}
+
+type memoryItem struct {
+	val      []byte
+	expireAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]memoryItem),
+		now:   time.Now,
+	}
+}
+
+func (s *MemoryStore) SetNX(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, val, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, val, ttl)
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return item.val, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.get(key)
+	delete(s.items, key)
+	return ok, nil
+}
+
+func (s *MemoryStore) GetDel(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(s.items, key)
+	return item.val, nil
+}
+
+// get 读取未过期的 key, 已过期的顺便删除
+func (s *MemoryStore) get(key string) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return item, false
+	}
+	if !item.expireAt.IsZero() && !s.now().Before(item.expireAt) {
+		delete(s.items, key)
+		return item, false
+	}
+	return item, true
+}
+
+func (s *MemoryStore) set(key string, val []byte, ttl time.Duration) {
+	item := memoryItem{val: val}
+	if ttl > 0 {
+		item.expireAt = s.now().Add(ttl)
+	}
+	s.items[key] = item
+}
@@ -0,0 +1,61 @@
+package idempotent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Store = (*RedisStore)(nil)
+
+// getDel 读取并删除 key, 兼容不支持 GETDEL 的 redis 版本
+var getDel = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+    redis.call('DEL', KEYS[1])
+end
+return current
+`)
+
+// RedisStore 基于 redis 的存储
+type RedisStore struct {
+	client redis.Cmdable
+}
+
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) SetNX(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, val, ttl).Result()
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, val, ttl).Err()
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return val, err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Del(ctx, key).Result()
+	return n > 0, err
+}
+
+func (s *RedisStore) GetDel(ctx context.Context, key string) ([]byte, error) {
+	val, err := getDel.Run(ctx, s.client, []string{key}).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(val), nil
+}
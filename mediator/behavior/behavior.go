@@ -0,0 +1,123 @@
+// Package behavior 提供 mediator.RequestBus 常用的 Behavior.
+package behavior
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/apus-run/sea-kit/mediator"
+	"github.com/apus-run/sea-kit/validator"
+)
+
+// When 只对 match 返回 true 的请求执行 b, 例如只对命令开启事务
+func When(match func(req any) bool, b mediator.Behavior) mediator.Behavior {
+	return func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		if !match(req) {
+			return next(ctx, req)
+		}
+		return b(ctx, req, next)
+	}
+}
+
+// Logging 记录请求类型, 耗时和错误, logger 为 nil 时使用 slog.Default()
+func Logging(logger *slog.Logger) mediator.Behavior {
+	return func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		start := time.Now()
+		res, err := next(ctx, req)
+		attrs := []any{
+			slog.String("request", fmt.Sprintf("%T", req)),
+			slog.Duration("cost", time.Since(start)),
+		}
+		if err != nil {
+			l.ErrorContext(ctx, "mediator send", append(attrs, slog.Any("err", err))...)
+			return res, err
+		}
+		l.InfoContext(ctx, "mediator send", attrs...)
+		return res, nil
+	}
+}
+
+// Validate 使用 validator 校验请求结构体, 校验失败时不会调用处理器.
+// v 为 nil 时使用 validator.V(), 默认的 validator 没有初始化时创建一个新的.
+func Validate(v *validator.Validator) mediator.Behavior {
+	if v == nil {
+		v = validator.V()
+	}
+	if v == nil {
+		v = validator.New()
+	}
+	return func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		if err := v.ValidCtx(ctx, req); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// Tracing 为每个请求创建一个 span, tracer 为 nil 时使用全局的 TracerProvider
+func Tracing(tracer trace.Tracer) mediator.Behavior {
+	if tracer == nil {
+		tracer = otel.Tracer("github.com/apus-run/sea-kit/mediator")
+	}
+	return func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		name := fmt.Sprintf("%T", req)
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.String("mediator.request", name)),
+		)
+		defer span.End()
+
+		res, err := next(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return res, err
+	}
+}
+
+type txKey struct{}
+
+// Transaction 在事务中执行请求, 处理器返回 error 或 panic 时回滚.
+// 处理器通过 DB 获取事务, 已经在事务中的请求不会开启新的事务.
+// 使用 gormx 时传入同一个 *gorm.DB 即可.
+func Transaction(db *gorm.DB) mediator.Behavior {
+	return func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		if _, ok := TxFromContext(ctx); ok {
+			return next(ctx, req)
+		}
+		var res any
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			res, err = next(context.WithValue(ctx, txKey{}, tx), req)
+			return err
+		})
+		return res, err
+	}
+}
+
+// TxFromContext 获取 Transaction 开启的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// DB 返回 ctx 中的事务, 没有事务时返回 db
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
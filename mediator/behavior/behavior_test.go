@@ -0,0 +1,75 @@
+package behavior
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+type createUser struct {
+	Name string `valid:"required"`
+}
+
+type user struct {
+	ID   int64 `gorm:"primaryKey"`
+	Name string
+}
+
+func TestValidate(t *testing.T) {
+	bus := mediator.NewRequestBus(Validate(nil))
+	require.NoError(t, mediator.RegisterTo[*createUser, int64](bus, mediator.RequestHandlerFunc[*createUser, int64](
+		func(ctx context.Context, req *createUser) (int64, error) {
+			return 1, nil
+		})))
+
+	_, err := mediator.SendTo[*createUser, int64](context.Background(), bus, &createUser{})
+	assert.Error(t, err)
+
+	id, err := mediator.SendTo[*createUser, int64](context.Background(), bus, &createUser{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
+
+func TestTransaction(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&user{}))
+
+	isCommand := func(req any) bool {
+		_, ok := req.(*createUser)
+		return ok
+	}
+	bus := mediator.NewRequestBus(Logging(nil), Tracing(nil), When(isCommand, Transaction(db)))
+	require.NoError(t, mediator.RegisterTo[*createUser, int64](bus, mediator.RequestHandlerFunc[*createUser, int64](
+		func(ctx context.Context, req *createUser) (int64, error) {
+			_, ok := TxFromContext(ctx)
+			assert.True(t, ok)
+			u := &user{Name: req.Name}
+			if err := DB(ctx, db).Create(u).Error; err != nil {
+				return 0, err
+			}
+			if req.Name == "rollback" {
+				return 0, errors.New("rollback")
+			}
+			return u.ID, nil
+		})))
+
+	_, err = mediator.SendTo[*createUser, int64](context.Background(), bus, &createUser{Name: "rollback"})
+	assert.EqualError(t, err, "rollback")
+	id, err := mediator.SendTo[*createUser, int64](context.Background(), bus, &createUser{Name: "foo"})
+	require.NoError(t, err)
+
+	var users []user
+	require.NoError(t, db.Find(&users).Error)
+	assert.Equal(t, []user{{ID: id, Name: "foo"}}, users)
+}
@@ -1,5 +1,7 @@
 package mediator
 
+import "context"
+
 var defaultMediator Mediator
 
 func SetDefault(m Mediator) {
@@ -17,3 +19,24 @@ func Dispatch(ev Event) {
 func Subscribe(hdl EventHandler) {
 	defaultMediator.Subscribe(hdl)
 }
+
+var defaultRequestBus = NewRequestBus()
+
+// SetDefaultRequestBus 设置 Register 和 Send 使用的请求总线
+func SetDefaultRequestBus(b *RequestBus) {
+	defaultRequestBus = b
+}
+
+func DefaultRequestBus() *RequestBus {
+	return defaultRequestBus
+}
+
+// Register 在默认的请求总线上注册 Req 的处理器
+func Register[Req, Resp any](hdl RequestHandler[Req, Resp]) error {
+	return RegisterTo[Req, Resp](defaultRequestBus, hdl)
+}
+
+// Send 通过默认的请求总线发送请求
+func Send[Req, Resp any](ctx context.Context, req Req) (Resp, error) {
+	return SendTo[Req, Resp](ctx, defaultRequestBus, req)
+}
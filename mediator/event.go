@@ -2,6 +2,7 @@ package mediator
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
 )
 
@@ -14,6 +15,19 @@ type (
 		Handle(context.Context, Event)
 	}
 
+	// ErrorEventHandler 处理失败时返回 error 的 EventHandler.
+	// 支持可靠投递的 Mediator 会优先调用 HandleWithError, 并根据返回的 error 决定是否重试.
+	ErrorEventHandler interface {
+		EventHandler
+		HandleWithError(context.Context, Event) error
+	}
+
+	// EventHandlerFunc 将函数适配为 ErrorEventHandler.
+	EventHandlerFunc struct {
+		kinds []EventKind
+		fn    func(context.Context, Event) error
+	}
+
 	// Event 事件接口.
 	Event interface {
 		Kind() EventKind
@@ -30,6 +44,38 @@ type (
 	}
 )
 
+// NewEventHandlerFunc 创建监听 kinds 的 EventHandler.
+func NewEventHandlerFunc(fn func(context.Context, Event) error, kinds ...EventKind) *EventHandlerFunc {
+	return &EventHandlerFunc{kinds: kinds, fn: fn}
+}
+
+func (h *EventHandlerFunc) Listening() []EventKind {
+	return h.kinds
+}
+
+func (h *EventHandlerFunc) Handle(ctx context.Context, ev Event) {
+	_ = h.fn(ctx, ev)
+}
+
+func (h *EventHandlerFunc) HandleWithError(ctx context.Context, ev Event) error {
+	return h.fn(ctx, ev)
+}
+
+// HandleEvent 调用 hdl 处理 ev, 优先使用 HandleWithError, handler panic 时返回 error.
+func HandleEvent(ctx context.Context, hdl EventHandler, ev Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mediator: handler panic: %v", r)
+		}
+	}()
+
+	if h, ok := hdl.(ErrorEventHandler); ok {
+		return h.HandleWithError(ctx, ev)
+	}
+	hdl.Handle(ctx, ev)
+	return nil
+}
+
 func NewEventCollection() EventCollection {
 	return &eventCollection{events: make([]Event, 0)}
 }
@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// DeadLetterStore 保存重试结束仍然投递失败的事件
+type DeadLetterStore interface {
+	Save(ctx context.Context, msg *Message) error
+}
+
+// GormDeadLetterStore 把死信写入数据库
+type GormDeadLetterStore struct {
+	db    *gorm.DB
+	table string
+}
+
+// NewDeadLetterStore 创建 GormDeadLetterStore, 默认表名 outbox_dead_letters
+func NewDeadLetterStore(db *gorm.DB, table ...string) *GormDeadLetterStore {
+	s := &GormDeadLetterStore{db: db, table: "outbox_dead_letters"}
+	if len(table) > 0 && table[0] != "" {
+		s.table = table[0]
+	}
+	return s
+}
+
+// AutoMigrate 创建死信表
+func (s *GormDeadLetterStore) AutoMigrate() error {
+	return s.db.Table(s.table).AutoMigrate(&DeadLetter{})
+}
+
+func (s *GormDeadLetterStore) Save(ctx context.Context, msg *Message) error {
+	return s.db.WithContext(ctx).Table(s.table).Save(&DeadLetter{
+		ID:        msg.ID,
+		Kind:      msg.Kind,
+		Payload:   msg.Payload,
+		Attempts:  msg.Attempts,
+		LastError: msg.LastError,
+		CreatedAt: msg.CreatedAt,
+	}).Error
+}
+
+// Find 按 id 倒序查询死信
+func (s *GormDeadLetterStore) Find(ctx context.Context, limit int) ([]DeadLetter, error) {
+	var res []DeadLetter
+	err := s.db.WithContext(ctx).Table(s.table).Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
@@ -0,0 +1,25 @@
+package outbox
+
+// Message 发件箱中的一条事件
+type Message struct {
+	ID      int64  `gorm:"primaryKey;autoIncrement"`
+	Kind    string `gorm:"type:varchar(255)"`
+	Payload []byte
+	// Attempts 已投递的次数
+	Attempts int
+	// NextAt 下一次投递的毫秒时间戳
+	NextAt    int64 `gorm:"index"`
+	LastError string
+	CreatedAt int64 `gorm:"autoCreateTime:milli"`
+}
+
+// DeadLetter 多次投递失败的事件
+type DeadLetter struct {
+	ID        int64  `gorm:"primaryKey"`
+	Kind      string `gorm:"type:varchar(255);index"`
+	Payload   []byte
+	Attempts  int
+	LastError string
+	CreatedAt int64
+	FailedAt  int64 `gorm:"autoCreateTime:milli"`
+}
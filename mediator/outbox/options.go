@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/apus-run/sea-kit/encoding"
+	"github.com/apus-run/sea-kit/encoding/json"
+	"github.com/apus-run/sea-kit/mediator"
+	"github.com/apus-run/sea-kit/retry"
+)
+
+// Option 发件箱配置
+type Option func(*options)
+
+type options struct {
+	// table 发件箱表名
+	table string
+	// codec 事件的编解码方式
+	codec encoding.Codec
+	// deadLetters 死信存储, 默认写入同库的 outbox_dead_letters 表
+	deadLetters DeadLetterStore
+	// retry 为每个事件创建重试策略, 策略结束后事件进入死信
+	retry func() retry.Strategy
+	// interval 轮询发件箱的间隔
+	interval time.Duration
+	// batchSize 每次轮询最多投递的事件数
+	batchSize int
+	// lease 事件被某个 relay 取走后, 其他 relay 至少等待多久才能再次取走
+	lease time.Duration
+	// timeout 单个事件处理的超时时间, 为 0 时不超时
+	timeout time.Duration
+	// orphanEventHandler 处理没有订阅者的事件
+	orphanEventHandler func(mediator.Event)
+}
+
+// WithTable 设置发件箱表名, 默认 outbox_messages
+func WithTable(table string) Option {
+	return func(o *options) {
+		if table == "" {
+			return
+		}
+		o.table = table
+	}
+}
+
+// WithCodec 设置事件的编解码方式, 默认 json
+func WithCodec(codec encoding.Codec) Option {
+	return func(o *options) {
+		if codec == nil {
+			return
+		}
+		o.codec = codec
+	}
+}
+
+// WithDeadLetterStore 设置死信存储
+func WithDeadLetterStore(store DeadLetterStore) Option {
+	return func(o *options) {
+		if store == nil {
+			return
+		}
+		o.deadLetters = store
+	}
+}
+
+// WithRetry 设置重试策略, fn 每个事件调用一次
+func WithRetry(fn func() retry.Strategy) Option {
+	return func(o *options) {
+		if fn == nil {
+			return
+		}
+		o.retry = fn
+	}
+}
+
+// WithInterval 设置轮询间隔
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			return
+		}
+		o.interval = d
+	}
+}
+
+// WithBatchSize 设置每次轮询最多投递的事件数
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			return
+		}
+		o.batchSize = n
+	}
+}
+
+// WithLease 设置事件被取走后的租约时间, 应大于事件的处理时间
+func WithLease(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			return
+		}
+		o.lease = d
+	}
+}
+
+// WithTimeout 设置单个事件处理的超时时间
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithOrphanEventHandler 设置没有订阅者的事件的处理函数, 未设置时直接丢弃
+func WithOrphanEventHandler(fn func(mediator.Event)) Option {
+	return func(o *options) {
+		o.orphanEventHandler = fn
+	}
+}
+
+// DefaultOptions .
+func DefaultOptions() *options {
+	return &options{
+		table: "outbox_messages",
+		codec: encoding.GetCodec(json.Name),
+		retry: func() retry.Strategy {
+			s, _ := retry.NewExponentialBackoffRetryStrategy(time.Second, time.Minute, 10)
+			return s
+		},
+		interval:  time.Second,
+		batchSize: 100,
+		lease:     time.Minute,
+	}
+}
+
+func Apply(opts ...Option) *options {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
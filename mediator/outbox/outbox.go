@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+// ErrUnregisteredEvent 事件类型没有通过 Register 注册, 无法从发件箱中解码
+var ErrUnregisteredEvent = errors.New("outbox: unregistered event kind")
+
+var (
+	_ mediator.Mediator = (*OutboxMediator)(nil)
+	_ mediator.Mediator = (*TxMediator)(nil)
+)
+
+// OutboxMediator 基于发件箱的 Mediator.
+// Dispatch 只把事件写入发件箱表, 由 Run 启动的 relay 读取并投递给订阅者;
+// 投递失败的事件按重试策略重新投递, 重试结束后进入死信.
+// 事件至少被投递一次, 订阅者需要自己保证幂等.
+type OutboxMediator struct {
+	db   *gorm.DB
+	opts *options
+
+	mu        sync.RWMutex
+	handlers  map[mediator.EventKind][]mediator.EventHandler
+	factories map[mediator.EventKind]func() mediator.Event
+}
+
+// New 创建 OutboxMediator, 使用前需调用 AutoMigrate 或手动建表
+func New(db *gorm.DB, opts ...Option) *OutboxMediator {
+	o := Apply(opts...)
+	if o.deadLetters == nil {
+		o.deadLetters = NewDeadLetterStore(db)
+	}
+	return &OutboxMediator{
+		db:        db,
+		opts:      o,
+		handlers:  make(map[mediator.EventKind][]mediator.EventHandler),
+		factories: make(map[mediator.EventKind]func() mediator.Event),
+	}
+}
+
+// AutoMigrate 创建发件箱表, 使用默认的死信存储时同时创建死信表
+func (m *OutboxMediator) AutoMigrate() error {
+	if err := m.db.Table(m.opts.table).AutoMigrate(&Message{}); err != nil {
+		return err
+	}
+	if s, ok := m.opts.deadLetters.(*GormDeadLetterStore); ok {
+		return s.AutoMigrate()
+	}
+	return nil
+}
+
+// Register 注册事件类型, factory 需返回事件的指针, relay 用它解码发件箱中的事件
+func (m *OutboxMediator) Register(factory func() mediator.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.factories[factory().Kind()] = factory
+}
+
+func (m *OutboxMediator) Subscribe(hdl mediator.EventHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, kind := range hdl.Listening() {
+		m.handlers[kind] = append(m.handlers[kind], hdl)
+	}
+}
+
+// Dispatch 在事务外写入发件箱, 写入失败时只记录日志. 需要和业务数据一起提交时使用 WithTx 或 Save
+func (m *OutboxMediator) Dispatch(ev mediator.Event) {
+	if err := m.Save(context.Background(), m.db, ev); err != nil {
+		slog.Default().Error("outbox dispatch", slog.String("kind", string(ev.Kind())), slog.Any("err", err))
+	}
+}
+
+// Save 在 tx 中写入发件箱, 事件随 tx 一起提交或回滚
+func (m *OutboxMediator) Save(ctx context.Context, tx *gorm.DB, events ...mediator.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	msgs := make([]*Message, 0, len(events))
+	for _, ev := range events {
+		payload, err := m.opts.codec.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("outbox: marshal %s: %w", ev.Kind(), err)
+		}
+		msgs = append(msgs, &Message{
+			Kind:    string(ev.Kind()),
+			Payload: payload,
+			NextAt:  now,
+		})
+	}
+	return tx.WithContext(ctx).Table(m.opts.table).Create(&msgs).Error
+}
+
+// WithTx 返回写入 tx 的 Mediator, 可以传给 EventCollection.Raise.
+// 使用 gormx 的事务时传入 Transaction.DB()
+func (m *OutboxMediator) WithTx(tx *gorm.DB) *TxMediator {
+	return &TxMediator{outbox: m, tx: tx}
+}
+
+func (m *OutboxMediator) WithOrphanEventHandler(fn func(mediator.Event)) {
+	m.opts.orphanEventHandler = fn
+}
+
+// TxMediator 把事件写入事务中的发件箱
+type TxMediator struct {
+	outbox *OutboxMediator
+	tx     *gorm.DB
+	err    error
+}
+
+func (t *TxMediator) Dispatch(ev mediator.Event) {
+	if t.err != nil {
+		return
+	}
+	t.err = t.outbox.Save(t.tx.Statement.Context, t.tx, ev)
+}
+
+func (t *TxMediator) Subscribe(hdl mediator.EventHandler) {
+	t.outbox.Subscribe(hdl)
+}
+
+// Err 返回第一次写入失败的错误, 不为 nil 时应回滚事务
+func (t *TxMediator) Err() error {
+	return t.err
+}
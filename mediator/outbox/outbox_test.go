@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/apus-run/sea-kit/mediator"
+	"github.com/apus-run/sea-kit/retry"
+)
+
+type orderCreated struct {
+	OrderID int64 `json:"order_id"`
+}
+
+func (e *orderCreated) Kind() mediator.EventKind {
+	return "order-created"
+}
+
+func newOutbox(t *testing.T, opts ...Option) (*OutboxMediator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	m := New(db, opts...)
+	require.NoError(t, m.AutoMigrate())
+	m.Register(func() mediator.Event { return &orderCreated{} })
+	return m, db
+}
+
+func count(t *testing.T, db *gorm.DB, table string) int64 {
+	var n int64
+	require.NoError(t, db.Table(table).Count(&n).Error)
+	return n
+}
+
+func TestOutboxMediator_WithTx(t *testing.T) {
+	m, db := newOutbox(t)
+
+	var got []int64
+	m.Subscribe(mediator.NewEventHandlerFunc(func(ctx context.Context, ev mediator.Event) error {
+		got = append(got, ev.(*orderCreated).OrderID)
+		return nil
+	}, "order-created"))
+
+	// 回滚的事务不会投递事件
+	_ = db.Transaction(func(tx *gorm.DB) error {
+		events := mediator.NewEventCollection()
+		events.Add(&orderCreated{OrderID: 1})
+		txm := m.WithTx(tx)
+		events.Raise(txm)
+		require.NoError(t, txm.Err())
+		return errors.New("rollback")
+	})
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return m.Save(context.Background(), tx, &orderCreated{OrderID: 2})
+	}))
+	assert.Equal(t, int64(1), count(t, db, "outbox_messages"))
+
+	n, err := m.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{2}, got)
+	assert.Equal(t, int64(0), count(t, db, "outbox_messages"))
+}
+
+func TestOutboxMediator_Retry(t *testing.T) {
+	m, db := newOutbox(t, WithRetry(func() retry.Strategy {
+		s, _ := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 2)
+		return s
+	}))
+
+	var calls int32
+	m.Subscribe(mediator.NewEventHandlerFunc(func(ctx context.Context, ev mediator.Event) error {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return errors.New("temporary")
+		}
+		return nil
+	}, "order-created"))
+	m.Dispatch(&orderCreated{OrderID: 1})
+
+	_, err := m.RelayOnce(context.Background())
+	require.NoError(t, err)
+	var msg Message
+	require.NoError(t, db.Table("outbox_messages").First(&msg).Error)
+	assert.Equal(t, 1, msg.Attempts)
+	assert.Equal(t, "temporary", msg.LastError)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = m.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, int64(0), count(t, db, "outbox_messages"))
+	assert.Equal(t, int64(0), count(t, db, "outbox_dead_letters"))
+}
+
+func TestOutboxMediator_DeadLetter(t *testing.T) {
+	m, db := newOutbox(t, WithRetry(func() retry.Strategy {
+		s, _ := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 1)
+		return s
+	}))
+
+	m.Subscribe(mediator.NewEventHandlerFunc(func(ctx context.Context, ev mediator.Event) error {
+		panic("boom")
+	}, "order-created"))
+	m.Dispatch(&orderCreated{OrderID: 1})
+
+	for i := 0; i < 2; i++ {
+		_, err := m.RelayOnce(context.Background())
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int64(0), count(t, db, "outbox_messages"))
+
+	letters, err := NewDeadLetterStore(db).Find(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, 2, letters[0].Attempts)
+	assert.Contains(t, letters[0].LastError, "boom")
+}
+
+func TestOutboxMediator_Unregistered(t *testing.T) {
+	m, db := newOutbox(t)
+	require.NoError(t, db.Table("outbox_messages").Create(&Message{Kind: "unknown", Payload: []byte("{}")}).Error)
+
+	_, err := m.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count(t, db, "outbox_messages"))
+	assert.Equal(t, int64(1), count(t, db, "outbox_dead_letters"))
+}
+
+func TestOutboxMediator_Claim(t *testing.T) {
+	m, _ := newOutbox(t)
+	m.Dispatch(&orderCreated{OrderID: 1})
+
+	var msgs []*Message
+	require.NoError(t, m.db.Table("outbox_messages").Find(&msgs).Error)
+	require.Len(t, msgs, 1)
+	stale := *msgs[0]
+
+	ok, err := m.claim(context.Background(), msgs[0], time.Now().UnixMilli())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	// 其他 relay 持有的旧 next_at 无法再次取走
+	ok, err = m.claim(context.Background(), &stale, time.Now().UnixMilli())
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+// Run 轮询发件箱并投递事件, 直到 ctx 结束
+func (m *OutboxMediator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.interval)
+	defer ticker.Stop()
+	for {
+		n, err := m.RelayOnce(ctx)
+		if err != nil {
+			slog.Default().ErrorContext(ctx, "outbox relay", slog.Any("err", err))
+		}
+		// 取满一批时说明还有积压, 不等待直接继续
+		if err == nil && n >= m.opts.batchSize {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 投递一批到期的事件, 返回取到的事件数
+func (m *OutboxMediator) RelayOnce(ctx context.Context) (int, error) {
+	var msgs []*Message
+	now := time.Now().UnixMilli()
+	err := m.db.WithContext(ctx).Table(m.opts.table).
+		Where("next_at <= ?", now).
+		Order("id").
+		Limit(m.opts.batchSize).
+		Find(&msgs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, msg := range msgs {
+		claimed, err := m.claim(ctx, msg, now)
+		if err != nil {
+			return len(msgs), err
+		}
+		if !claimed {
+			continue
+		}
+		if err = m.deliver(ctx, msg); err != nil {
+			return len(msgs), err
+		}
+	}
+	return len(msgs), nil
+}
+
+// claim 延后事件的投递时间, 防止多个 relay 同时投递同一个事件
+func (m *OutboxMediator) claim(ctx context.Context, msg *Message, now int64) (bool, error) {
+	leaseAt := now + m.opts.lease.Milliseconds()
+	res := m.db.WithContext(ctx).Table(m.opts.table).
+		Where("id = ? AND next_at = ?", msg.ID, msg.NextAt).
+		Update("next_at", leaseAt)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	msg.NextAt = leaseAt
+	return res.RowsAffected == 1, nil
+}
+
+func (m *OutboxMediator) deliver(ctx context.Context, msg *Message) error {
+	ev, err := m.decode(msg)
+	if err != nil {
+		msg.Attempts++
+		msg.LastError = err.Error()
+		return m.bury(ctx, msg)
+	}
+
+	m.mu.RLock()
+	handlers := m.handlers[ev.Kind()]
+	m.mu.RUnlock()
+	if len(handlers) == 0 {
+		if m.opts.orphanEventHandler != nil {
+			m.opts.orphanEventHandler(ev)
+		}
+		return m.delete(ctx, msg)
+	}
+
+	for _, hdl := range handlers {
+		if err = m.handle(ctx, hdl, ev); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		return m.delete(ctx, msg)
+	}
+	return m.fail(ctx, msg, err)
+}
+
+func (m *OutboxMediator) handle(ctx context.Context, hdl mediator.EventHandler, ev mediator.Event) error {
+	if m.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.opts.timeout)
+		defer cancel()
+	}
+	return mediator.HandleEvent(ctx, hdl, ev)
+}
+
+// fail 按重试策略重新安排投递, 重试结束后转入死信
+func (m *OutboxMediator) fail(ctx context.Context, msg *Message, cause error) error {
+	msg.Attempts++
+	msg.LastError = cause.Error()
+
+	var (
+		d  time.Duration
+		ok bool
+	)
+	strategy := m.opts.retry()
+	for i := 0; i < msg.Attempts; i++ {
+		if d, ok = strategy.Next(); !ok {
+			break
+		}
+	}
+	if !ok {
+		return m.bury(ctx, msg)
+	}
+
+	return m.db.WithContext(ctx).Table(m.opts.table).
+		Where("id = ?", msg.ID).
+		Updates(map[string]any{
+			"attempts":   msg.Attempts,
+			"next_at":    time.Now().Add(d).UnixMilli(),
+			"last_error": msg.LastError,
+		}).Error
+}
+
+// bury 先写入死信再删除, 写入死信失败时事件留在发件箱等待租约到期后再次投递
+func (m *OutboxMediator) bury(ctx context.Context, msg *Message) error {
+	if err := m.opts.deadLetters.Save(ctx, msg); err != nil {
+		return err
+	}
+	return m.delete(ctx, msg)
+}
+
+func (m *OutboxMediator) delete(ctx context.Context, msg *Message) error {
+	return m.db.WithContext(ctx).Table(m.opts.table).Where("id = ?", msg.ID).Delete(&Message{}).Error
+}
+
+func (m *OutboxMediator) decode(msg *Message) (mediator.Event, error) {
+	m.mu.RLock()
+	factory, ok := m.factories[mediator.EventKind(msg.Kind)]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnregisteredEvent, msg.Kind)
+	}
+
+	ev := factory()
+	if reflect.TypeOf(ev).Kind() != reflect.Pointer {
+		return nil, errors.New("outbox: factory must return a pointer")
+	}
+	if err := m.opts.codec.Unmarshal(msg.Payload, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
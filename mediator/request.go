@@ -0,0 +1,121 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrHandlerNotFound 请求类型没有注册处理器
+	ErrHandlerNotFound = errors.New("mediator: request handler not found")
+	// ErrHandlerExists 请求类型已经注册过处理器
+	ErrHandlerExists = errors.New("mediator: request handler already registered")
+	// ErrResponseType 处理器返回的响应类型与 Send 期望的不一致
+	ErrResponseType = errors.New("mediator: unexpected response type")
+)
+
+type (
+	// RequestHandler 处理命令或查询, 每种请求类型只能有一个处理器.
+	RequestHandler[Req, Resp any] interface {
+		Handle(context.Context, Req) (Resp, error)
+	}
+
+	// RequestHandlerFunc 将函数适配为 RequestHandler.
+	RequestHandlerFunc[Req, Resp any] func(context.Context, Req) (Resp, error)
+
+	// Next 调用管道中的下一个 Behavior, 最后一个是请求的处理器.
+	Next func(ctx context.Context, req any) (any, error)
+
+	// Behavior 包裹在每次 Send 外层的处理逻辑, 如日志, 校验, 链路追踪, 事务等.
+	// 按注册顺序由外到内执行, 不调用 next 即可中断请求.
+	Behavior func(ctx context.Context, req any, next Next) (any, error)
+
+	// RequestBus 请求总线, 保存请求处理器和 Behavior 管道.
+	RequestBus struct {
+		mu        sync.RWMutex
+		handlers  map[reflect.Type]Next
+		behaviors []Behavior
+	}
+)
+
+func (f RequestHandlerFunc[Req, Resp]) Handle(ctx context.Context, req Req) (Resp, error) {
+	return f(ctx, req)
+}
+
+// NewRequestBus 创建请求总线
+func NewRequestBus(behaviors ...Behavior) *RequestBus {
+	return &RequestBus{
+		handlers:  make(map[reflect.Type]Next),
+		behaviors: behaviors,
+	}
+}
+
+// Use 追加 Behavior, 只对之后的 Send 生效
+func (b *RequestBus) Use(behaviors ...Behavior) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.behaviors = append(b.behaviors, behaviors...)
+}
+
+// RegisterTo 在 b 上注册 Req 的处理器
+func RegisterTo[Req, Resp any](b *RequestBus, hdl RequestHandler[Req, Resp]) error {
+	typ := reflect.TypeOf((*Req)(nil)).Elem()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.handlers[typ]; ok {
+		return fmt.Errorf("%w: %s", ErrHandlerExists, typ)
+	}
+	b.handlers[typ] = func(ctx context.Context, req any) (any, error) {
+		r, ok := req.(Req)
+		if !ok {
+			return nil, fmt.Errorf("mediator: unexpected request type %T, want %s", req, typ)
+		}
+		return hdl.Handle(ctx, r)
+	}
+	return nil
+}
+
+// SendTo 通过 b 发送请求, 依次经过所有 Behavior 后交给 Req 的处理器
+func SendTo[Req, Resp any](ctx context.Context, b *RequestBus, req Req) (Resp, error) {
+	var zero Resp
+	typ := reflect.TypeOf((*Req)(nil)).Elem()
+
+	b.mu.RLock()
+	hdl, ok := b.handlers[typ]
+	behaviors := b.behaviors
+	b.mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrHandlerNotFound, typ)
+	}
+
+	next := hdl
+	for i := len(behaviors) - 1; i >= 0; i-- {
+		next = wrap(behaviors[i], next)
+	}
+
+	res, err := next(ctx, req)
+	if err != nil {
+		if r, ok := res.(Resp); ok {
+			return r, err
+		}
+		return zero, err
+	}
+	if res == nil {
+		return zero, nil
+	}
+	r, ok := res.(Resp)
+	if !ok {
+		return zero, fmt.Errorf("%w: %T", ErrResponseType, res)
+	}
+	return r, nil
+}
+
+func wrap(b Behavior, next Next) Next {
+	return func(ctx context.Context, req any) (any, error) {
+		return b(ctx, req, next)
+	}
+}
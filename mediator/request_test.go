@@ -0,0 +1,80 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+type createUser struct {
+	Name string
+}
+
+type getUser struct {
+	ID int64
+}
+
+func TestSend(t *testing.T) {
+	bus := mediator.NewRequestBus()
+	var steps []string
+	bus.Use(
+		func(ctx context.Context, req any, next mediator.Next) (any, error) {
+			steps = append(steps, "outer")
+			return next(ctx, req)
+		},
+		func(ctx context.Context, req any, next mediator.Next) (any, error) {
+			steps = append(steps, "inner")
+			return next(ctx, req)
+		},
+	)
+
+	require.NoError(t, mediator.RegisterTo[createUser, int64](bus, mediator.RequestHandlerFunc[createUser, int64](
+		func(ctx context.Context, req createUser) (int64, error) {
+			steps = append(steps, "handler")
+			return 1, nil
+		})))
+	require.NoError(t, mediator.RegisterTo[*getUser, string](bus, mediator.RequestHandlerFunc[*getUser, string](
+		func(ctx context.Context, req *getUser) (string, error) {
+			return "", errors.New("not found")
+		})))
+
+	id, err := mediator.SendTo[createUser, int64](context.Background(), bus, createUser{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, []string{"outer", "inner", "handler"}, steps)
+
+	_, err = mediator.SendTo[*getUser, string](context.Background(), bus, &getUser{ID: 1})
+	assert.EqualError(t, err, "not found")
+
+	// 每种请求只能有一个处理器
+	err = mediator.RegisterTo[createUser, int64](bus, mediator.RequestHandlerFunc[createUser, int64](
+		func(ctx context.Context, req createUser) (int64, error) { return 0, nil }))
+	assert.ErrorIs(t, err, mediator.ErrHandlerExists)
+
+	_, err = mediator.SendTo[getUser, string](context.Background(), bus, getUser{})
+	assert.ErrorIs(t, err, mediator.ErrHandlerNotFound)
+
+	_, err = mediator.SendTo[createUser, string](context.Background(), bus, createUser{})
+	assert.ErrorIs(t, err, mediator.ErrResponseType)
+}
+
+func TestSend_BehaviorShortCircuit(t *testing.T) {
+	bus := mediator.NewRequestBus(func(ctx context.Context, req any, next mediator.Next) (any, error) {
+		return nil, errors.New("denied")
+	})
+	called := false
+	require.NoError(t, mediator.RegisterTo[createUser, int64](bus, mediator.RequestHandlerFunc[createUser, int64](
+		func(ctx context.Context, req createUser) (int64, error) {
+			called = true
+			return 1, nil
+		})))
+
+	_, err := mediator.SendTo[createUser, int64](context.Background(), bus, createUser{})
+	assert.EqualError(t, err, "denied")
+	assert.False(t, called)
+}
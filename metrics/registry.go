@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 )
@@ -20,3 +22,18 @@ func init() {
 		collectors.NewGoCollector(),
 	)
 }
+
+// Register 把 c 注册到 Registry, 由 Serve 提供的 /metrics 暴露.
+// 已经注册过同名的指标时返回已有的指标, 多个实例可以共用同一组指标
+func Register[T prometheus.Collector](c T) (T, error) {
+	if err := Registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
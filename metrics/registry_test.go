@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	opts := prometheus.CounterOpts{Name: "test_register_total", Help: "test"}
+	c1, err := Register(prometheus.NewCounterVec(opts, []string{"job"}))
+	assert.NoError(t, err)
+	defer Registry.Unregister(c1)
+
+	// 同名的指标返回已经注册的指标
+	c2, err := Register(prometheus.NewCounterVec(opts, []string{"job"}))
+	assert.NoError(t, err)
+	assert.Same(t, c1, c2)
+
+	// 注册到 Serve 使用的 Registry
+	c1.WithLabelValues("a").Inc()
+	mfs, err := Registry.Gather()
+	assert.NoError(t, err)
+	found := false
+	for _, mf := range mfs {
+		found = found || mf.GetName() == "test_register_total"
+	}
+	assert.True(t, found)
+
+	// 同名但类型不同时返回错误
+	_, err = Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_register_total", Help: "test"}, []string{"job"}))
+	assert.Error(t, err)
+}
@@ -2,13 +2,34 @@ module github.com/apus-run/sea-kit/mq
 
 go 1.21
 
-require github.com/IBM/sarama v1.42.1
+require (
+	github.com/IBM/sarama v1.42.1
+	github.com/apus-run/sea-kit/encoding v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/idempotent v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/mediator v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/metrics v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/retry v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/trace v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/zlog v0.0.0-00010101000000-000000000000
+	github.com/google/uuid v1.6.0
+	github.com/prometheus/client_golang v1.17.0
+	github.com/redis/go-redis/v9 v9.4.0
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.22.0
+	go.opentelemetry.io/otel/trace v1.22.0
+)
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/eapache/go-resiliency v1.4.0 // indirect
 	github.com/eapache/go-xerial-snappy v0.0.0-20230731223053-c322873962e3 // indirect
 	github.com/eapache/queue v1.1.0 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/hashicorp/errwrap v1.0.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
@@ -19,12 +40,35 @@ require (
 	github.com/jcmturner/gokrb5/v8 v8.4.4 // indirect
 	github.com/jcmturner/rpc/v2 v2.0.3 // indirect
 	github.com/klauspost/compress v1.16.7 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
+	github.com/natefinch/lumberjack v2.0.0+incompatible // indirect
 	github.com/pierrec/lz4/v4 v4.1.18 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_model v0.4.1-0.20230718164431-9a2bf3000d16 // indirect
+	github.com/prometheus/common v0.44.0 // indirect
+	github.com/prometheus/procfs v0.11.1 // indirect
 	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475 // indirect
+	go.opentelemetry.io/otel/metric v1.22.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.26.0 // indirect
 	golang.org/x/crypto v0.14.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace (
+	github.com/apus-run/sea-kit/encoding => ../encoding
+	github.com/apus-run/sea-kit/idempotent => ../idempotent
+	github.com/apus-run/sea-kit/log => ../log
+	github.com/apus-run/sea-kit/mediator => ../mediator
+	github.com/apus-run/sea-kit/metrics => ../metrics
+	github.com/apus-run/sea-kit/prof => ../prof
+	github.com/apus-run/sea-kit/retry => ../retry
+	github.com/apus-run/sea-kit/tls => ../tls
+	github.com/apus-run/sea-kit/trace => ../trace
+	github.com/apus-run/sea-kit/utils => ../utils
+	github.com/apus-run/sea-kit/validator => ../validator
 	github.com/apus-run/sea-kit/zlog => ../zlog
-)
\ No newline at end of file
+)
@@ -2,7 +2,7 @@ package kafka
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"github.com/nacos-group/nacos-sdk-go/common/logger"
 	"log"
 	"os"
@@ -15,7 +15,7 @@ type Consumer struct {
 	group   sarama.ConsumerGroup
 	topics  []string
 	groupID string
-	handler sarama.ConsumerGroupHandler
+	handler *ConsumerGroupHandler
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -49,30 +49,28 @@ func NewConsumer(logger zlog.Logger, config *sarama.Config, topic string, groupI
 	}
 }
 
+// Consume 消费消息直到 Close 被调用, 每次 rebalance 后重新加入消费者组
 func (c *Consumer) Consume() error {
 	// Track errors
 	go func() {
 		for err := range c.group.Errors() {
-			fmt.Println("ERROR", err)
+			logger.Errorf("[Kafka] Consume err: %s", err.Error())
 		}
 	}()
 
-	// Iterate over consumer sessions.
-	ctx := context.Background()
 	for {
-		select {
-		case <-c.ctx.Done():
-			err := c.group.Close()
-			logger.Info("[Kafka] Consume ctx done")
-			return err
-		default:
-			if err := c.group.Consume(ctx, c.topics, c.handler); err != nil {
-				logger.Errorf("[Kafka] Consume err: %s", err.Error())
-
-				return err
+		if err := c.group.Consume(c.ctx, c.topics, c.handler); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
 			}
-			return nil
+			logger.Errorf("[Kafka] Consume err: %s", err.Error())
+			return err
+		}
+		if c.ctx.Err() != nil {
+			logger.Info("[Kafka] Consume ctx done")
+			return c.group.Close()
 		}
+		c.handler.waitRetry(c.ctx)
 	}
 }
 
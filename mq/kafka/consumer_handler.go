@@ -1,38 +1,242 @@
 package kafka
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/apus-run/sea-kit/retry"
+)
+
+const (
+	// HeaderRetryCount 消息被转发到重试 topic 的次数
+	HeaderRetryCount = "x-retry-count"
+	// HeaderOriginTopic 转发前消息所在的 topic
+	HeaderOriginTopic = "x-origin-topic"
+	// HeaderOriginPartition 转发前消息所在的分区
+	HeaderOriginPartition = "x-origin-partition"
+	// HeaderOriginOffset 转发前消息的 offset
+	HeaderOriginOffset = "x-origin-offset"
+	// HeaderError 最后一次处理失败的原因
+	HeaderError = "x-error"
 )
 
-// ConsumerGroupHandler represents the sarama consumer group
-type ConsumerGroupHandler struct{}
+// HandlerOption ConsumerGroupHandler 配置
+type HandlerOption func(*ConsumerGroupHandler)
+
+// WithRetry 设置进程内重试的策略, fn 每条消息调用一次, 默认不重试
+func WithRetry(fn func() retry.Strategy) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.retry = fn
+	}
+}
+
+// WithRetryTopic 进程内重试失败后, 把消息转发到重试 topic, 最多转发 maxRetries 次.
+// 重试 topic 需要由同一个 handler 或其他消费者订阅
+func WithRetryTopic(topic string, maxRetries int) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.retryTopic = topic
+		h.maxRetries = maxRetries
+	}
+}
+
+// WithDeadLetterTopic 重试全部失败后, 把消息转发到死信 topic
+func WithDeadLetterTopic(topic string) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.deadLetterTopic = topic
+	}
+}
+
+// WithRoutingProducer 设置转发到重试 topic 和死信 topic 使用的生产者
+func WithRoutingProducer(p sarama.SyncProducer) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.producer = p
+	}
+}
+
+// WithConsumeBackoff 设置处理失败且没有可转发的 topic 时, 重新消费前的等待时间, 默认 1s.
+// 会话因处理失败而结束后, 消费者组等待这段时间再重新加入, 避免失败的消息被不停地重复消费
+func WithConsumeBackoff(d time.Duration) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.backoff = d
+	}
+}
+
+// WithPropagator 设置从消息头中提取链路信息的 propagator, 默认使用 otel 全局的 propagator
+func WithPropagator(p propagation.TextMapPropagator) HandlerOption {
+	return func(h *ConsumerGroupHandler) {
+		h.propagator = p
+	}
+}
+
+// ConsumerGroupHandler represents the sarama consumer group.
+// 消息处理成功, 或者转发到重试/死信 topic 成功后才会提交 offset;
+// 没有配置转发时, 处理失败的消息不提交并结束本次会话, 重新消费时从该消息开始.
+type ConsumerGroupHandler struct {
+	handler HandlerFunc
+
+	retry           func() retry.Strategy
+	retryTopic      string
+	maxRetries      int
+	deadLetterTopic string
+	producer        sarama.SyncProducer
+	propagator      propagation.TextMapPropagator
+	backoff         time.Duration
+
+	// failed 本次会话是否因为处理失败而结束
+	failed atomic.Bool
+}
+
+// NewConsumerGroupHandler 创建 ConsumerGroupHandler
+func NewConsumerGroupHandler(handler HandlerFunc, opts ...HandlerOption) *ConsumerGroupHandler {
+	h := &ConsumerGroupHandler{handler: handler, backoff: time.Second}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
 
 // Setup is run before consumer start consuming, is normally used to setup things such as database connections
-func (ConsumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
+func (h *ConsumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
+	if (h.retryTopic != "" || h.deadLetterTopic != "") && h.producer == nil {
+		return errors.New("kafka: routing producer is required by retry or dead letter topic")
+	}
 	return nil
 }
 
 // Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
-func (ConsumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+func (h *ConsumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-// ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages(), here is supposed to be what you want to
-// do with the message. In this example the message will be logged with the topic name, partition and message value.
-func (h ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for msg := range claim.Messages() {
-		fmt.Printf(
-			"Message topic:%q partition:%d offset:%d message: %v\n",
-			msg.Topic, msg.Partition, msg.Offset, string(msg.Value),
-		)
+// ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages().
+func (h *ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if err := h.consume(session.Context(), msg); err != nil {
+				slog.Default().ErrorContext(session.Context(), "kafka consume",
+					slog.String("topic", msg.Topic),
+					slog.Int("partition", int(msg.Partition)),
+					slog.Int64("offset", msg.Offset),
+					slog.Any("err", err))
+				h.failed.Store(true)
+				return err
+			}
+			session.MarkMessage(msg, "")
+		}
+	}
+}
+
+// waitRetry 上一次会话因处理失败而结束时, 等待 backoff 后再重新消费, 正常 rebalance 不等待
+func (h *ConsumerGroupHandler) waitRetry(ctx context.Context) {
+	if h.failed.Swap(false) {
+		sleep(ctx, h.backoff)
+	}
+}
+
+// sleep 等待 d 或者 ctx 结束
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// consume 处理一条消息, 返回 nil 时可以提交 offset
+func (h *ConsumerGroupHandler) consume(ctx context.Context, raw *sarama.ConsumerMessage) error {
+	if h.handler == nil {
+		slog.Default().InfoContext(ctx, "kafka message without handler",
+			slog.String("topic", raw.Topic), slog.Int64("offset", raw.Offset))
+		return nil
+	}
+
+	msg := newMessage(raw)
+	propagator := h.propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	ctx = propagator.Extract(ctx, headerCarrier(msg.Headers))
+
+	err := h.handle(ctx, msg)
+	if err == nil {
+		return nil
+	}
+	return h.route(msg, err)
+}
+
+func (h *ConsumerGroupHandler) handle(ctx context.Context, msg *Message) error {
+	var strategy retry.Strategy
+	if h.retry != nil {
+		strategy = h.retry()
+	}
+	for {
+		err := h.handler(ctx, msg)
+		if err == nil || strategy == nil {
+			return err
+		}
+		d, ok := strategy.Next()
+		if !ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(d):
+		}
+	}
+}
 
-		session.MarkMessage(msg, "")
+// route 把处理失败的消息转发到重试 topic 或死信 topic, 没有可转发的 topic 时返回 cause
+func (h *ConsumerGroupHandler) route(msg *Message, cause error) error {
+	retries, _ := strconv.Atoi(msg.Headers[HeaderRetryCount])
+	topic := h.deadLetterTopic
+	if h.retryTopic != "" && retries < h.maxRetries {
+		topic = h.retryTopic
+		retries++
+	}
+	if topic == "" {
+		return cause
+	}
 
-		log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
+	headers := make(map[string]string, len(msg.Headers)+5)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	// 已经转发过的消息保留最初的来源
+	if _, ok := headers[HeaderOriginTopic]; !ok {
+		headers[HeaderOriginTopic] = msg.Topic
+		headers[HeaderOriginPartition] = strconv.Itoa(int(msg.Partition))
+		headers[HeaderOriginOffset] = strconv.FormatInt(msg.Offset, 10)
 	}
+	headers[HeaderRetryCount] = strconv.Itoa(retries)
+	headers[HeaderError] = cause.Error()
 
+	_, _, err := h.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: toRecordHeaders(headers),
+	})
+	if err != nil {
+		return errors.Join(cause, err)
+	}
 	return nil
 }
@@ -0,0 +1,150 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/apus-run/sea-kit/retry"
+	"github.com/apus-run/sea-kit/trace"
+)
+
+func consumeOne(t *testing.T, h *ConsumerGroupHandler, msg *sarama.ConsumerMessage) (*testSession, error) {
+	msgs := make(chan *sarama.ConsumerMessage, 1)
+	msgs <- msg
+	close(msgs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	session := &testSession{ctx: ctx}
+	require.NoError(t, h.Setup(session))
+	return session, h.ConsumeClaim(session, &testClaim{msgs: msgs})
+}
+
+func TestConsumerGroupHandler_Retry(t *testing.T) {
+	calls := 0
+	h := NewConsumerGroupHandler(func(ctx context.Context, msg *Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}, WithRetry(func() retry.Strategy {
+		s, _ := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 3)
+		return s
+	}))
+
+	session, err := consumeOne(t, h, &sarama.ConsumerMessage{Topic: "orders", Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int64{1}, session.marked)
+}
+
+func TestConsumerGroupHandler_NoRouting(t *testing.T) {
+	h := NewConsumerGroupHandler(func(ctx context.Context, msg *Message) error {
+		return errors.New("failed")
+	}, WithConsumeBackoff(50*time.Millisecond))
+	session, err := consumeOne(t, h, &sarama.ConsumerMessage{Topic: "orders", Offset: 1})
+	assert.EqualError(t, err, "failed")
+	assert.Empty(t, session.marked)
+
+	// 会话因处理失败而结束时, 等待一段时间再重新消费, 避免空转
+	start := time.Now()
+	h.waitRetry(context.Background())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// 正常 rebalance 后不等待
+	start = time.Now()
+	h.waitRetry(context.Background())
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestConsumerGroupHandler_Route(t *testing.T) {
+	testCases := []struct {
+		name      string
+		headers   []*sarama.RecordHeader
+		wantTopic string
+		wantCount string
+	}{
+		{
+			name:      "retry topic",
+			wantTopic: "orders.retry",
+			wantCount: "1",
+		},
+		{
+			name: "dead letter topic",
+			headers: []*sarama.RecordHeader{
+				{Key: []byte(HeaderRetryCount), Value: []byte("2")},
+				{Key: []byte(HeaderOriginTopic), Value: []byte("orders")},
+			},
+			wantTopic: "orders.dlt",
+			wantCount: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			producer := mocks.NewSyncProducer(t, nil)
+			producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+				headers := make(map[string]string)
+				for _, h := range msg.Headers {
+					headers[string(h.Key)] = string(h.Value)
+				}
+				if msg.Topic != tc.wantTopic {
+					return errors.New("unexpected topic " + msg.Topic)
+				}
+				if headers[HeaderRetryCount] != tc.wantCount {
+					return errors.New("unexpected retry count " + headers[HeaderRetryCount])
+				}
+				if headers[HeaderOriginTopic] != "orders" || headers[HeaderError] != "failed" {
+					return errors.New("unexpected headers")
+				}
+				return nil
+			})
+
+			h := NewConsumerGroupHandler(func(ctx context.Context, msg *Message) error {
+				return errors.New("failed")
+			},
+				WithRetryTopic("orders.retry", 2),
+				WithDeadLetterTopic("orders.dlt"),
+				WithRoutingProducer(producer),
+			)
+			msg := &sarama.ConsumerMessage{Topic: "orders", Offset: 7, Value: []byte("v"), Headers: tc.headers}
+			if tc.wantCount == "2" {
+				msg.Topic = "orders.retry"
+			}
+			session, err := consumeOne(t, h, msg)
+			require.NoError(t, err)
+			assert.Equal(t, []int64{7}, session.marked)
+		})
+	}
+}
+
+func TestConsumerGroupHandler_Trace(t *testing.T) {
+	var traceID string
+	h := NewConsumerGroupHandler(func(ctx context.Context, msg *Message) error {
+		traceID = trace.TraceIdFromContext(ctx)
+		return nil
+	}, WithPropagator(propagation.TraceContext{}))
+
+	_, err := consumeOne(t, h, &sarama.ConsumerMessage{
+		Topic: "orders",
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("traceparent"), Value: []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
+
+func TestConsumerGroupHandler_SetupWithoutProducer(t *testing.T) {
+	h := NewConsumerGroupHandler(nil, WithDeadLetterTopic("orders.dlt"))
+	assert.Error(t, h.Setup(nil))
+}
@@ -0,0 +1,249 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+// HeaderEventKind 消息头中保存事件类型的 key
+const HeaderEventKind = "event-kind"
+
+// KeyedEvent 指定消息 key 的事件, 相同 key 的事件会写入同一个分区以保证顺序
+type KeyedEvent interface {
+	mediator.Event
+	Key() string
+}
+
+var _ mediator.Mediator = (*Mediator)(nil)
+
+// Mediator 基于 kafka 的 Mediator, 用于跨服务传递事件.
+// Dispatch 把事件编码后发送到事件类型对应的 topic; Run 消费订阅的事件并交给 EventHandler 处理,
+// 处理失败时按 WithMaxRetries 重试, 重试全部失败后转发到死信 topic 再提交 offset,
+// 同一事件可能被处理多次, 因此 handler 需要保证幂等.
+type Mediator struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	group    sarama.ConsumerGroup
+	handler  *ConsumerGroupHandler
+	opts     *mediatorOptions
+
+	mu        sync.RWMutex
+	handlers  map[mediator.EventKind][]mediator.EventHandler
+	factories map[mediator.EventKind]func() mediator.Event
+	// kinds topic 上的事件类型, 用于没有 HeaderEventKind 的消息
+	kinds map[string][]mediator.EventKind
+}
+
+// NewMediator 创建 Mediator, groupID 为空时只能发送事件
+func NewMediator(config *sarama.Config, brokers []string, groupID string, opts ...MediatorOption) (*Mediator, error) {
+	if config == nil {
+		config = sarama.NewConfig()
+	}
+	config.Producer.Return.Successes = true
+
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	var group sarama.ConsumerGroup
+	if groupID != "" {
+		if group, err = sarama.NewConsumerGroupFromClient(groupID, client); err != nil {
+			_ = producer.Close()
+			_ = client.Close()
+			return nil, err
+		}
+	}
+	m := newMediator(producer, group, opts...)
+	// 由 client 创建的生产者和消费者组不会关闭 client, 需要单独关闭
+	m.client = client
+	return m, nil
+}
+
+func newMediator(producer sarama.SyncProducer, group sarama.ConsumerGroup, opts ...MediatorOption) *Mediator {
+	o := defaultMediatorOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	m := &Mediator{
+		producer:  producer,
+		group:     group,
+		opts:      o,
+		handlers:  make(map[mediator.EventKind][]mediator.EventHandler),
+		factories: make(map[mediator.EventKind]func() mediator.Event),
+		kinds:     make(map[string][]mediator.EventKind),
+	}
+	handlerOpts := append([]HandlerOption{
+		WithRetry(o.retryStrategy),
+		WithDeadLetterTopic(DefaultDeadLetterTopic),
+		WithRoutingProducer(producer),
+		WithConsumeBackoff(o.retryBackoff),
+	}, o.handlerOpts...)
+	m.handler = NewConsumerGroupHandler(m.handle, handlerOpts...)
+	return m
+}
+
+// Register 注册事件类型, factory 需返回事件的指针, 消费时用它解码消息
+func (m *Mediator) Register(factory func() mediator.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.factories[factory().Kind()] = factory
+}
+
+func (m *Mediator) Subscribe(hdl mediator.EventHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, kind := range hdl.Listening() {
+		if _, ok := m.handlers[kind]; !ok {
+			topic := m.opts.topic(kind)
+			m.kinds[topic] = append(m.kinds[topic], kind)
+		}
+		m.handlers[kind] = append(m.handlers[kind], hdl)
+	}
+}
+
+// Dispatch 发送事件, 发送失败时只记录日志. 需要处理错误时使用 Publish
+func (m *Mediator) Dispatch(ev mediator.Event) {
+	if err := m.Publish(context.Background(), ev); err != nil {
+		slog.Default().Error("kafka mediator dispatch", slog.String("kind", string(ev.Kind())), slog.Any("err", err))
+	}
+}
+
+// Publish 发送事件到事件类型对应的 topic
+func (m *Mediator) Publish(_ context.Context, ev mediator.Event) error {
+	data, err := m.opts.codec.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("kafka mediator: marshal %s: %w", ev.Kind(), err)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: m.opts.topic(ev.Kind()),
+		Value: sarama.ByteEncoder(data),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(HeaderEventKind), Value: []byte(ev.Kind())},
+		},
+	}
+	if ke, ok := ev.(KeyedEvent); ok {
+		msg.Key = sarama.StringEncoder(ke.Key())
+	}
+	_, _, err = m.producer.SendMessage(msg)
+	return err
+}
+
+// Run 消费已订阅事件的 topic, 直到 ctx 结束
+func (m *Mediator) Run(ctx context.Context) error {
+	if m.group == nil {
+		return errors.New("kafka mediator: consumer group is not configured")
+	}
+
+	m.mu.RLock()
+	topics := make([]string, 0, len(m.kinds))
+	for topic := range m.kinds {
+		topics = append(topics, topic)
+	}
+	m.mu.RUnlock()
+	if len(topics) == 0 {
+		return errors.New("kafka mediator: no subscribed events")
+	}
+
+	go func() {
+		for err := range m.group.Errors() {
+			slog.Default().Error("kafka mediator consume", slog.Any("err", err))
+		}
+	}()
+
+	for {
+		// 每次 rebalance 或 ConsumeClaim 返回后都需要重新调用 Consume
+		if err := m.group.Consume(ctx, topics, m.handler); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.handler.waitRetry(ctx)
+	}
+}
+
+// Close 关闭消费者组、生产者以及它们共用的 client
+func (m *Mediator) Close() error {
+	var errs []error
+	if m.group != nil {
+		errs = append(errs, m.group.Close())
+	}
+	errs = append(errs, m.producer.Close())
+	if m.client != nil {
+		errs = append(errs, m.client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// handle 把消息解码为事件并交给订阅的 handler, 返回的错误由 ConsumerGroupHandler 重试或转发到死信 topic
+func (m *Mediator) handle(ctx context.Context, msg *Message) error {
+	kind, ok := m.kindOf(msg)
+	if !ok {
+		// 无法识别的消息重试也不会成功, 直接跳过
+		slog.Default().WarnContext(ctx, "kafka mediator: unknown event", slog.String("topic", msg.Topic))
+		return nil
+	}
+
+	m.mu.RLock()
+	factory, registered := m.factories[kind]
+	handlers := m.handlers[kind]
+	m.mu.RUnlock()
+	if len(handlers) == 0 {
+		return nil
+	}
+	if !registered {
+		return fmt.Errorf("kafka mediator: unregistered event kind %s", kind)
+	}
+
+	ev := factory()
+	if reflect.TypeOf(ev).Kind() != reflect.Pointer {
+		return errors.New("kafka mediator: factory must return a pointer")
+	}
+	if err := m.opts.codec.Unmarshal(msg.Value, ev); err != nil {
+		// 无法解码的消息重试也不会成功, 直接跳过
+		slog.Default().WarnContext(ctx, "kafka mediator: decode event",
+			slog.String("kind", string(kind)), slog.Any("err", err))
+		return nil
+	}
+
+	if m.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.opts.timeout)
+		defer cancel()
+	}
+	for _, hdl := range handlers {
+		if err := mediator.HandleEvent(ctx, hdl, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Mediator) kindOf(msg *Message) (mediator.EventKind, bool) {
+	if kind, ok := msg.Headers[HeaderEventKind]; ok {
+		return mediator.EventKind(kind), true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if kinds := m.kinds[msg.Topic]; len(kinds) == 1 {
+		return kinds[0], true
+	}
+	return "", false
+}
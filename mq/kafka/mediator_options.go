@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/apus-run/sea-kit/encoding"
+	"github.com/apus-run/sea-kit/encoding/json"
+	"github.com/apus-run/sea-kit/mediator"
+	"github.com/apus-run/sea-kit/retry"
+)
+
+// DefaultDeadLetterTopic 默认的死信 topic, 重试全部失败的事件会转发到这里
+const DefaultDeadLetterTopic = "mediator.dead-letter"
+
+// MediatorOption Mediator 配置
+type MediatorOption func(*mediatorOptions)
+
+type mediatorOptions struct {
+	// codec 事件的编解码方式
+	codec encoding.Codec
+	// topic 事件类型到 topic 的映射
+	topic func(mediator.EventKind) string
+	// retryBackoff 处理失败后重试的间隔
+	retryBackoff time.Duration
+	// maxRetries 处理失败后最多重试的次数, 超过后转发到死信 topic
+	maxRetries int32
+	// handlerOpts 消费时 ConsumerGroupHandler 的配置
+	handlerOpts []HandlerOption
+	// timeout 单个事件处理的超时时间, 为 0 时不超时
+	timeout time.Duration
+}
+
+// WithCodec 设置事件的编解码方式, 默认 json
+func WithCodec(codec encoding.Codec) MediatorOption {
+	return func(o *mediatorOptions) {
+		if codec == nil {
+			return
+		}
+		o.codec = codec
+	}
+}
+
+// WithTopicMapper 设置事件类型到 topic 的映射, 默认 topic 与事件类型同名
+func WithTopicMapper(fn func(mediator.EventKind) string) MediatorOption {
+	return func(o *mediatorOptions) {
+		if fn == nil {
+			return
+		}
+		o.topic = fn
+	}
+}
+
+// WithRetryBackoff 设置处理失败后重试的间隔, 默认 1s
+func WithRetryBackoff(d time.Duration) MediatorOption {
+	return func(o *mediatorOptions) {
+		o.retryBackoff = d
+	}
+}
+
+// WithMaxRetries 设置处理失败后最多重试的次数, 默认 3 次.
+// 重试全部失败后事件被转发到死信 topic, 不会阻塞后续事件
+func WithMaxRetries(n int32) MediatorOption {
+	return func(o *mediatorOptions) {
+		o.maxRetries = n
+	}
+}
+
+// WithConsumerOptions 设置消费时 ConsumerGroupHandler 的配置, 会覆盖默认配置,
+// 例如用 WithDeadLetterTopic 替换默认的死信 topic, 用 WithRetryTopic 增加重试 topic
+func WithConsumerOptions(opts ...HandlerOption) MediatorOption {
+	return func(o *mediatorOptions) {
+		o.handlerOpts = append(o.handlerOpts, opts...)
+	}
+}
+
+// WithHandleTimeout 设置单个事件处理的超时时间
+func WithHandleTimeout(d time.Duration) MediatorOption {
+	return func(o *mediatorOptions) {
+		o.timeout = d
+	}
+}
+
+func defaultMediatorOptions() *mediatorOptions {
+	return &mediatorOptions{
+		codec: encoding.GetCodec(json.Name),
+		topic: func(kind mediator.EventKind) string {
+			return string(kind)
+		},
+		retryBackoff: time.Second,
+		maxRetries:   3,
+	}
+}
+
+// retryStrategy 每条消息的重试策略, 间隔为 0 时立即重试
+func (o *mediatorOptions) retryStrategy() retry.Strategy {
+	if o.maxRetries <= 0 {
+		return nil
+	}
+	interval := o.retryBackoff
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	s, err := retry.NewFixedIntervalRetryStrategy(interval, o.maxRetries)
+	if err != nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/mediator"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+}
+
+func (e *orderCreated) Kind() mediator.EventKind {
+	return "order-created"
+}
+
+func (e *orderCreated) Key() string {
+	return e.OrderID
+}
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *testSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	msgs chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.msgs
+}
+
+func TestMediator_Publish(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "orders.order-created" {
+			return errors.New("unexpected topic " + msg.Topic)
+		}
+		key, _ := msg.Key.Encode()
+		if string(key) != "1" {
+			return errors.New("unexpected key " + string(key))
+		}
+		if len(msg.Headers) != 1 || string(msg.Headers[0].Value) != "order-created" {
+			return errors.New("missing event kind header")
+		}
+		return nil
+	})
+
+	m := newMediator(producer, nil, WithTopicMapper(func(kind mediator.EventKind) string {
+		return "orders." + string(kind)
+	}))
+	require.NoError(t, m.Publish(context.Background(), &orderCreated{OrderID: "1"}))
+	require.NoError(t, m.Close())
+}
+
+func TestMediator_ConsumeClaim(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != DefaultDeadLetterTopic {
+			return errors.New("unexpected topic " + msg.Topic)
+		}
+		return nil
+	})
+	m := newMediator(producer, nil, WithRetryBackoff(0), WithMaxRetries(2))
+	m.Register(func() mediator.Event { return &orderCreated{} })
+
+	var (
+		got   []string
+		fails int
+	)
+	m.Subscribe(mediator.NewEventHandlerFunc(func(ctx context.Context, ev mediator.Event) error {
+		id := ev.(*orderCreated).OrderID
+		if id == "fail" {
+			fails++
+			return errors.New("handle failed")
+		}
+		got = append(got, id)
+		return nil
+	}, "order-created"))
+
+	msgs := make(chan *sarama.ConsumerMessage, 4)
+	msgs <- &sarama.ConsumerMessage{Topic: "order-created", Offset: 1, Value: []byte(`{"order_id":"1"}`)}
+	// 无法解码的消息被跳过
+	msgs <- &sarama.ConsumerMessage{Topic: "order-created", Offset: 2, Value: []byte(`{`)}
+	msgs <- &sarama.ConsumerMessage{Topic: "order-created", Offset: 3, Value: []byte(`{"order_id":"fail"}`)}
+	msgs <- &sarama.ConsumerMessage{Topic: "order-created", Offset: 4, Value: []byte(`{"order_id":"4"}`)}
+	close(msgs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	session := &testSession{ctx: ctx}
+	require.NoError(t, m.handler.Setup(session))
+	require.NoError(t, m.handler.ConsumeClaim(session, &testClaim{msgs: msgs}))
+	// 重试全部失败的事件转发到死信 topic 后提交, 不阻塞后续事件
+	assert.Equal(t, 3, fails)
+	assert.Equal(t, []int64{1, 2, 3, 4}, session.marked)
+	assert.Equal(t, []string{"1", "4"}, got)
+}
+
+func TestMediator_ConsumeClaimDeadLetterFailed(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	m := newMediator(producer, nil, WithRetryBackoff(0), WithMaxRetries(0),
+		WithConsumerOptions(WithDeadLetterTopic("orders.dlt"), WithConsumeBackoff(0)))
+	m.Register(func() mediator.Event { return &orderCreated{} })
+	m.Subscribe(mediator.NewEventHandlerFunc(func(ctx context.Context, ev mediator.Event) error {
+		return errors.New("handle failed")
+	}, "order-created"))
+
+	msgs := make(chan *sarama.ConsumerMessage, 1)
+	msgs <- &sarama.ConsumerMessage{Topic: "order-created", Offset: 1, Value: []byte(`{"order_id":"1"}`)}
+	close(msgs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	session := &testSession{ctx: ctx}
+	err := m.handler.ConsumeClaim(session, &testClaim{msgs: msgs})
+	// 转发失败时不提交, 重新消费
+	assert.ErrorIs(t, err, sarama.ErrOutOfBrokers)
+	assert.Empty(t, session.marked)
+}
@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Message 消费到的 kafka 消息
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// HandlerFunc 处理消息, 返回 error 时按重试策略重试
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+func newMessage(msg *sarama.ConsumerMessage) *Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h == nil {
+			continue
+		}
+		headers[string(h.Key)] = string(h.Value)
+	}
+	return &Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Timestamp: msg.Timestamp,
+	}
+}
+
+// headerCarrier 在 kafka 消息头中传递链路信息
+type headerCarrier map[string]string
+
+var _ propagation.TextMapCarrier = headerCarrier(nil)
+
+func (c headerCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func toRecordHeaders(headers map[string]string) []sarama.RecordHeader {
+	res := make([]sarama.RecordHeader, 0, len(headers))
+	for k, v := range headers {
+		res = append(res, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	return res
+}
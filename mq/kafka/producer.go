@@ -1,79 +1,263 @@
 package kafka
 
 import (
-	"log"
-	"os"
-	"os/signal"
+	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
-	"github.com/nacos-group/nacos-sdk-go/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	kitmetrics "github.com/apus-run/sea-kit/metrics"
+	"go.opentelemetry.io/otel"
 )
 
-// Producer kafka producer
+// ErrProducerClosed Producer 已关闭
+var ErrProducerClosed = errors.New("kafka: producer closed")
+
+// ProducerMessage 待发送的消息
+type ProducerMessage struct {
+	// Topic 为空时使用 WithDefaultTopic 设置的 topic
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+
+	// Partition 和 Offset 在发送成功后返回
+	Partition int32
+	Offset    int64
+}
+
+// Callback 异步发送的结果回调, err 为 nil 表示 broker 已确认
+type Callback func(msg *ProducerMessage, err error)
+
+// Producer kafka producer, 可以被多个协程并发调用.
+// Publish 等待 broker 确认后返回, PublishAsync 立即返回并在确认后调用回调.
 type Producer struct {
-	asyncProducer sarama.AsyncProducer
-	topic         string
-	enqueued      int
+	producer sarama.AsyncProducer
+	opts     *producerOptions
+	metrics  *producerMetrics
+
+	// mu 保护 closed, 保证 Close 之后不再写入 Input
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+	done    chan struct{}
 }
 
-// NewProducer create producer
-// nolint
-func NewProducer(config *sarama.Config, logger *log.Logger, topic string, brokers []string) *Producer {
-	sarama.Logger = logger
+type pending struct {
+	msg      *ProducerMessage
+	start    time.Time
+	callback Callback
+}
+
+// NewProducer 创建 Producer
+func NewProducer(config *sarama.Config, brokers []string, opts ...ProducerOption) (*Producer, error) {
+	o := &producerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if config == nil {
+		config = sarama.NewConfig()
+	}
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	if o.batchSize > 0 {
+		config.Producer.Flush.Messages = o.batchSize
+	}
+	if o.batchBytes > 0 {
+		config.Producer.Flush.Bytes = o.batchBytes
+	}
+	if o.linger > 0 {
+		config.Producer.Flush.Frequency = o.linger
+	}
 
-	// Start a new async producer
 	producer, err := sarama.NewAsyncProducer(brokers, config)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	return newProducer(producer, o)
+}
 
-	log.Println("Kafka AsyncProducer up and running!")
-
+func newProducer(producer sarama.AsyncProducer, o *producerOptions) (*Producer, error) {
 	p := &Producer{
-		asyncProducer: producer,
-		topic:         topic,
+		producer: producer,
+		opts:     o,
+		done:     make(chan struct{}),
 	}
+	if o.metrics {
+		m, err := newProducerMetrics(o.namespace, o.subsystem)
+		if err != nil {
+			_ = producer.Close()
+			return nil, err
+		}
+		p.metrics = m
+	}
+	go p.dispatch()
+	return p, nil
+}
 
-	go p.asyncDealMessage()
+// Publish 发送消息并等待 broker 确认
+func (p *Producer) Publish(ctx context.Context, msg *ProducerMessage) error {
+	result := make(chan error, 1)
+	err := p.PublishAsync(ctx, msg, func(_ *ProducerMessage, err error) {
+		result <- err
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-result:
+		return err
+	case <-ctx.Done():
+		// 消息已经进入发送队列, 无法撤回, 结果由回调丢弃
+		return ctx.Err()
+	}
+}
+
+// PublishAsync 把消息放入发送队列, callback 可以为 nil
+func (p *Producer) PublishAsync(ctx context.Context, msg *ProducerMessage, callback Callback) error {
+	topic := msg.Topic
+	if topic == "" {
+		topic = p.opts.topic
+	}
+	if topic == "" {
+		return errors.New("kafka: topic is required")
+	}
 
-	return p
+	headers := make(map[string]string, len(msg.Headers)+2)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	propagator := p.opts.propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	propagator.Inject(ctx, headerCarrier(headers))
+
+	pm := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: toRecordHeaders(headers),
+		Metadata: &pending{
+			msg:      msg,
+			start:    time.Now(),
+			callback: callback,
+		},
+	}
+	if msg.Key != nil {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrProducerClosed
+	}
+	p.pending.Add(1)
+	select {
+	case p.producer.Input() <- pm:
+		return nil
+	case <-ctx.Done():
+		p.pending.Done()
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新消息, 等待队列中的消息发送完成后关闭
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	// AsyncClose 会发送完 Input 中的消息, 并在结束后关闭 Successes 和 Errors
+	p.producer.AsyncClose()
+	<-p.done
+	p.pending.Wait()
+	return nil
 }
 
-func (p *Producer) asyncDealMessage() {
-	for {
+func (p *Producer) dispatch() {
+	defer close(p.done)
+	successes, errs := p.producer.Successes(), p.producer.Errors()
+	for successes != nil || errs != nil {
 		select {
-		case res := <-p.asyncProducer.Successes():
-			logger.Info("push msg success", "topic is", res.Topic, "partition is ", res.Partition, "offset is ", res.Offset)
-		case err := <-p.asyncProducer.Errors():
-			logger.Info("push msg failed", "err is ", err.Error())
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			p.finish(msg, nil)
+		case perr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			p.finish(perr.Msg, perr.Err)
 		}
 	}
 }
 
-// Publish push data to queue
-func (p *Producer) Publish(message string) error {
-	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, os.Interrupt)
+func (p *Producer) finish(msg *sarama.ProducerMessage, err error) {
+	pd, ok := msg.Metadata.(*pending)
+	if !ok {
+		return
+	}
+	defer p.pending.Done()
 
-	for {
-		time.Sleep(5 * time.Second)
-		message := &sarama.ProducerMessage{Topic: p.topic, Value: sarama.StringEncoder(message)}
+	if err == nil {
+		pd.msg.Partition = msg.Partition
+		pd.msg.Offset = msg.Offset
+	}
+	p.metrics.observe(msg.Topic, pd.start, err)
+	if pd.callback != nil {
+		pd.callback(pd.msg, err)
+	}
+}
 
-		select {
-		case p.asyncProducer.Input() <- message:
-			p.enqueued++
-			logger.Infof("New message publish:  %s", message.Value)
-			return nil
-		case <-signals:
-			p.asyncProducer.AsyncClose() // Trigger a shutdown of the producer.
-			logger.Infof("Kafka AsyncProducer finished with %d messages produced.", p.enqueued)
-			return nil
-		}
+type producerMetrics struct {
+	messages *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+func newProducerMetrics(namespace, subsystem string) (*producerMetrics, error) {
+	messages := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "kafka_producer_messages_total",
+		Help:      "Total number of kafka messages published.",
+	}, []string{"topic", "result"})
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "kafka_producer_publish_seconds",
+		Help:      "Latency from publishing a kafka message to its acknowledgement.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	var err error
+	if messages, err = kitmetrics.Register(messages); err != nil {
+		return nil, err
+	}
+	if latency, err = kitmetrics.Register(latency); err != nil {
+		return nil, err
 	}
+	return &producerMetrics{messages: messages, latency: latency}, nil
 }
 
-// Close producer
-func (p *Producer) Close() {
-	p.asyncProducer.Close()
+func (m *producerMetrics) observe(topic string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.messages.WithLabelValues(topic, result).Inc()
+	m.latency.WithLabelValues(topic).Observe(time.Since(start).Seconds())
 }
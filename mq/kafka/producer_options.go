@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ProducerOption Producer 配置
+type ProducerOption func(*producerOptions)
+
+type producerOptions struct {
+	// topic 消息没有指定 topic 时使用的默认 topic
+	topic string
+	// batchSize 攒够多少条消息后发送, 0 表示由 sarama 决定
+	batchSize int
+	// batchBytes 攒够多少字节后发送, 0 表示由 sarama 决定
+	batchBytes int
+	// linger 消息最多等待多久后发送, 0 表示立即发送
+	linger time.Duration
+	// propagator 把链路信息注入到消息头
+	propagator propagation.TextMapPropagator
+	// metrics 是否开启 prometheus 指标
+	metrics   bool
+	namespace string
+	subsystem string
+}
+
+// WithDefaultTopic 设置默认的 topic
+func WithDefaultTopic(topic string) ProducerOption {
+	return func(o *producerOptions) {
+		o.topic = topic
+	}
+}
+
+// WithBatch 设置批量发送, 攒够 size 条消息, bytes 字节或者等待 linger 后发送一批
+func WithBatch(size, bytes int, linger time.Duration) ProducerOption {
+	return func(o *producerOptions) {
+		o.batchSize = size
+		o.batchBytes = bytes
+		o.linger = linger
+	}
+}
+
+// WithProducerPropagator 设置注入链路信息的 propagator, 默认使用 otel 全局的 propagator
+func WithProducerPropagator(p propagation.TextMapPropagator) ProducerOption {
+	return func(o *producerOptions) {
+		o.propagator = p
+	}
+}
+
+// WithMetrics 开启 prometheus 指标
+func WithMetrics(namespace, subsystem string) ProducerOption {
+	return func(o *producerOptions) {
+		o.metrics = true
+		o.namespace = namespace
+		o.subsystem = subsystem
+	}
+}
@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestProducer(t *testing.T, opts ...ProducerOption) (*Producer, *mocks.AsyncProducer) {
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	mp := mocks.NewAsyncProducer(t, config)
+	o := &producerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	p, err := newProducer(mp, o)
+	require.NoError(t, err)
+	return p, mp
+}
+
+func TestProducer_Publish(t *testing.T) {
+	p, mp := newTestProducer(t, WithDefaultTopic("orders"), WithMetrics("test", "publish"))
+	mp.ExpectInputAndSucceed()
+	mp.ExpectInputAndFail(errors.New("broker down"))
+
+	msg := &ProducerMessage{Key: []byte("1"), Value: []byte("created")}
+	require.NoError(t, p.Publish(context.Background(), msg))
+	assert.Equal(t, int64(1), msg.Offset)
+
+	err := p.Publish(context.Background(), &ProducerMessage{Value: []byte("created")})
+	assert.EqualError(t, err, "broker down")
+
+	require.NoError(t, p.Close())
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.messages.WithLabelValues("orders", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.messages.WithLabelValues("orders", "failure")))
+
+	assert.ErrorIs(t, p.Publish(context.Background(), msg), ErrProducerClosed)
+}
+
+func TestProducer_PublishAsync(t *testing.T) {
+	p, mp := newTestProducer(t)
+	var (
+		mu      sync.Mutex
+		results []error
+	)
+	for i := 0; i < 3; i++ {
+		mp.ExpectInputAndSucceed()
+		err := p.PublishAsync(context.Background(), &ProducerMessage{Topic: "orders", Value: []byte("v")},
+			func(msg *ProducerMessage, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				results = append(results, err)
+			})
+		require.NoError(t, err)
+	}
+	assert.Error(t, p.PublishAsync(context.Background(), &ProducerMessage{Value: []byte("v")}, nil))
+
+	// Close 等待所有消息的回调完成
+	require.NoError(t, p.Close())
+	assert.Equal(t, []error{nil, nil, nil}, results)
+}
+
+func TestProducer_TraceHeaders(t *testing.T) {
+	p, mp := newTestProducer(t, WithDefaultTopic("orders"), WithProducerPropagator(propagation.TraceContext{}))
+	mp.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		for _, h := range msg.Headers {
+			if string(h.Key) == "traceparent" {
+				return nil
+			}
+		}
+		return errors.New("traceparent header not found")
+	})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	require.NoError(t, p.Publish(ctx, &ProducerMessage{Value: []byte("v")}))
+	require.NoError(t, p.Close())
+}
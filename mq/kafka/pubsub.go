@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+// HeaderMessageID 保存 mq.Message.ID 的消息头
+const HeaderMessageID = "x-message-id"
+
+var (
+	_ mq.Publisher  = (*Publisher)(nil)
+	_ mq.Subscriber = (*Subscriber)(nil)
+)
+
+// Publisher 把 Producer 适配为 mq.Publisher
+type Publisher struct {
+	producer *Producer
+}
+
+// NewPublisher 创建 mq.Publisher, Close 时会关闭 producer
+func NewPublisher(producer *Producer) *Publisher {
+	return &Publisher{producer: producer}
+}
+
+// Publish 逐条发送消息并等待 broker 确认
+func (p *Publisher) Publish(ctx context.Context, topic string, msgs ...*mq.Message) error {
+	for _, msg := range msgs {
+		headers := msg.Headers.Clone()
+		if msg.ID != "" {
+			headers[HeaderMessageID] = msg.ID
+		}
+		pm := &ProducerMessage{
+			Topic:   topic,
+			Value:   msg.Payload,
+			Headers: headers,
+		}
+		if msg.Key != "" {
+			pm.Key = []byte(msg.Key)
+		}
+		if err := p.producer.Publish(ctx, pm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.producer.Close()
+}
+
+// Subscriber 把消费者组适配为 mq.Subscriber, 消费者组 ID 在创建 group 时指定
+type Subscriber struct {
+	group sarama.ConsumerGroup
+	opts  []HandlerOption
+}
+
+// NewSubscriber 创建 mq.Subscriber, opts 用于配置重试、重试 topic 和死信 topic
+func NewSubscriber(group sarama.ConsumerGroup, opts ...HandlerOption) *Subscriber {
+	return &Subscriber{group: group, opts: opts}
+}
+
+// Subscribe 消费 topic 直到 ctx 取消, 每次 rebalance 后重新加入消费者组.
+// 处理失败的消息按 HandlerOption 重试或转发, 不会提交 offset
+func (s *Subscriber) Subscribe(ctx context.Context, topic string, h mq.Handler) error {
+	handler := NewConsumerGroupHandler(func(ctx context.Context, msg *Message) error {
+		return h(ctx, toMQMessage(msg))
+	}, s.opts...)
+	for {
+		err := s.group.Consume(ctx, []string{topic}, handler)
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return mq.ErrClosed
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// 会话因为处理失败而结束时, 等待一段时间再重新加入消费者组
+		sleep(ctx, handler.backoff)
+	}
+}
+
+func (s *Subscriber) Close() error {
+	return s.group.Close()
+}
+
+func toMQMessage(msg *Message) *mq.Message {
+	headers := mq.Headers(msg.Headers).Clone()
+	id := headers[HeaderMessageID]
+	delete(headers, HeaderMessageID)
+	if id == "" {
+		id = fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+	}
+	return &mq.Message{
+		ID:      id,
+		Topic:   msg.Topic,
+		Key:     string(msg.Key),
+		Payload: msg.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+// testGroup 每次 Consume 投递一条消息, 然后取消 ctx
+type testGroup struct {
+	sarama.ConsumerGroup
+	msg     *sarama.ConsumerMessage
+	session *testSession
+	closed  bool
+}
+
+func (g *testGroup) Consume(ctx context.Context, _ []string, handler sarama.ConsumerGroupHandler) error {
+	msgs := make(chan *sarama.ConsumerMessage, 1)
+	msgs <- g.msg
+	close(msgs)
+	g.session = &testSession{ctx: ctx}
+	if err := handler.Setup(g.session); err != nil {
+		return err
+	}
+	return handler.ConsumeClaim(g.session, &testClaim{msgs: msgs})
+}
+
+func (g *testGroup) Close() error {
+	g.closed = true
+	return nil
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	p, mp := newTestProducer(t)
+	mp.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, "orders", msg.Topic)
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, []byte("1"), key)
+		headers := map[string]string{}
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		assert.Equal(t, "m-1", headers[HeaderMessageID])
+		assert.Equal(t, "v", headers["x-custom"])
+		return nil
+	})
+
+	pub := NewPublisher(p)
+	msg := &mq.Message{ID: "m-1", Key: "1", Payload: []byte("created"), Headers: mq.Headers{"x-custom": "v"}}
+	require.NoError(t, pub.Publish(context.Background(), "orders", msg))
+	require.NoError(t, pub.Close())
+}
+
+func TestSubscriber_Subscribe(t *testing.T) {
+	group := &testGroup{msg: &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    7,
+		Key:       []byte("1"),
+		Value:     []byte("created"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("x-custom"), Value: []byte("v")}},
+	}}
+	sub := NewSubscriber(group)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got *mq.Message
+	err := sub.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		got = msg
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "orders-2-7", got.ID)
+	assert.Equal(t, "1", got.Key)
+	assert.Equal(t, "v", got.Headers.Get("x-custom"))
+	assert.Equal(t, []int64{7}, group.session.marked)
+
+	require.NoError(t, sub.Close())
+	assert.True(t, group.closed)
+}
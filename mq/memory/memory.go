@@ -0,0 +1,224 @@
+// Package memory 提供进程内的 mq 驱动, 用于单元测试.
+// 消息保存在内存中, 新的消费者组从 topic 的第一条消息开始消费
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+var _ mq.Publisher = (*Broker)(nil)
+
+// Option 内存驱动选项
+type Option func(*Broker)
+
+// WithRedeliveryDelay 设置处理失败的消息重新投递前的等待时间, 默认 100ms
+func WithRedeliveryDelay(d time.Duration) Option {
+	return func(b *Broker) {
+		b.redeliveryDelay = d
+	}
+}
+
+// Broker 内存消息中间件, 本身实现了 mq.Publisher
+type Broker struct {
+	redeliveryDelay time.Duration
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	closed chan struct{}
+	once   sync.Once
+}
+
+type topic struct {
+	log    []*mq.Message
+	groups map[string]*group
+	// notify 在有新消息时关闭并替换, 用于唤醒等待中的订阅者
+	notify chan struct{}
+}
+
+type group struct {
+	offset  int
+	pending []*mq.Message
+}
+
+// New 创建内存消息中间件
+func New(opts ...Option) *Broker {
+	b := &Broker{
+		redeliveryDelay: 100 * time.Millisecond,
+		topics:          make(map[string]*topic),
+		closed:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Publish 追加消息到 topic, 消息会被复制, 调用方之后修改 msgs 不影响已发布的消息
+func (b *Broker) Publish(_ context.Context, name string, msgs ...*mq.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.isClosed() {
+		return mq.ErrClosed
+	}
+	t := b.topic(name)
+	for _, msg := range msgs {
+		cp := *msg
+		cp.Topic = name
+		cp.Headers = msg.Headers.Clone()
+		cp.Payload = append([]byte(nil), msg.Payload...)
+		if cp.ID == "" {
+			cp.ID = name + "-" + strconv.Itoa(len(t.log))
+		}
+		t.log = append(t.log, &cp)
+	}
+	t.wake()
+	return nil
+}
+
+// Messages 返回 topic 中已发布的全部消息, 用于测试断言
+func (b *Broker) Messages(name string) []*mq.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok := b.topics[name]; ok {
+		return append([]*mq.Message(nil), t.log...)
+	}
+	return nil
+}
+
+// Subscriber 返回属于消费者组 group 的订阅者
+func (b *Broker) Subscriber(group string) mq.Subscriber {
+	return &subscriber{broker: b, group: group, closed: make(chan struct{})}
+}
+
+// Close 关闭 Broker, 所有订阅者的 Subscribe 返回 mq.ErrClosed
+func (b *Broker) Close() error {
+	b.once.Do(func() {
+		close(b.closed)
+	})
+	return nil
+}
+
+func (b *Broker) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// topic 调用方需持有锁
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{
+			groups: make(map[string]*group),
+			notify: make(chan struct{}),
+		}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// next 取出消费者组的下一条消息, 没有消息时返回等待用的 channel
+func (b *Broker) next(name, groupName string) (*mq.Message, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(name)
+	g, ok := t.groups[groupName]
+	if !ok {
+		g = &group{}
+		t.groups[groupName] = g
+	}
+	if len(g.pending) > 0 {
+		msg := g.pending[0]
+		g.pending = g.pending[1:]
+		return msg, nil
+	}
+	if g.offset < len(t.log) {
+		msg := t.log[g.offset]
+		g.offset++
+		return msg, nil
+	}
+	return nil, t.notify
+}
+
+// requeue 把处理失败的消息放回消费者组
+func (b *Broker) requeue(name, groupName string, msg *mq.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(name)
+	g := t.groups[groupName]
+	g.pending = append(g.pending, msg)
+	t.wake()
+}
+
+func (t *topic) wake() {
+	close(t.notify)
+	t.notify = make(chan struct{})
+}
+
+type subscriber struct {
+	broker *Broker
+	group  string
+	closed chan struct{}
+	once   sync.Once
+}
+
+// Subscribe 消费消息, 处理失败的消息在 redeliveryDelay 后重新投递给组内的订阅者
+func (s *subscriber) Subscribe(ctx context.Context, name string, h mq.Handler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.closed:
+			return mq.ErrClosed
+		case <-s.broker.closed:
+			return mq.ErrClosed
+		default:
+		}
+		msg, wait := s.broker.next(name, s.group)
+		if msg == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.closed:
+				return mq.ErrClosed
+			case <-s.broker.closed:
+				return mq.ErrClosed
+			case <-wait:
+			}
+			continue
+		}
+
+		// 每次投递一份副本, 避免 handler 修改消息影响重投
+		cp := *msg
+		cp.Headers = msg.Headers.Clone()
+		if err := h(ctx, &cp); err != nil {
+			s.redeliver(name, msg)
+		}
+	}
+}
+
+func (s *subscriber) redeliver(name string, msg *mq.Message) {
+	if s.broker.redeliveryDelay <= 0 {
+		s.broker.requeue(name, s.group, msg)
+		return
+	}
+	time.AfterFunc(s.broker.redeliveryDelay, func() {
+		s.broker.requeue(name, s.group, msg)
+	})
+}
+
+// Close 停止订阅, 正在处理的消息处理完后 Subscribe 返回 mq.ErrClosed
+func (s *subscriber) Close() error {
+	s.once.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
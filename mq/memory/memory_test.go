@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+func TestBroker_ConsumerGroups(t *testing.T) {
+	b := New()
+	defer b.Close()
+
+	require.NoError(t, b.Publish(context.Background(), "orders",
+		&mq.Message{Payload: []byte("a")}, &mq.Message{Payload: []byte("b")}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		got = map[string][]string{}
+		wg  sync.WaitGroup
+	)
+	wg.Add(4)
+	for _, group := range []string{"g1", "g2"} {
+		group := group
+		go func() {
+			_ = b.Subscriber(group).Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+				mu.Lock()
+				got[group] = append(got[group], string(msg.Payload))
+				mu.Unlock()
+				wg.Done()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"a", "b"}, got["g1"])
+	assert.Equal(t, []string{"a", "b"}, got["g2"])
+	assert.Equal(t, "orders-0", b.Messages("orders")[0].ID)
+}
+
+func TestBroker_Redeliver(t *testing.T) {
+	b := New(WithRedeliveryDelay(time.Millisecond))
+	defer b.Close()
+
+	msg := mq.NewMessage([]byte("a"))
+	require.NoError(t, b.Publish(context.Background(), "orders", msg))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ids []string
+	err := b.Subscriber("g").Subscribe(ctx, "orders", func(ctx context.Context, m *mq.Message) error {
+		ids = append(ids, m.ID)
+		if len(ids) < 3 {
+			return errors.New("mock error")
+		}
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{msg.ID, msg.ID, msg.ID}, ids)
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := New()
+	sub := b.Subscriber("g")
+	done := make(chan error, 1)
+	go func() {
+		done <- sub.Subscribe(context.Background(), "orders", func(ctx context.Context, msg *mq.Message) error {
+			return nil
+		})
+	}()
+	require.NoError(t, sub.Close())
+	assert.ErrorIs(t, <-done, mq.ErrClosed)
+
+	require.NoError(t, b.Close())
+	assert.ErrorIs(t, b.Publish(context.Background(), "orders", mq.NewMessage(nil)), mq.ErrClosed)
+}
@@ -0,0 +1,56 @@
+package mq
+
+import (
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Message 与具体中间件无关的消息
+type Message struct {
+	// ID 消息唯一标识, 由生产者设置. 为空时由驱动在投递时填充一个在重投中保持不变的标识
+	ID string
+	// Topic 消息所属的 topic, 投递时由驱动填充
+	Topic   string
+	Key     string
+	Payload []byte
+	Headers Headers
+}
+
+// NewMessage 创建一条带随机 ID 的消息
+func NewMessage(payload []byte) *Message {
+	return &Message{
+		ID:      uuid.NewString(),
+		Payload: payload,
+		Headers: Headers{},
+	}
+}
+
+// Headers 消息头, 同时可以作为链路信息的载体
+type Headers map[string]string
+
+var _ propagation.TextMapCarrier = Headers(nil)
+
+func (h Headers) Get(key string) string {
+	return h[key]
+}
+
+func (h Headers) Set(key, value string) {
+	h[key] = value
+}
+
+func (h Headers) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Clone 复制消息头, nil 返回空 Headers
+func (h Headers) Clone() Headers {
+	res := make(Headers, len(h))
+	for k, v := range h {
+		res[k] = v
+	}
+	return res
+}
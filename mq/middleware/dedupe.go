@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/apus-run/sea-kit/idempotent"
+	"github.com/apus-run/sea-kit/mq"
+)
+
+// ErrInProgress 消息正在被其他消费者处理, 不确认, 等待重投
+var ErrInProgress = errors.New("mq: message is being processed")
+
+// 去重记录的状态, 兼容旧版本写入的 []byte{1}, 视为已处理
+var (
+	dedupeInProgress = []byte("in_progress")
+	dedupeDone       = []byte("done")
+)
+
+// DedupeOption 去重选项
+type DedupeOption func(*dedupeOptions)
+
+type dedupeOptions struct {
+	prefix string
+	ttl    time.Duration
+	lease  time.Duration
+}
+
+// WithDedupePrefix 设置去重记录的 key 前缀, 多个消费者组共用一个 Store 时应各自设置
+func WithDedupePrefix(prefix string) DedupeOption {
+	return func(o *dedupeOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithDedupeTTL 设置已处理记录的保留时间, 默认 24 小时
+func WithDedupeTTL(ttl time.Duration) DedupeOption {
+	return func(o *dedupeOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithDedupeLease 设置处理中记录的有效期, 默认 5 分钟.
+// 进程在处理中崩溃时, 记录过期后重投的消息可以再次处理, 应大于消息的最长处理时间
+func WithDedupeLease(lease time.Duration) DedupeOption {
+	return func(o *dedupeOptions) {
+		o.lease = lease
+	}
+}
+
+// Dedupe 按消息 ID 去重, 保证至少一次投递的同时避免重复处理:
+//
+//   - 处理前将消息标记为处理中, 有效期为 lease
+//   - 处理成功后标记为已处理, 保留 ttl, 之后重投的消息直接确认
+//   - 处理失败时删除记录, 使重投的消息可以再次处理
+//   - 消息正在被其他消费者处理 (例如被 XAUTOCLAIM 转移) 时返回 ErrInProgress, 不确认
+//
+// 没有 ID 的消息不去重
+func Dedupe(store idempotent.Store, opts ...DedupeOption) mq.Middleware {
+	o := &dedupeOptions{
+		prefix: "mq:dedupe:",
+		ttl:    24 * time.Hour,
+		lease:  5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next mq.Handler) mq.Handler {
+		return func(ctx context.Context, msg *mq.Message) error {
+			if msg.ID == "" {
+				return next(ctx, msg)
+			}
+			key := o.prefix + msg.Topic + ":" + msg.ID
+			ok, err := store.SetNX(ctx, key, dedupeInProgress, o.lease)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				state, err := store.Get(ctx, key)
+				if errors.Is(err, idempotent.ErrNotFound) {
+					// 记录恰好过期或被删除, 交给重投再处理
+					return ErrInProgress
+				}
+				if err != nil {
+					return err
+				}
+				if bytes.Equal(state, dedupeInProgress) {
+					return ErrInProgress
+				}
+				return nil
+			}
+
+			// 使用独立的 ctx, 避免 ctx 已取消时记录无法更新
+			if err = next(ctx, msg); err != nil {
+				_, _ = store.Delete(context.WithoutCancel(ctx), key)
+				return err
+			}
+			return store.Set(context.WithoutCancel(ctx), key, dedupeDone, o.ttl)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+// Logging 记录每条消息的处理结果和耗时, 失败时使用 Error 级别
+func Logging(logger *slog.Logger) mq.Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next mq.Handler) mq.Handler {
+		return func(ctx context.Context, msg *mq.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+			attrs := []any{
+				slog.String("topic", msg.Topic),
+				slog.String("id", msg.ID),
+				slog.Duration("elapsed", time.Since(start)),
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "mq: handle message failed", append(attrs, slog.Any("err", err))...)
+				return err
+			}
+			logger.DebugContext(ctx, "mq: message handled", attrs...)
+			return nil
+		}
+	}
+}
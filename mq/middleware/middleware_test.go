@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/apus-run/sea-kit/idempotent"
+	"github.com/apus-run/sea-kit/mq"
+	"github.com/apus-run/sea-kit/mq/memory"
+	"github.com/apus-run/sea-kit/retry"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	h := mq.Chain(func(ctx context.Context, msg *mq.Message) error {
+		calls++
+		return errors.New("mock error")
+	}, Retry(func() retry.Strategy {
+		s, _ := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 2)
+		return s
+	}))
+	assert.Error(t, h(context.Background(), mq.NewMessage(nil)))
+	assert.Equal(t, 3, calls)
+}
+
+func TestDedupe(t *testing.T) {
+	store := idempotent.NewMemoryStore()
+	calls := 0
+	fail := true
+	h := mq.Chain(func(ctx context.Context, msg *mq.Message) error {
+		calls++
+		if fail {
+			return errors.New("mock error")
+		}
+		return nil
+	}, Dedupe(store))
+
+	msg := mq.NewMessage(nil)
+	// 失败后记录被删除, 重投的消息可以再次处理
+	assert.Error(t, h(context.Background(), msg))
+	fail = false
+	assert.NoError(t, h(context.Background(), msg))
+	// 已处理过的消息直接确认
+	assert.NoError(t, h(context.Background(), msg))
+	assert.Equal(t, 2, calls)
+}
+
+func TestDedupe_InProgress(t *testing.T) {
+	store := idempotent.NewMemoryStore()
+	calls := 0
+	var h mq.Handler
+	h = mq.Chain(func(ctx context.Context, msg *mq.Message) error {
+		calls++
+		if calls == 1 {
+			// 处理中重投的消息不确认
+			assert.ErrorIs(t, h(ctx, msg), ErrInProgress)
+		}
+		return nil
+	}, Dedupe(store, WithDedupeLease(50*time.Millisecond)))
+
+	msg := mq.NewMessage(nil)
+	assert.NoError(t, h(context.Background(), msg))
+	assert.Equal(t, 1, calls)
+
+	// 处理中崩溃, 记录过期后重投的消息可以再次处理
+	key := "mq:dedupe::" + msg.ID
+	crashed := mq.NewMessage(nil)
+	ok, err := store.SetNX(context.Background(), "mq:dedupe::"+crashed.ID, dedupeInProgress, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.ErrorIs(t, h(context.Background(), crashed), ErrInProgress)
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, h(context.Background(), crashed))
+	assert.Equal(t, 2, calls)
+
+	// 已处理的记录不受 lease 影响
+	state, err := store.Get(context.Background(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, dedupeDone, state)
+}
+
+func TestTracing(t *testing.T) {
+	prop := propagation.TraceContext{}
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	b := memory.New()
+	defer b.Close()
+	pub := mq.WrapPublisher(b, TracingPublisher(WithPropagator(prop)))
+
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	require.NoError(t, pub.Publish(ctx, "orders", mq.NewMessage([]byte("a"))))
+	assert.NotEmpty(t, b.Messages("orders")[0].Headers.Get("traceparent"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got trace.SpanContext
+	h := mq.Chain(func(ctx context.Context, msg *mq.Message) error {
+		got = trace.SpanContextFromContext(ctx)
+		cancel()
+		return nil
+	}, Tracing(WithPropagator(prop)), Logging(nil))
+	require.NoError(t, b.Subscriber("g").Subscribe(ctx, "orders", h))
+	assert.Equal(t, parent.TraceID(), got.TraceID())
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/apus-run/sea-kit/mq"
+	"github.com/apus-run/sea-kit/retry"
+)
+
+// Retry 在进程内按策略重试处理失败的消息, 重试耗尽后返回最后一次的错误交给驱动重投.
+// newStrategy 每条消息调用一次, 因为 retry.Strategy 是有状态的
+func Retry(newStrategy func() retry.Strategy) mq.Middleware {
+	return func(next mq.Handler) mq.Handler {
+		return func(ctx context.Context, msg *mq.Message) error {
+			strategy := newStrategy()
+			for {
+				err := next(ctx, msg)
+				if err == nil {
+					return nil
+				}
+				d, ok := strategy.Next()
+				if !ok {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return err
+				case <-time.After(d):
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+const instrumentationName = "github.com/apus-run/sea-kit/mq"
+
+// TracingOption 链路追踪选项
+type TracingOption func(*tracingOptions)
+
+type tracingOptions struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracer 设置 tracer, 默认使用全局 TracerProvider
+func WithTracer(tracer trace.Tracer) TracingOption {
+	return func(o *tracingOptions) {
+		o.tracer = tracer
+	}
+}
+
+// WithPropagator 设置链路信息的传播方式, 默认使用全局 TextMapPropagator
+func WithPropagator(p propagation.TextMapPropagator) TracingOption {
+	return func(o *tracingOptions) {
+		o.propagator = p
+	}
+}
+
+func newTracingOptions(opts []TracingOption) *tracingOptions {
+	o := &tracingOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.tracer == nil {
+		o.tracer = otel.Tracer(instrumentationName)
+	}
+	if o.propagator == nil {
+		o.propagator = otel.GetTextMapPropagator()
+	}
+	return o
+}
+
+// Tracing 从消息头中提取链路信息, 并为每条消息创建一个 consumer span
+func Tracing(opts ...TracingOption) mq.Middleware {
+	o := newTracingOptions(opts)
+	return func(next mq.Handler) mq.Handler {
+		return func(ctx context.Context, msg *mq.Message) error {
+			if msg.Headers != nil {
+				ctx = o.propagator.Extract(ctx, msg.Headers)
+			}
+			ctx, span := o.tracer.Start(ctx, msg.Topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.destination.name", msg.Topic),
+					attribute.String("messaging.message.id", msg.ID),
+				))
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// TracingPublisher 为发布的消息创建 producer span 并把链路信息写入消息头
+func TracingPublisher(opts ...TracingOption) mq.PublisherMiddleware {
+	o := newTracingOptions(opts)
+	return func(next mq.PublishFunc) mq.PublishFunc {
+		return func(ctx context.Context, topic string, msgs ...*mq.Message) error {
+			ctx, span := o.tracer.Start(ctx, topic+" publish",
+				trace.WithSpanKind(trace.SpanKindProducer),
+				trace.WithAttributes(
+					attribute.String("messaging.destination.name", topic),
+					attribute.Int("messaging.batch.message_count", len(msgs)),
+				))
+			defer span.End()
+
+			for _, msg := range msgs {
+				if msg.Headers == nil {
+					msg.Headers = mq.Headers{}
+				}
+				o.propagator.Inject(ctx, msg.Headers)
+			}
+			err := next(ctx, topic, msgs...)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package mq
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed Publisher 或 Subscriber 已关闭
+var ErrClosed = errors.New("mq: closed")
+
+// Handler 处理消息. 返回 nil 时确认消息, 返回 error 时不确认, 由驱动重新投递
+type Handler func(ctx context.Context, msg *Message) error
+
+// Middleware 包装 Handler, 用于重试、链路追踪、日志、去重等
+type Middleware func(next Handler) Handler
+
+// Chain 按顺序组合中间件, 第一个中间件在最外层
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Publisher 向 topic 发布消息, 可以被多个协程并发调用
+type Publisher interface {
+	// Publish 发布消息, 返回 nil 表示消息已被中间件确认
+	Publish(ctx context.Context, topic string, msgs ...*Message) error
+	// Close 释放资源
+	Close() error
+}
+
+// Subscriber 订阅 topic 并消费消息
+type Subscriber interface {
+	// Subscribe 阻塞消费 topic 直到 ctx 取消或 Subscriber 关闭.
+	// 同一消费者组内的订阅者竞争消费, 不同组各自收到全部消息
+	Subscribe(ctx context.Context, topic string, h Handler) error
+	// Close 释放资源, 正在进行的 Subscribe 会返回
+	Close() error
+}
+
+// PublishFunc 发布消息的函数
+type PublishFunc func(ctx context.Context, topic string, msgs ...*Message) error
+
+// PublisherMiddleware 包装发布过程, 用于注入链路信息等
+type PublisherMiddleware func(next PublishFunc) PublishFunc
+
+// WrapPublisher 为 Publisher 添加发布中间件, 第一个中间件在最外层
+func WrapPublisher(p Publisher, mws ...PublisherMiddleware) Publisher {
+	fn := p.Publish
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](fn)
+	}
+	return &wrappedPublisher{Publisher: p, publish: fn}
+}
+
+type wrappedPublisher struct {
+	Publisher
+	publish PublishFunc
+}
+
+func (p *wrappedPublisher) Publish(ctx context.Context, topic string, msgs ...*Message) error {
+	return p.publish(ctx, topic, msgs...)
+}
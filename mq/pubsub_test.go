@@ -0,0 +1,27 @@
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *Message) error {
+				order = append(order, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+	h := Chain(func(ctx context.Context, msg *Message) error {
+		order = append(order, "handler")
+		return nil
+	}, mw("first"), mw("second"))
+
+	assert.NoError(t, h(context.Background(), NewMessage(nil)))
+	assert.Equal(t, []string{"first", "second", "handler"}, order)
+}
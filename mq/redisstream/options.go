@@ -0,0 +1,78 @@
+package redisstream
+
+import (
+	"log/slog"
+	"time"
+)
+
+// PublisherOption 发布者选项
+type PublisherOption func(*Publisher)
+
+// WithMaxLen 设置 stream 的近似最大长度, 超出时裁剪旧消息, 0 表示不裁剪
+func WithMaxLen(n int64) PublisherOption {
+	return func(p *Publisher) {
+		p.maxLen = n
+	}
+}
+
+// SubscriberOption 订阅者选项
+type SubscriberOption func(*Subscriber)
+
+// WithBlock 设置 XREADGROUP 的阻塞时间, 默认 2s
+func WithBlock(d time.Duration) SubscriberOption {
+	return func(s *Subscriber) {
+		s.block = d
+	}
+}
+
+// WithBatchSize 设置每次读取的最大消息数, 默认 16
+func WithBatchSize(n int64) SubscriberOption {
+	return func(s *Subscriber) {
+		s.batchSize = n
+	}
+}
+
+// WithClaimInterval 设置检查 pending 消息的间隔, 默认 30s, 0 表示不回收
+func WithClaimInterval(d time.Duration) SubscriberOption {
+	return func(s *Subscriber) {
+		s.claimInterval = d
+	}
+}
+
+// WithMinIdle 设置 pending 消息空闲多久后可以被回收重新处理, 默认 1min.
+// 应大于单条消息的最长处理时间, 否则消息可能被重复处理
+func WithMinIdle(d time.Duration) SubscriberOption {
+	return func(s *Subscriber) {
+		s.minIdle = d
+	}
+}
+
+// WithStartID 设置消费者组不存在时创建组的起始位置, 默认 "0" 即从头消费, "$" 表示只消费新消息
+func WithStartID(id string) SubscriberOption {
+	return func(s *Subscriber) {
+		s.startID = id
+	}
+}
+
+// WithMaxDeliveries 设置消息最多投递的次数, 默认 10. 处理失败且投递次数达到上限的消息
+// 转移到死信 stream 并确认, 不再回收; 0 表示不限制, 失败的消息一直留在 pending 列表中
+func WithMaxDeliveries(n int64) SubscriberOption {
+	return func(s *Subscriber) {
+		s.maxDeliveries = n
+	}
+}
+
+// WithDeadLetterStream 设置死信 stream, 默认为 topic + DefaultDeadLetterSuffix.
+// 死信消息保留原始字段, 并额外记录来源 topic 和最后一次处理的错误
+func WithDeadLetterStream(stream string) SubscriberOption {
+	return func(s *Subscriber) {
+		s.deadLetterStream = stream
+	}
+}
+
+// WithLogger 设置日志, 默认使用 slog.Default()
+func WithLogger(logger *slog.Logger) SubscriberOption {
+	return func(s *Subscriber) {
+		s.logger = logger
+	}
+}
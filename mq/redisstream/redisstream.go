@@ -0,0 +1,312 @@
+// Package redisstream 基于 redis stream 的 mq 驱动.
+// 客户端可以通过 redisx.Helper 获取, 消费者组、消息确认和 pending 消息回收分别对应
+// XREADGROUP、XACK 和 XAUTOCLAIM, 投递次数超过上限的消息转移到死信 stream
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+var (
+	_ mq.Publisher  = (*Publisher)(nil)
+	_ mq.Subscriber = (*Subscriber)(nil)
+)
+
+const (
+	fieldID      = "id"
+	fieldKey     = "key"
+	fieldPayload = "payload"
+	fieldHeaders = "headers"
+
+	// 死信消息额外记录的字段
+	fieldTopic = "topic"
+	fieldError = "error"
+)
+
+// DefaultDeadLetterSuffix 默认死信 stream 的后缀, 死信 stream 为 topic + DefaultDeadLetterSuffix
+const DefaultDeadLetterSuffix = ".dead-letter"
+
+// Publisher 使用 XADD 发布消息
+type Publisher struct {
+	client redis.Cmdable
+	maxLen int64
+}
+
+// NewPublisher 创建发布者
+func NewPublisher(client redis.Cmdable, opts ...PublisherOption) *Publisher {
+	p := &Publisher{client: client}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Publish 发布消息, 多条消息通过 pipeline 一次发送
+func (p *Publisher) Publish(ctx context.Context, topic string, msgs ...*mq.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	pipe := p.client.Pipeline()
+	for _, msg := range msgs {
+		values, err := encode(msg)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: topic,
+			MaxLen: p.maxLen,
+			Approx: p.maxLen > 0,
+			Values: values,
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Close 客户端由调用方管理, 这里不做任何事情
+func (p *Publisher) Close() error {
+	return nil
+}
+
+// Subscriber 以消费者组的方式消费 stream
+type Subscriber struct {
+	client   redis.Cmdable
+	group    string
+	consumer string
+
+	block            time.Duration
+	batchSize        int64
+	claimInterval    time.Duration
+	minIdle          time.Duration
+	startID          string
+	maxDeliveries    int64
+	deadLetterStream string
+	logger           *slog.Logger
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewSubscriber 创建订阅者, consumer 在组内唯一标识当前实例
+func NewSubscriber(client redis.Cmdable, group, consumer string, opts ...SubscriberOption) *Subscriber {
+	s := &Subscriber{
+		client:        client,
+		group:         group,
+		consumer:      consumer,
+		block:         2 * time.Second,
+		batchSize:     16,
+		claimInterval: 30 * time.Second,
+		minIdle:       time.Minute,
+		startID:       "0",
+		maxDeliveries: 10,
+		logger:        slog.Default(),
+		closed:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Subscribe 消费 topic, 处理成功的消息 XACK, 失败的消息留在 pending 列表中,
+// 空闲超过 minIdle 后由组内任意订阅者回收重新处理, 投递次数达到 maxDeliveries 后转移到死信 stream
+func (s *Subscriber) Subscribe(ctx context.Context, topic string, h mq.Handler) error {
+	err := s.client.XGroupCreateMkStream(ctx, topic, s.group, s.startID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	var lastClaim time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.closed:
+			return mq.ErrClosed
+		default:
+		}
+
+		if s.claimInterval > 0 && time.Since(lastClaim) >= s.claimInterval {
+			lastClaim = time.Now()
+			if err := s.reclaim(ctx, topic, h); err != nil && ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "redisstream: reclaim pending messages failed",
+					slog.String("topic", topic), slog.Any("err", err))
+			}
+		}
+
+		streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.consumer,
+			Streams:  []string{topic, ">"},
+			Count:    s.batchSize,
+			Block:    s.block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, stream := range streams {
+			s.handle(ctx, topic, stream.Messages, h)
+		}
+	}
+}
+
+// reclaim 使用 XAUTOCLAIM 接管空闲超过 minIdle 的 pending 消息并处理
+func (s *Subscriber) reclaim(ctx context.Context, topic string, h mq.Handler) error {
+	start := "0-0"
+	for {
+		msgs, next, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   topic,
+			Group:    s.group,
+			Consumer: s.consumer,
+			MinIdle:  s.minIdle,
+			Start:    start,
+			Count:    s.batchSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		s.handle(ctx, topic, msgs, h)
+		if next == "0-0" || ctx.Err() != nil {
+			return nil
+		}
+		start = next
+	}
+}
+
+func (s *Subscriber) handle(ctx context.Context, topic string, msgs []redis.XMessage, h mq.Handler) {
+	for _, raw := range msgs {
+		msg, err := decode(topic, raw)
+		if err != nil {
+			// 无法解析的消息重投也无法处理, 直接确认
+			s.logger.ErrorContext(ctx, "redisstream: drop undecodable message",
+				slog.String("topic", topic), slog.String("id", raw.ID), slog.Any("err", err))
+			s.ack(ctx, topic, raw.ID)
+			continue
+		}
+		if err = h(ctx, msg); err != nil {
+			s.logger.ErrorContext(ctx, "redisstream: handle message failed",
+				slog.String("topic", topic), slog.String("id", raw.ID), slog.Any("err", err))
+			s.deadLetter(ctx, topic, raw, err)
+			continue
+		}
+		s.ack(ctx, topic, raw.ID)
+	}
+}
+
+// deadLetter 消息投递次数达到 maxDeliveries 时, 在同一个事务中写入死信 stream 并确认,
+// 否则留在 pending 列表中等待回收
+func (s *Subscriber) deadLetter(ctx context.Context, topic string, raw redis.XMessage, cause error) {
+	if s.maxDeliveries <= 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: topic,
+		Group:  s.group,
+		Start:  raw.ID,
+		End:    raw.ID,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "redisstream: get delivery count failed",
+			slog.String("topic", topic), slog.String("id", raw.ID), slog.Any("err", err))
+		return
+	}
+	if len(pending) == 0 || pending[0].RetryCount < s.maxDeliveries {
+		return
+	}
+
+	values := make(map[string]any, len(raw.Values)+2)
+	for k, v := range raw.Values {
+		values[k] = v
+	}
+	values[fieldTopic] = topic
+	values[fieldError] = cause.Error()
+
+	stream := s.deadLetterStream
+	if stream == "" {
+		stream = topic + DefaultDeadLetterSuffix
+	}
+	pipe := s.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: values})
+	pipe.XAck(ctx, topic, s.group, raw.ID)
+	if _, err = pipe.Exec(ctx); err != nil {
+		s.logger.ErrorContext(ctx, "redisstream: move message to dead letter stream failed",
+			slog.String("topic", topic), slog.String("id", raw.ID), slog.Any("err", err))
+		return
+	}
+	s.logger.WarnContext(ctx, "redisstream: message moved to dead letter stream",
+		slog.String("topic", topic), slog.String("id", raw.ID),
+		slog.String("stream", stream), slog.Int64("deliveries", pending[0].RetryCount))
+}
+
+func (s *Subscriber) ack(ctx context.Context, topic, id string) {
+	if err := s.client.XAck(context.WithoutCancel(ctx), topic, s.group, id).Err(); err != nil {
+		s.logger.ErrorContext(ctx, "redisstream: ack failed",
+			slog.String("topic", topic), slog.String("id", id), slog.Any("err", err))
+	}
+}
+
+// Close 停止订阅, 正在处理的批次处理完后 Subscribe 返回 mq.ErrClosed
+func (s *Subscriber) Close() error {
+	s.once.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+func encode(msg *mq.Message) (map[string]any, error) {
+	values := map[string]any{
+		fieldID:      msg.ID,
+		fieldKey:     msg.Key,
+		fieldPayload: msg.Payload,
+	}
+	if len(msg.Headers) > 0 {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return nil, err
+		}
+		values[fieldHeaders] = headers
+	}
+	return values, nil
+}
+
+func decode(topic string, raw redis.XMessage) (*mq.Message, error) {
+	msg := &mq.Message{
+		ID:      stringValue(raw.Values[fieldID]),
+		Topic:   topic,
+		Key:     stringValue(raw.Values[fieldKey]),
+		Payload: []byte(stringValue(raw.Values[fieldPayload])),
+		Headers: mq.Headers{},
+	}
+	if msg.ID == "" {
+		msg.ID = raw.ID
+	}
+	if headers := stringValue(raw.Values[fieldHeaders]); headers != "" {
+		if err := json.Unmarshal([]byte(headers), &msg.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,100 @@
+//go:build e2e
+
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/mq"
+)
+
+func newClient(t *testing.T) redis.Cmdable {
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	require.NoError(t, rdb.Ping(context.Background()).Err())
+	return rdb
+}
+
+func TestPubSub_E2E(t *testing.T) {
+	rdb := newClient(t)
+	topic := "mq-e2e-" + time.Now().Format("150405.000")
+	t.Cleanup(func() { rdb.Del(context.Background(), topic) })
+
+	pub := NewPublisher(rdb, WithMaxLen(1000))
+	msg := mq.NewMessage([]byte("created"))
+	msg.Headers.Set("x-custom", "v")
+	require.NoError(t, pub.Publish(context.Background(), topic, msg))
+
+	// 第一个消费者处理失败, 消息留在 pending 列表
+	failed := NewSubscriber(rdb, "g", "c1", WithBlock(100*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, failed.Subscribe(ctx, topic, func(ctx context.Context, m *mq.Message) error {
+		cancel()
+		return errors.New("mock error")
+	}))
+
+	// 第二个消费者回收 pending 消息并确认
+	sub := NewSubscriber(rdb, "g", "c2",
+		WithBlock(100*time.Millisecond), WithMinIdle(time.Millisecond), WithClaimInterval(time.Millisecond))
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got *mq.Message
+	require.NoError(t, sub.Subscribe(ctx, topic, func(ctx context.Context, m *mq.Message) error {
+		got = m
+		cancel()
+		return nil
+	}))
+	require.NotNil(t, got)
+	assert.Equal(t, msg.ID, got.ID)
+	assert.Equal(t, "v", got.Headers.Get("x-custom"))
+
+	pending, err := rdb.XPending(context.Background(), topic, "g").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), pending.Count)
+}
+
+func TestDeadLetter_E2E(t *testing.T) {
+	rdb := newClient(t)
+	topic := "mq-e2e-dead-" + time.Now().Format("150405.000")
+	dead := topic + DefaultDeadLetterSuffix
+	t.Cleanup(func() { rdb.Del(context.Background(), topic, dead) })
+
+	msg := mq.NewMessage([]byte("created"))
+	require.NoError(t, NewPublisher(rdb).Publish(context.Background(), topic, msg))
+
+	// 每次都处理失败, 第二次投递后转移到死信 stream
+	sub := NewSubscriber(rdb, "g", "c1", WithMaxDeliveries(2),
+		WithBlock(100*time.Millisecond), WithMinIdle(time.Millisecond), WithClaimInterval(time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var calls int
+	go func() {
+		assert.Eventually(t, func() bool {
+			n, err := rdb.XLen(context.Background(), dead).Result()
+			return err == nil && n == 1
+		}, 4*time.Second, 10*time.Millisecond)
+		cancel()
+	}()
+	require.NoError(t, sub.Subscribe(ctx, topic, func(ctx context.Context, m *mq.Message) error {
+		calls++
+		return errors.New("mock error")
+	}))
+	assert.Equal(t, 2, calls)
+
+	msgs, err := rdb.XRange(context.Background(), dead, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, msg.ID, msgs[0].Values[fieldID])
+	assert.Equal(t, topic, msgs[0].Values[fieldTopic])
+	assert.Equal(t, "mock error", msgs[0].Values[fieldError])
+
+	pending, err := rdb.XPending(context.Background(), topic, "g").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), pending.Count)
+}
@@ -0,0 +1,188 @@
+// Package adaptive implements a client side adaptive concurrency limiter.
+//
+// The limit follows the gradient of the round trip time, inspired by
+// netflix concurrency-limits (https://github.com/Netflix/concurrency-limits):
+//
+//	gradient = clamp(minRt / avgRt, 0.5, 1)
+//	newLimit = limit * gradient + sqrt(limit)
+//	limit    = limit * (1 - smoothing) + newLimit * smoothing
+//
+// so the limit grows while the downstream latency stays close to its no load
+// round trip time and shrinks as soon as requests start queuing. Overload
+// errors decrease the limit multiplicatively (AIMD).
+package adaptive
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apus-run/sea-kit/algo/window"
+)
+
+// Limiter implements a gradient based adaptive concurrency limiter.
+type Limiter struct {
+	rtStat         window.RollingCounter
+	bucketDuration time.Duration
+	inFlight       int64
+	rejected       int64
+	limit          int64
+
+	mu sync.Mutex
+	// limit with fractional part, limit is its rounded value
+	estimated float64
+	// round trip times sampled since the last update, in microseconds
+	rtSum   float64
+	rtCount int
+	// max in flight since the last update
+	maxInFlight  int64
+	lastUpdate   time.Time
+	lastDecrease time.Time
+
+	opts    options
+	metrics *metrics
+}
+
+// NewLimiter returns an adaptive concurrency limiter.
+// It fails only when the metrics enabled by WithMetrics cannot be registered.
+func NewLimiter(opts ...Option) (*Limiter, error) {
+	opt := Apply(opts...)
+	bucketDuration := opt.Window / time.Duration(opt.Bucket)
+
+	l := &Limiter{
+		rtStat:         window.NewRollingCounter(window.RollingCounterOpts{Size: opt.Bucket, BucketDuration: bucketDuration}),
+		bucketDuration: bucketDuration,
+		estimated:      float64(opt.InitialLimit),
+		lastUpdate:     time.Now(),
+		opts:           opt,
+	}
+	if opt.metrics {
+		m, err := newMetrics(opt.metricsNamespace, opt.metricsSubsystem)
+		if err != nil {
+			return nil, err
+		}
+		l.metrics = m
+	}
+	l.setLimit(float64(opt.InitialLimit))
+	return l, nil
+}
+
+// Stat takes a snapshot of the adaptive limiter.
+func (l *Limiter) Stat() Stat {
+	return Stat{
+		Limit:    atomic.LoadInt64(&l.limit),
+		InFlight: atomic.LoadInt64(&l.inFlight),
+		MinRt:    int64(l.minRT()),
+		Rejected: atomic.LoadInt64(&l.rejected),
+	}
+}
+
+// Allow acquires a concurrency slot.
+// Once the in flight requests reach the limit, it fails fast with ErrLimitExceed.
+func (l *Limiter) Allow() (DoneFunc, error) {
+	for {
+		inFlight := atomic.LoadInt64(&l.inFlight)
+		if inFlight >= atomic.LoadInt64(&l.limit) {
+			atomic.AddInt64(&l.rejected, 1)
+			l.metrics.reject(l.opts.Name)
+			return nil, ErrLimitExceed
+		}
+		if atomic.CompareAndSwapInt64(&l.inFlight, inFlight, inFlight+1) {
+			l.metrics.setInFlight(l.opts.Name, inFlight+1)
+			break
+		}
+	}
+
+	start := time.Now()
+	return func(info DoneInfo) {
+		// cancelled by the caller, the round trip time says nothing about the downstream
+		if !info.Canceled {
+			now := time.Now()
+			l.update(now, now.Sub(start), info.Overload)
+		}
+		inFlight := atomic.AddInt64(&l.inFlight, -1)
+		l.metrics.setInFlight(l.opts.Name, inFlight)
+	}, nil
+}
+
+// update samples the round trip time and updates the limit
+// at most once per bucket duration.
+func (l *Limiter) update(now time.Time, rt time.Duration, overload bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if inFlight := atomic.LoadInt64(&l.inFlight); inFlight > l.maxInFlight {
+		l.maxInFlight = inFlight
+	}
+
+	if overload {
+		if now.Sub(l.lastDecrease) < l.bucketDuration {
+			return
+		}
+		l.lastDecrease = now
+		l.estimated = l.clamp(l.estimated * l.opts.Backoff)
+		l.setLimit(l.estimated)
+		return
+	}
+
+	us := float64(rt) / float64(time.Microsecond)
+	if us < 1 {
+		us = 1
+	}
+	l.rtStat.Add(int64(math.Ceil(us)))
+	l.rtSum += us
+	l.rtCount++
+
+	if now.Sub(l.lastUpdate) < l.bucketDuration {
+		return
+	}
+
+	avgRT := l.rtSum / float64(l.rtCount)
+	gradient := math.Max(0.5, math.Min(1, l.minRT()/avgRT))
+	newLimit := l.estimated * gradient
+	// grow only when the limit is actually used, not app limited
+	if float64(l.maxInFlight) >= l.estimated/2 {
+		newLimit += math.Sqrt(l.estimated)
+	}
+	l.estimated = l.clamp(l.estimated*(1-l.opts.Smoothing) + newLimit*l.opts.Smoothing)
+	l.setLimit(l.estimated)
+
+	l.rtSum = 0
+	l.rtCount = 0
+	l.maxInFlight = 0
+	l.lastUpdate = now
+}
+
+// minRT returns the min bucket average round trip time within the window, in microseconds.
+func (l *Limiter) minRT() float64 {
+	minRT := l.rtStat.Reduce(func(iterator window.Iterator) float64 {
+		var result = math.MaxFloat64
+		for iterator.Next() {
+			bucket := iterator.Bucket()
+			if len(bucket.Points) == 0 {
+				continue
+			}
+			total := 0.0
+			for _, p := range bucket.Points {
+				total += p
+			}
+			result = math.Min(result, total/float64(bucket.Count))
+		}
+		return result
+	})
+	if minRT <= 0 || minRT == math.MaxFloat64 {
+		return 1
+	}
+	return minRT
+}
+
+func (l *Limiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.opts.MinLimit), math.Min(float64(l.opts.MaxLimit), limit))
+}
+
+func (l *Limiter) setLimit(limit float64) {
+	v := int64(math.Round(limit))
+	atomic.StoreInt64(&l.limit, v)
+	l.metrics.setLimit(l.opts.Name, v)
+}
@@ -0,0 +1,127 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kitmetrics "github.com/apus-run/sea-kit/metrics"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l, err := NewLimiter(WithInitialLimit(2), WithMetrics("test", "adaptive_allow"), WithName("allow"))
+	require.NoError(t, err)
+	rejected := testutil.ToFloat64(l.metrics.rejected.WithLabelValues("allow"))
+
+	done1, err := l.Allow()
+	require.NoError(t, err)
+	done2, err := l.Allow()
+	require.NoError(t, err)
+
+	// 达到并发上限, 快速失败
+	_, err = l.Allow()
+	assert.Equal(t, ErrLimitExceed, err)
+
+	stat := l.Stat()
+	assert.Equal(t, int64(2), stat.Limit)
+	assert.Equal(t, int64(2), stat.InFlight)
+	assert.Equal(t, int64(1), stat.Rejected)
+	assert.Equal(t, rejected+1, testutil.ToFloat64(l.metrics.rejected.WithLabelValues("allow")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.metrics.inFlight.WithLabelValues("allow")))
+
+	done1(DoneInfo{})
+	done2(DoneInfo{})
+
+	_, err = l.Allow()
+	assert.NoError(t, err)
+}
+
+func TestLimiter_Overload(t *testing.T) {
+	l, err := NewLimiter(WithInitialLimit(10), WithBackoff(0.5),
+		WithWindow(100*time.Millisecond), WithBucket(10))
+	require.NoError(t, err)
+
+	done, err := l.Allow()
+	require.NoError(t, err)
+	done(DoneInfo{Overload: true})
+	assert.Equal(t, int64(5), l.Stat().Limit)
+
+	// 一个 bucket 内最多减少一次
+	done, err = l.Allow()
+	require.NoError(t, err)
+	done(DoneInfo{Overload: true})
+	assert.Equal(t, int64(5), l.Stat().Limit)
+
+	time.Sleep(10 * time.Millisecond)
+	done, err = l.Allow()
+	require.NoError(t, err)
+	done(DoneInfo{Overload: true})
+	assert.Equal(t, int64(3), l.Stat().Limit)
+}
+
+func TestLimiter_Gradient(t *testing.T) {
+	l, err := NewLimiter(WithInitialLimit(4), WithLimitRange(1, 100),
+		WithWindow(time.Second), WithBucket(100))
+	require.NoError(t, err)
+
+	// 延迟稳定且并发跑满时, limit 增长
+	for i := 0; i < 20; i++ {
+		dones := make([]DoneFunc, 0, 4)
+		for {
+			done, err := l.Allow()
+			if err != nil {
+				break
+			}
+			dones = append(dones, done)
+		}
+		time.Sleep(10 * time.Millisecond)
+		for _, done := range dones {
+			done(DoneInfo{})
+		}
+	}
+	grown := l.Stat().Limit
+	assert.Greater(t, grown, int64(4))
+
+	// 延迟明显升高, limit 下降
+	for i := 0; i < 10; i++ {
+		done, err := l.Allow()
+		require.NoError(t, err)
+		time.Sleep(40 * time.Millisecond)
+		done(DoneInfo{})
+	}
+	assert.Less(t, l.Stat().Limit, grown)
+}
+
+func TestLimiter_Canceled(t *testing.T) {
+	l, err := NewLimiter(WithInitialLimit(10), WithBackoff(0.5),
+		WithWindow(100*time.Millisecond), WithBucket(10))
+	require.NoError(t, err)
+
+	// 调用方取消的请求不采样, 也不影响 limit
+	done, err := l.Allow()
+	require.NoError(t, err)
+	done(DoneInfo{Err: context.Canceled, Overload: true, Canceled: true})
+	stat := l.Stat()
+	assert.Equal(t, int64(10), stat.Limit)
+	assert.Equal(t, int64(0), stat.InFlight)
+	assert.Equal(t, 0, l.rtCount)
+}
+
+func TestNewLimiter_MetricsError(t *testing.T) {
+	// 同名但 label 不同的指标无法注册
+	_, err := kitmetrics.Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "test",
+		Subsystem: "adaptive_conflict",
+		Name:      "concurrency_limit",
+		Help:      "Conflicting metric.",
+	}, []string{"other"}))
+	require.NoError(t, err)
+
+	_, err = NewLimiter(WithMetrics("test", "adaptive_conflict"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package adaptive
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	kitmetrics "github.com/apus-run/sea-kit/metrics"
+)
+
+type metrics struct {
+	limit    *prometheus.GaugeVec
+	inFlight *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+func newMetrics(namespace, subsystem string) (*metrics, error) {
+	limit, err := kitmetrics.Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "concurrency_limit",
+		Help:      "Current adaptive concurrency limit.",
+	}, []string{"limiter"}))
+	if err != nil {
+		return nil, err
+	}
+	inFlight, err := kitmetrics.Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "in_flight_requests",
+		Help:      "Number of requests in flight.",
+	}, []string{"limiter"}))
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := kitmetrics.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "rejected_requests_total",
+		Help:      "Number of requests rejected by the concurrency limit.",
+	}, []string{"limiter"}))
+	if err != nil {
+		return nil, err
+	}
+	return &metrics{limit: limit, inFlight: inFlight, rejected: rejected}, nil
+}
+
+func (m *metrics) setLimit(name string, limit int64) {
+	if m == nil {
+		return
+	}
+	m.limit.WithLabelValues(name).Set(float64(limit))
+}
+
+func (m *metrics) setInFlight(name string, inFlight int64) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(name).Set(float64(inFlight))
+}
+
+func (m *metrics) reject(name string) {
+	if m == nil {
+		return
+	}
+	m.rejected.WithLabelValues(name).Inc()
+}
@@ -0,0 +1,118 @@
+package adaptive
+
+import (
+	"time"
+)
+
+// options of adaptive limiter.
+type options struct {
+	// Window defines the time duration the no load round trip time is tracked
+	Window time.Duration
+	// Bucket defines bucket number for each window,
+	// the limit is updated at most once per bucket duration
+	Bucket int
+	// InitialLimit is the concurrency limit before any sample
+	InitialLimit int
+	// MinLimit and MaxLimit bound the concurrency limit
+	MinLimit int
+	MaxLimit int
+	// Smoothing is the weight of the new gradient limit, in (0, 1]
+	Smoothing float64
+	// Backoff is the ratio the limit is multiplied by on overload, in (0, 1)
+	Backoff float64
+	// Name labels the limiter metrics
+	Name string
+
+	// metrics are registered by NewLimiter when enabled
+	metrics          bool
+	metricsNamespace string
+	metricsSubsystem string
+}
+
+// Option function for adaptive limiter
+type Option func(*options)
+
+// WithWindow with window size.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.Window = d
+	}
+}
+
+// WithBucket with bucket size.
+func WithBucket(b int) Option {
+	return func(o *options) {
+		o.Bucket = b
+	}
+}
+
+// WithInitialLimit with initial concurrency limit.
+func WithInitialLimit(limit int) Option {
+	return func(o *options) {
+		o.InitialLimit = limit
+	}
+}
+
+// WithLimitRange with min and max concurrency limit.
+func WithLimitRange(min, max int) Option {
+	return func(o *options) {
+		o.MinLimit = min
+		o.MaxLimit = max
+	}
+}
+
+// WithSmoothing with gradient smoothing factor.
+func WithSmoothing(smoothing float64) Option {
+	return func(o *options) {
+		o.Smoothing = smoothing
+	}
+}
+
+// WithBackoff with overload backoff ratio.
+func WithBackoff(backoff float64) Option {
+	return func(o *options) {
+		o.Backoff = backoff
+	}
+}
+
+// WithName with limiter name, used as the "limiter" label of the metrics.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.Name = name
+	}
+}
+
+// WithMetrics enables the prometheus limiter metrics registered
+// under the provided namespace and subsystem.
+//
+// Limiters with the same namespace and subsystem share the metrics,
+// use WithName() to tell them apart. NewLimiter returns the registration error.
+func WithMetrics(namespace, subsystem string) Option {
+	return func(o *options) {
+		o.metrics = true
+		o.metricsNamespace = namespace
+		o.metricsSubsystem = subsystem
+	}
+}
+
+// DefaultOptions .
+func DefaultOptions() options {
+	return options{
+		Window:       time.Second * 10,
+		Bucket:       100,
+		InitialLimit: 20,
+		MinLimit:     1,
+		MaxLimit:     1000,
+		Smoothing:    0.2,
+		Backoff:      0.9,
+		Name:         "default",
+	}
+}
+
+func Apply(opts ...Option) options {
+	def := DefaultOptions()
+	for _, apply := range opts {
+		apply(&def)
+	}
+	return def
+}
@@ -0,0 +1,33 @@
+package adaptive
+
+import "errors"
+
+var (
+	// ErrLimitExceed is returned when the in flight requests
+	// reach the current concurrency limit and the request is rejected.
+	ErrLimitExceed = errors.New("adaptive concurrency limit exceeded")
+)
+
+// DoneFunc is done function.
+type DoneFunc func(DoneInfo)
+
+// DoneInfo is done info.
+type DoneInfo struct {
+	Err error
+	// Overload reports the downstream is overloaded (eg. timeout or resource exhausted),
+	// the limit is decreased multiplicatively and the round trip time is not sampled.
+	Overload bool
+	// Canceled reports the caller cancelled the request before it completed,
+	// the round trip time is not sampled and the limit is not changed.
+	Canceled bool
+}
+
+// Stat contains the metrics snapshot of the adaptive limiter.
+type Stat struct {
+	Limit    int64
+	InFlight int64
+	// MinRt is the no load round trip time in microseconds.
+	MinRt int64
+	// Rejected is the total number of rejected requests.
+	Rejected int64
+}
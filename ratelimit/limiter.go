@@ -0,0 +1,79 @@
+// Package ratelimit 定义了统一的限流器接口, 具体实现见:
+//
+//   - ratelimit/redis: 基于 redis 的滑动窗口、固定窗口、令牌桶和 GCRA 限流器, 适用于集群限流
+//   - ratelimit/local: 进程内的固定窗口、令牌桶和 GCRA 限流器, 适用于单机限流
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidLimit 限流配置不合法
+var ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+
+// 限流相关的响应头, 参考 IETF draft-ietf-httpapi-ratelimit-headers
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 对 key 消耗一次配额并返回判定结果
+	// key 就是限流对象
+	// err 限流器本身有没有错误
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// Result 一次限流判定的结果
+type Result struct {
+	// Allowed 是否放行, false 就是要限流
+	Allowed bool
+	// Limit 配额上限 (窗口阈值或桶容量)
+	Limit int
+	// Remaining 本次判定之后剩余的配额
+	Remaining int
+	// RetryAfter 被限流时多久之后可以重试, 放行时为 0
+	RetryAfter time.Duration
+	// ResetAfter 多久之后配额完全恢复
+	ResetAfter time.Duration
+}
+
+// Headers 返回对应的限流响应头, 被限流时额外返回 Retry-After
+// 时间类的值按秒向上取整
+func (r Result) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderLimit:     strconv.Itoa(r.Limit),
+		HeaderRemaining: strconv.Itoa(r.Remaining),
+		HeaderReset:     strconv.FormatInt(seconds(r.ResetAfter), 10),
+	}
+	if !r.Allowed {
+		headers[HeaderRetryAfter] = strconv.FormatInt(seconds(r.RetryAfter), 10)
+	}
+	return headers
+}
+
+func seconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// CheckLimit 校验 interval 内允许 rate 个请求、最多 burst 个突发请求的配置,
+// 三者都必须为正数, 且 rate 不能超过 interval 的纳秒数, 否则请求间隔为 0
+func CheckLimit(interval time.Duration, rate int, burst int) error {
+	if interval <= 0 || rate <= 0 || burst <= 0 {
+		return fmt.Errorf("%w: interval %s, rate %d and burst %d must be positive", ErrInvalidLimit, interval, rate, burst)
+	}
+	if interval/time.Duration(rate) <= 0 {
+		return fmt.Errorf("%w: rate %d is too high for interval %s", ErrInvalidLimit, rate, interval)
+	}
+	return nil
+}
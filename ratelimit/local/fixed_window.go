@@ -0,0 +1,65 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+// FixedWindowLimiter 进程内的固定窗口算法限流器实现
+type FixedWindowLimiter struct {
+	// 窗口大小
+	interval time.Duration
+	// 阈值, interval 内允许 rate 个请求
+	rate int
+
+	states *store[fixedWindow]
+	now    func() time.Time
+}
+
+type fixedWindow struct {
+	start time.Time
+	count int
+}
+
+// NewFixedWindowLimiter interval 内允许 rate 个请求
+func NewFixedWindowLimiter(interval time.Duration, rate int) (ratelimit.Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, rate); err != nil {
+		return nil, err
+	}
+	return &FixedWindowLimiter{
+		interval: interval,
+		rate:     rate,
+		states: newStore(interval, func(st *fixedWindow, now time.Time) bool {
+			return !now.Before(st.start.Add(interval))
+		}),
+		now: time.Now,
+	}, nil
+}
+
+func (l *FixedWindowLimiter) Allow(_ context.Context, key string) (ratelimit.Result, error) {
+	now := l.now()
+	return l.states.do(key, now, func(st *fixedWindow) ratelimit.Result {
+		if end := st.start.Add(l.interval); !now.Before(end) {
+			st.start = now
+			st.count = 0
+		}
+		reset := st.start.Add(l.interval).Sub(now)
+
+		if st.count >= l.rate {
+			return ratelimit.Result{
+				Limit:      l.rate,
+				RetryAfter: reset,
+				ResetAfter: reset,
+			}
+		}
+		st.count++
+		return ratelimit.Result{
+			Allowed:    true,
+			Limit:      l.rate,
+			Remaining:  l.rate - st.count,
+			ResetAfter: reset,
+		}
+	}), nil
+}
@@ -0,0 +1,63 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+// GCRALimiter 进程内的 GCRA (Generic Cell Rate Algorithm) 限流器实现
+// 每个 key 只保存一个理论到达时间 (TAT), 效果等同于令牌桶
+type GCRALimiter struct {
+	// 相邻两个请求的理论间隔, interval 内允许 rate 个请求
+	emission time.Duration
+	// 允许的突发请求数
+	burst int
+
+	states *store[time.Time]
+	now    func() time.Time
+}
+
+// NewGCRALimiter interval 内允许 rate 个请求, 最多允许 burst 个突发请求
+func NewGCRALimiter(interval time.Duration, rate int, burst int) (ratelimit.Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, burst); err != nil {
+		return nil, err
+	}
+	emission := interval / time.Duration(rate)
+	return &GCRALimiter{
+		emission: emission,
+		burst:    burst,
+		states: newStore(emission*time.Duration(burst), func(tat *time.Time, now time.Time) bool {
+			return !tat.After(now)
+		}),
+		now: time.Now,
+	}, nil
+}
+
+func (l *GCRALimiter) Allow(_ context.Context, key string) (ratelimit.Result, error) {
+	now := l.now()
+	tolerance := l.emission * time.Duration(l.burst)
+	return l.states.do(key, now, func(tat *time.Time) ratelimit.Result {
+		t := *tat
+		if t.Before(now) {
+			t = now
+		}
+		diff := t.Add(l.emission).Sub(now)
+
+		if diff > tolerance {
+			return ratelimit.Result{
+				Limit:      l.burst,
+				RetryAfter: diff - tolerance,
+				ResetAfter: t.Sub(now),
+			}
+		}
+		*tat = t.Add(l.emission)
+		return ratelimit.Result{
+			Allowed:    true,
+			Limit:      l.burst,
+			Remaining:  int((tolerance - diff) / l.emission),
+			ResetAfter: diff,
+		}
+	}), nil
+}
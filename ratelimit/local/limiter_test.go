@@ -0,0 +1,202 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestFixedWindowLimiter_Allow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter, err := NewFixedWindowLimiter(time.Second, 2)
+	require.NoError(t, err)
+	l := limiter.(*FixedWindowLimiter)
+	l.now = clock.Now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		key     string
+		want    ratelimit.Result
+	}{
+		{
+			name: "正常通过",
+			key:  "foo",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Second},
+		},
+		{
+			name:    "窗口内第二个请求通过",
+			advance: 400 * time.Millisecond,
+			key:     "foo",
+			want:    ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 600 * time.Millisecond},
+		},
+		{
+			name: "另外一个key正常通过",
+			key:  "bar",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Second},
+		},
+		{
+			name:    "限流",
+			advance: 100 * time.Millisecond,
+			key:     "foo",
+			want:    ratelimit.Result{Limit: 2, RetryAfter: 500 * time.Millisecond, ResetAfter: 500 * time.Millisecond},
+		},
+		{
+			name:    "新窗口正常通过",
+			advance: 500 * time.Millisecond,
+			key:     "foo",
+			want:    ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Add(tt.advance)
+			got, err := l.Allow(context.Background(), tt.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTokenBucketLimiter_Allow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	// 每 100ms 补充一个令牌, 桶容量 2
+	limiter, err := NewTokenBucketLimiter(time.Second, 10, 2)
+	require.NoError(t, err)
+	l := limiter.(*TokenBucketLimiter)
+	l.now = clock.Now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    ratelimit.Result
+	}{
+		{
+			name: "正常通过",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 100 * time.Millisecond},
+		},
+		{
+			name: "突发请求通过",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 200 * time.Millisecond},
+		},
+		{
+			name:    "限流",
+			advance: 50 * time.Millisecond,
+			want:    ratelimit.Result{Limit: 2, Remaining: 0, RetryAfter: 50 * time.Millisecond, ResetAfter: 150 * time.Millisecond},
+		},
+		{
+			name:    "补充令牌之后通过",
+			advance: 50 * time.Millisecond,
+			want:    ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 200 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Add(tt.advance)
+			got, err := l.Allow(context.Background(), "foo")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGCRALimiter_Allow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	// 每 100ms 允许一个请求, 最多 2 个突发请求
+	limiter, err := NewGCRALimiter(time.Second, 10, 2)
+	require.NoError(t, err)
+	l := limiter.(*GCRALimiter)
+	l.now = clock.Now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    ratelimit.Result
+	}{
+		{
+			name: "正常通过",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 100 * time.Millisecond},
+		},
+		{
+			name: "突发请求通过",
+			want: ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 200 * time.Millisecond},
+		},
+		{
+			name:    "限流",
+			advance: 50 * time.Millisecond,
+			want:    ratelimit.Result{Limit: 2, Remaining: 0, RetryAfter: 50 * time.Millisecond, ResetAfter: 150 * time.Millisecond},
+		},
+		{
+			name:    "间隔之后通过",
+			advance: 50 * time.Millisecond,
+			want:    ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 200 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Add(tt.advance)
+			got, err := l.Allow(context.Background(), "foo")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Sweep(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter, err := NewFixedWindowLimiter(time.Second, 1)
+	require.NoError(t, err)
+	l := limiter.(*FixedWindowLimiter)
+	l.now = clock.Now
+
+	_, _ = l.Allow(context.Background(), "foo")
+	clock.Add(time.Second)
+	_, _ = l.Allow(context.Background(), "bar")
+
+	assert.Len(t, l.states.items, 1)
+	assert.Contains(t, l.states.items, "bar")
+}
+
+func TestNewLimiter_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		rate     int
+		burst    int
+	}{
+		{name: "zero rate", interval: time.Second, rate: 0, burst: 1},
+		{name: "negative rate", interval: time.Second, rate: -1, burst: 1},
+		{name: "zero interval", interval: 0, rate: 1, burst: 1},
+		{name: "zero burst", interval: time.Second, rate: 1, burst: 0},
+		{name: "rate too high", interval: time.Nanosecond, rate: 2, burst: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGCRALimiter(tt.interval, tt.rate, tt.burst)
+			assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+			_, err = NewTokenBucketLimiter(tt.interval, tt.rate, tt.burst)
+			assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+			if tt.burst > 0 {
+				_, err = NewFixedWindowLimiter(tt.interval, tt.rate)
+				assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package local
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+// store 保存每个 key 的限流状态
+// 状态完全恢复之后的 key 会在下一次清理时被删除, 避免 key 无限增长
+type store[T any] struct {
+	mu    sync.Mutex
+	items map[string]*T
+
+	// idle 判断状态是否已经完全恢复
+	idle       func(st *T, now time.Time) bool
+	sweepEvery time.Duration
+	lastSweep  time.Time
+}
+
+func newStore[T any](sweepEvery time.Duration, idle func(st *T, now time.Time) bool) *store[T] {
+	return &store[T]{
+		items:      map[string]*T{},
+		idle:       idle,
+		sweepEvery: sweepEvery,
+	}
+}
+
+// do 在锁内对 key 的状态执行 fn
+func (s *store[T]) do(key string, now time.Time, fn func(st *T) ratelimit.Result) ratelimit.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= s.sweepEvery {
+		s.lastSweep = now
+		for k, st := range s.items {
+			if s.idle(st, now) {
+				delete(s.items, k)
+			}
+		}
+	}
+
+	st, ok := s.items[key]
+	if !ok {
+		st = new(T)
+		s.items[key] = st
+	}
+	return fn(st)
+}
@@ -0,0 +1,65 @@
+package local
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+// TokenBucketLimiter 进程内的令牌桶算法限流器实现
+type TokenBucketLimiter struct {
+	// 每个令牌的补充间隔, interval 内补充 rate 个令牌
+	refill time.Duration
+	// 桶容量, 即允许的突发请求数
+	burst int
+
+	states *store[tokenBucket]
+	now    func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter interval 内补充 rate 个令牌, 桶里最多 burst 个令牌
+func NewTokenBucketLimiter(interval time.Duration, rate int, burst int) (ratelimit.Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, burst); err != nil {
+		return nil, err
+	}
+	refill := interval / time.Duration(rate)
+	full := refill * time.Duration(burst)
+	return &TokenBucketLimiter{
+		refill: refill,
+		burst:  burst,
+		states: newStore(full, func(st *tokenBucket, now time.Time) bool {
+			return now.Sub(st.last) >= full
+		}),
+		now: time.Now,
+	}, nil
+}
+
+func (l *TokenBucketLimiter) Allow(_ context.Context, key string) (ratelimit.Result, error) {
+	now := l.now()
+	return l.states.do(key, now, func(st *tokenBucket) ratelimit.Result {
+		if st.last.IsZero() {
+			st.tokens = float64(l.burst)
+		} else {
+			st.tokens = math.Min(float64(l.burst), st.tokens+float64(now.Sub(st.last))/float64(l.refill))
+		}
+		st.last = now
+
+		res := ratelimit.Result{Limit: l.burst}
+		if st.tokens >= 1 {
+			st.tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = time.Duration((1 - st.tokens) * float64(l.refill))
+		}
+		res.Remaining = int(st.tokens)
+		res.ResetAfter = time.Duration((float64(l.burst) - st.tokens) * float64(l.refill))
+		return res
+	}), nil
+}
@@ -0,0 +1,52 @@
+package ratelimit_redis
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+//go:embed lua/fixed_window.lua
+var fixedWindowScript string
+
+// RedisFixedWindowLimiter Redis 上的固定窗口算法限流器实现
+// 每个 key 只占用一个计数器, 但窗口边界处可能放行 2 * rate 个请求
+type RedisFixedWindowLimiter struct {
+	cmd redis.Cmdable
+
+	// 窗口大小
+	interval time.Duration
+
+	// 阈值, interval 内允许 rate 个请求
+	rate int
+}
+
+// NewRedisFixedWindowLimiter interval 内允许 rate 个请求, 窗口以毫秒为单位, interval 不能小于 1ms
+func NewRedisFixedWindowLimiter(cmd redis.Cmdable,
+	interval time.Duration, rate int) (Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, rate); err != nil {
+		return nil, err
+	}
+	if interval < time.Millisecond {
+		return nil, fmt.Errorf("%w: interval %s is less than 1ms", ratelimit.ErrInvalidLimit, interval)
+	}
+	return &RedisFixedWindowLimiter{
+		cmd:      cmd,
+		interval: interval,
+		rate:     rate,
+	}, nil
+}
+
+func (r *RedisFixedWindowLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return eval(ctx, r.cmd, fixedWindowScript, key, r.rate,
+		r.interval.Milliseconds(), r.rate)
+}
+
+func (r *RedisFixedWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	return limited(r.Allow(ctx, key))
+}
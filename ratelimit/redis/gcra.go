@@ -0,0 +1,48 @@
+package ratelimit_redis
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+//go:embed lua/gcra.lua
+var gcraScript string
+
+// RedisGCRALimiter Redis 上的 GCRA (Generic Cell Rate Algorithm) 限流器实现
+// 每个 key 只保存一个理论到达时间 (TAT), 效果等同于令牌桶
+type RedisGCRALimiter struct {
+	cmd redis.Cmdable
+
+	// 相邻两个请求的理论间隔, interval 内允许 rate 个请求
+	emission time.Duration
+
+	// 允许的突发请求数
+	burst int
+}
+
+// NewRedisGCRALimiter interval 内允许 rate 个请求, 最多允许 burst 个突发请求
+func NewRedisGCRALimiter(cmd redis.Cmdable,
+	interval time.Duration, rate int, burst int) (Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, burst); err != nil {
+		return nil, err
+	}
+	return &RedisGCRALimiter{
+		cmd:      cmd,
+		emission: interval / time.Duration(rate),
+		burst:    burst,
+	}, nil
+}
+
+func (r *RedisGCRALimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return eval(ctx, r.cmd, gcraScript, key, r.burst,
+		milliseconds(r.emission), r.burst, time.Now().UnixMilli())
+}
+
+func (r *RedisGCRALimiter) Limit(ctx context.Context, key string) (bool, error) {
+	return limited(r.Allow(ctx, key))
+}
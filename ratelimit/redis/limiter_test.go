@@ -0,0 +1,92 @@
+package ratelimit_redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+func TestRedisLimiters_Allow(t *testing.T) {
+	rdb := initRedis()
+
+	fixedWindow, err := NewRedisFixedWindowLimiter(rdb, 500*time.Millisecond, 2)
+	require.NoError(t, err)
+	tokenBucket, err := NewRedisTokenBucketLimiter(rdb, 500*time.Millisecond, 2, 2)
+	require.NoError(t, err)
+	gcra, err := NewRedisGCRALimiter(rdb, 500*time.Millisecond, 2, 2)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		limiter Limiter
+		key     string
+	}{
+		{
+			name:    "固定窗口",
+			limiter: fixedWindow,
+			key:     "fixed-window",
+		},
+		{
+			name:    "令牌桶",
+			limiter: tokenBucket,
+			key:     "token-bucket",
+		},
+		{
+			name:    "GCRA",
+			limiter: gcra,
+			key:     "gcra",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			require.NoError(t, rdb.Del(ctx, tt.key).Err())
+
+			res, err := tt.limiter.Allow(ctx, tt.key)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 2, res.Limit)
+			assert.Equal(t, 1, res.Remaining)
+
+			res, err = tt.limiter.Allow(ctx, tt.key)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+
+			// 配额耗尽, 触发限流
+			limited, err := tt.limiter.Limit(ctx, tt.key)
+			require.NoError(t, err)
+			assert.True(t, limited)
+
+			res, err = tt.limiter.Allow(ctx, tt.key)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+
+			// 等待配额恢复
+			<-time.After(res.RetryAfter + 10*time.Millisecond)
+			res, err = tt.limiter.Allow(ctx, tt.key)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func TestNewRedisLimiters_Invalid(t *testing.T) {
+	_, err := NewRedisGCRALimiter(nil, time.Second, 0, 1)
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+	_, err = NewRedisTokenBucketLimiter(nil, 0, 1, 1)
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+	_, err = NewRedisTokenBucketLimiter(nil, time.Second, 1, 0)
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+	_, err = NewRedisFixedWindowLimiter(nil, time.Second, -1)
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+	// 窗口以毫秒为单位
+	_, err = NewRedisFixedWindowLimiter(nil, time.Microsecond, 1)
+	assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+}
@@ -0,0 +1,18 @@
+-- 限流对象
+local key = KEYS[1]
+-- 窗口大小
+local window = tonumber(ARGV[1])
+-- 阈值
+local threshold = tonumber(ARGV[2])
+
+local cnt = redis.call('INCR', key)
+if cnt == 1 then
+    -- 第一个请求开启新的窗口
+    redis.call('PEXPIRE', key, window)
+end
+local ttl = redis.call('PTTL', key)
+if cnt > threshold then
+    -- 执行限流
+    return {0, 0, ttl, ttl}
+end
+return {1, threshold - cnt, 0, ttl}
@@ -0,0 +1,22 @@
+-- 限流对象
+local key = KEYS[1]
+-- 相邻两个请求的理论间隔 (毫秒)
+local emission = tonumber(ARGV[1])
+-- 允许的突发请求数
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tolerance = emission * burst
+
+-- 理论到达时间 (TAT)
+local tat = tonumber(redis.call('GET', key))
+if not tat or tat < now then
+    tat = now
+end
+local diff = tat + emission - now
+
+if diff > tolerance then
+    -- 执行限流
+    return {0, 0, math.ceil(diff - tolerance), math.ceil(tat - now)}
+end
+redis.call('SET', key, tostring(tat + emission), 'PX', math.ceil(diff))
+return {1, math.floor((tolerance - diff) / emission), 0, math.ceil(diff)}
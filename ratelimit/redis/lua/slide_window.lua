@@ -19,11 +19,13 @@ redis.call('ZREMRANGEBYSCORE', key, '-inf', min)
 local cnt = redis.call('ZCOUNT', key, '-inf', '+inf')
 -- local cnt = redis.call('ZCOUNT', key, min, '+inf')
 if cnt >= threshold then
-    -- 执行限流
-    return "true"
+    -- 执行限流, 最早的请求滑出窗口之后才能重试
+    local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+    local retry = tonumber(oldest[2]) + window - now
+    return {0, 0, retry, redis.call('PTTL', key)}
 else
     -- score 设置为当前时间, member 设置为唯一id
     redis.call('ZADD', key, now, uid)
     redis.call('PEXPIRE', key, window)
-    return "false"
-end
\ No newline at end of file
+    return {1, threshold - cnt - 1, 0, window}
+end
@@ -0,0 +1,29 @@
+-- 限流对象
+local key = KEYS[1]
+-- 每个令牌的补充间隔 (毫秒)
+local refill = tonumber(ARGV[1])
+-- 桶容量
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+-- 按流逝的时间补充令牌
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / refill)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+else
+    -- 执行限流
+    retry = math.ceil((1 - tokens) * refill)
+end
+local reset = math.ceil((capacity - tokens) * refill)
+
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', tostring(now))
+-- 令牌补满之后状态等同于不存在, 直接过期
+redis.call('PEXPIRE', key, math.max(reset, 1))
+return {allowed, math.floor(tokens), retry, reset}
@@ -1,14 +1,20 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/ratelimit/options.go
+// Source: types.go
+//
+// Generated by this command:
+//
+//	mockgen -source=types.go -destination=mocks/ratelimit.mock.go -package=limitmocks
+//
 
 // Package limitmocks is a generated GoMock package.
 package limitmocks
 
 import (
-	"context"
-	"reflect"
+	context "context"
+	reflect "reflect"
 
-	"go.uber.org/mock/gomock"
+	ratelimit "github.com/apus-run/sea-kit/ratelimit"
+	gomock "go.uber.org/mock/gomock"
 )
 
 // MockLimiter is a mock of Limiter interface.
@@ -34,6 +40,21 @@ func (m *MockLimiter) EXPECT() *MockLimiterMockRecorder {
 	return m.recorder
 }
 
+// Allow mocks base method.
+func (m *MockLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, key)
+	ret0, _ := ret[0].(ratelimit.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockLimiterMockRecorder) Allow(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockLimiter)(nil).Allow), ctx, key)
+}
+
 // Limit mocks base method.
 func (m *MockLimiter) Limit(ctx context.Context, key string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -44,7 +65,7 @@ func (m *MockLimiter) Limit(ctx context.Context, key string) (bool, error) {
 }
 
 // Limit indicates an expected call of Limit.
-func (mr *MockLimiterMockRecorder) Limit(ctx, key interface{}) *gomock.Call {
+func (mr *MockLimiterMockRecorder) Limit(ctx, key any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Limit", reflect.TypeOf((*MockLimiter)(nil).Limit), ctx, key)
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/ratelimit"
 )
 
 //go:embed lua/slide_window.lua
@@ -35,11 +37,41 @@ func NewRedisSlidingWindowLimiter(cmd redis.Cmdable,
 	}
 }
 
-func (r *RedisSlidingWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
+func (r *RedisSlidingWindowLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
 	uid, err := uuid.NewUUID()
 	if err != nil {
-		return false, fmt.Errorf("generate uuid failed: %w", err)
+		return ratelimit.Result{}, fmt.Errorf("generate uuid failed: %w", err)
+	}
+	return eval(ctx, r.cmd, luaScript, key, r.rate,
+		r.interval.Milliseconds(), r.rate, time.Now().UnixMilli(), uid.String())
+}
+
+func (r *RedisSlidingWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	return limited(r.Allow(ctx, key))
+}
+
+// eval 执行限流脚本, 脚本统一返回 {是否放行, 剩余配额, 重试等待毫秒数, 完全恢复毫秒数}
+func eval(ctx context.Context, cmd redis.Cmdable, script string, key string, limit int,
+	args ...any) (ratelimit.Result, error) {
+	vals, err := cmd.Eval(ctx, script, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if len(vals) != 4 {
+		return ratelimit.Result{}, fmt.Errorf("unexpected limiter script result: %v", vals)
+	}
+	return ratelimit.Result{
+		Allowed:    vals[0] == 1,
+		Limit:      limit,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		ResetAfter: time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
+
+func limited(res ratelimit.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
 	}
-	return r.cmd.Eval(ctx, luaScript, []string{key},
-		r.interval.Milliseconds(), r.rate, time.Now().UnixMilli(), uid.String()).Bool()
+	return !res.Allowed, nil
 }
@@ -0,0 +1,53 @@
+package ratelimit_redis
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/ratelimit"
+)
+
+//go:embed lua/token_bucket.lua
+var tokenBucketScript string
+
+// RedisTokenBucketLimiter Redis 上的令牌桶算法限流器实现
+// 每个 key 只保存剩余令牌数和上次补充时间, 内存占用和 QPS 无关
+type RedisTokenBucketLimiter struct {
+	cmd redis.Cmdable
+
+	// 每个令牌的补充间隔, interval 内补充 rate 个令牌
+	refill time.Duration
+
+	// 桶容量, 即允许的突发请求数
+	burst int
+}
+
+// NewRedisTokenBucketLimiter interval 内补充 rate 个令牌, 桶里最多 burst 个令牌
+func NewRedisTokenBucketLimiter(cmd redis.Cmdable,
+	interval time.Duration, rate int, burst int) (Limiter, error) {
+	if err := ratelimit.CheckLimit(interval, rate, burst); err != nil {
+		return nil, err
+	}
+	return &RedisTokenBucketLimiter{
+		cmd:    cmd,
+		refill: interval / time.Duration(rate),
+		burst:  burst,
+	}, nil
+}
+
+func (r *RedisTokenBucketLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return eval(ctx, r.cmd, tokenBucketScript, key, r.burst,
+		milliseconds(r.refill), r.burst, time.Now().UnixMilli())
+}
+
+func (r *RedisTokenBucketLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	return limited(r.Allow(ctx, key))
+}
+
+// milliseconds 返回带小数的毫秒数, 避免高 QPS 下间隔被截断为 0
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
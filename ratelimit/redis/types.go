@@ -1,10 +1,14 @@
 package ratelimit_redis
 
 import (
-	"golang.org/x/net/context"
+	"context"
+
+	"github.com/apus-run/sea-kit/ratelimit"
 )
 
 type Limiter interface {
+	ratelimit.Limiter
+
 	// Limit 有没有触发限流
 	// key 就是限流对象
 	// bool 代表是否限流，true 就是要限流
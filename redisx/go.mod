@@ -3,6 +3,7 @@ module github.com/apus-run/sea-kit/redisx
 go 1.21
 
 require (
+	github.com/apus-run/sea-kit/collection v0.0.0-00010101000000-000000000000
 	github.com/google/uuid v1.4.0
 	github.com/redis/go-redis/v9 v9.3.0
 	github.com/stretchr/testify v1.8.4
@@ -17,3 +18,5 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/apus-run/sea-kit/collection => ../collection
@@ -0,0 +1,138 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+const defaultChannel = "sea-kit:invalidation"
+
+var _ invalidation.Bus = (*RedisBus)(nil)
+
+// RedisBus 基于 redis pub/sub 的失效消息总线, 本节点发出的消息不会回调本节点
+type RedisBus struct {
+	client  redis.UniversalClient
+	channel string
+	node    string
+
+	mu       sync.RWMutex
+	seq      int
+	handlers map[int]invalidation.Handler
+
+	pubsub    *redis.PubSub
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+type message struct {
+	Node string   `json:"node"`
+	Keys []string `json:"keys"`
+}
+
+// Option RedisBus 配置
+type Option func(*RedisBus)
+
+// WithChannel 设置 pub/sub 频道
+func WithChannel(channel string) Option {
+	return func(b *RedisBus) {
+		if channel == "" {
+			return
+		}
+		b.channel = channel
+	}
+}
+
+// NewRedisBus 创建 RedisBus 并订阅频道, 使用完需调用 Close
+func NewRedisBus(client redis.UniversalClient, opts ...Option) *RedisBus {
+	b := &RedisBus{
+		client:   client,
+		channel:  defaultChannel,
+		node:     uuid.NewString(),
+		handlers: make(map[int]invalidation.Handler),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.pubsub = client.Subscribe(context.Background(), b.channel)
+	go b.listen()
+	return b
+}
+
+func (b *RedisBus) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(message{Node: b.node, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+func (b *RedisBus) Subscribe(handler invalidation.Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.seq
+	b.seq++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Close 取消订阅频道
+func (b *RedisBus) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		err = b.pubsub.Close()
+	})
+	return err
+}
+
+func (b *RedisBus) listen() {
+	ch := b.pubsub.Channel()
+	for {
+		select {
+		case <-b.done:
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			b.handle(msg.Payload)
+		}
+	}
+}
+
+func (b *RedisBus) handle(payload string) {
+	var msg message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		return
+	}
+	if msg.Node == b.node || len(msg.Keys) == 0 {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := make([]invalidation.Handler, 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(msg.Keys)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build e2e
+
+package invalidation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisBus_e2e(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, rdb.Ping(context.Background()).Err())
+
+	node1 := NewRedisBus(rdb, WithChannel("invalidation-e2e"))
+	defer node1.Close()
+	node2 := NewRedisBus(rdb, WithChannel("invalidation-e2e"))
+	defer node2.Close()
+
+	var mu sync.Mutex
+	var got []string
+	node2.Subscribe(func(keys []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, keys...)
+	})
+
+	// 等待订阅生效
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, node1.Publish(context.Background(), "a"))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 1 && got[0] == "a"
+	}, time.Second, 10*time.Millisecond)
+}
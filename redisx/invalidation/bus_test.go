@@ -0,0 +1,40 @@
+package invalidation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+func TestRedisBus_handle(t *testing.T) {
+	b := &RedisBus{
+		node:     "self",
+		handlers: make(map[int]invalidation.Handler),
+	}
+
+	var got []string
+	unsubscribe := b.Subscribe(func(keys []string) {
+		got = append(got, keys...)
+	})
+
+	own, err := json.Marshal(message{Node: "self", Keys: []string{"a"}})
+	require.NoError(t, err)
+	b.handle(string(own))
+	assert.Empty(t, got)
+
+	other, err := json.Marshal(message{Node: "other", Keys: []string{"a", "b"}})
+	require.NoError(t, err)
+	b.handle(string(other))
+	assert.Equal(t, []string{"a", "b"}, got)
+
+	b.handle("not json")
+	assert.Equal(t, []string{"a", "b"}, got)
+
+	unsubscribe()
+	b.handle(string(other))
+	assert.Equal(t, []string{"a", "b"}, got)
+}
@@ -2,6 +2,9 @@ package rtcache
 
 import (
 	"context"
+	"log/slog"
+	"sync"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
@@ -11,14 +14,32 @@ import (
 type MemReadThroughCache struct {
 	rtf   ReadThroughFunc // worker function to create the items.
 	cache *lru.Cache      // caches the items in RAM. Thread-safe.
+	opts  *options
 
 	activeReadsCh chan struct{} // limits the number of concurrent ReadThroughFunc executions.
+
+	mu         sync.Mutex          // protects the fields below.
+	pending    map[string]struct{} // stale ids waiting for a background refresh.
+	refreshing bool                // whether a background refresh goroutine is running.
+	removed    map[string]struct{} // ids removed while a refresh is in flight, nil otherwise.
+
+	now func() time.Time // overridden in tests.
+
+	ctx         context.Context    // parent of background refreshes, cancelled by Stop.
+	cancel      context.CancelFunc // cancels ctx.
+	unsubscribe func()             // unsubscribes from the invalidation bus.
+}
+
+// entry is a cached item together with the time it was read through.
+type entry struct {
+	value    interface{}
+	loadedAt time.Time
 }
 
 // New returns a new instance of ReadThroughCache that is stored in RAM.
 // maxConcurrentReadThroughCalls defines the number of concurrent calls to the ReadThroughFunc when
-// requested items are not cached.
-func New(rtf ReadThroughFunc, maxCachedItems int, maxConcurrentReadThroughCalls int) (*MemReadThroughCache, error) {
+// requested items are not cached. By default items never expire, see WithSoftTTL and WithHardTTL.
+func New(rtf ReadThroughFunc, maxCachedItems int, maxConcurrentReadThroughCalls int, opts ...Option) (*MemReadThroughCache, error) {
 	// if maxCachedItems is <= 0 then we don't tiny_cache at all. But lru.Cache will not
 	// limit the tiny_cache if the size is 0. So we tiny_cache 1 element.
 	if maxCachedItems <= 0 {
@@ -30,15 +51,36 @@ func New(rtf ReadThroughFunc, maxCachedItems int, maxConcurrentReadThroughCalls
 		return nil, errors.Wrapf(err, "making LRU with %d items", maxCachedItems)
 	}
 
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	ret := &MemReadThroughCache{
 		rtf:   rtf,
 		cache: lruCache,
+		opts:  o,
 
 		activeReadsCh: make(chan struct{}, maxConcurrentReadThroughCalls),
+
+		pending: map[string]struct{}{},
+		now:     time.Now,
+	}
+	ret.ctx, ret.cancel = context.WithCancel(context.Background())
+	if o.bus != nil {
+		ret.unsubscribe = o.bus.Subscribe(ret.remove)
 	}
 	return ret, nil
 }
 
+// Stop unsubscribes the cache from the invalidation bus and cancels background refreshes.
+func (m *MemReadThroughCache) Stop() {
+	m.cancel()
+	if m.unsubscribe != nil {
+		m.unsubscribe()
+	}
+}
+
 // Get implements the ReadThroughCache interface.
 func (m *MemReadThroughCache) Get(ctx context.Context, id string) (interface{}, error) {
 	r, err := m.GetAll(ctx, []string{id})
@@ -48,41 +90,162 @@ func (m *MemReadThroughCache) Get(ctx context.Context, id string) (interface{},
 	return r[0], nil
 }
 
-// GetAll implements the ReadThroughCache interface.
+// GetAll implements the ReadThroughCache interface. Items older than the soft TTL are
+// returned as they are and refreshed in the background, items older than the hard TTL
+// are read through again before being returned.
 func (m *MemReadThroughCache) GetAll(ctx context.Context, ids []string) ([]interface{}, error) {
 	rv := make([]interface{}, len(ids))
 	var missedIDs []string
 	var missedIndexes []int
+	var staleIDs []string
+	now := m.now()
 	for i, id := range ids {
 		// Check the tiny_cache first
-		if result, ok := m.cache.Get(id); ok {
-			rv[i] = result
+		if e, ok := m.get(id); ok && !m.expired(e, now) {
+			rv[i] = e.value
+			if m.stale(e, now) {
+				staleIDs = append(staleIDs, id)
+			}
 		} else {
 			missedIDs = append(missedIDs, id)
 			missedIndexes = append(missedIndexes, i)
 		}
 	}
+	if len(staleIDs) > 0 {
+		m.scheduleRefresh(staleIDs)
+	}
 	// check for both to appease the static analysis from complaining about null deref below.
 	if len(missedIDs) == 0 || len(missedIndexes) == 0 {
 		return rv, nil
 	}
 
-	m.activeReadsCh <- struct{}{}
-	defer func() {
-		<-m.activeReadsCh
-	}()
-	vals, err := m.rtf(ctx, missedIDs)
+	vals, err := m.readThrough(ctx, missedIDs)
 	if err != nil {
 		return nil, err
 	}
 	for i, val := range vals {
-		m.cache.Add(missedIDs[i], val)
 		rv[missedIndexes[i]] = val
 	}
 
 	return rv, nil
 }
 
+// readThrough calls the ReadThroughFunc and caches the results.
+func (m *MemReadThroughCache) readThrough(ctx context.Context, ids []string) ([]interface{}, error) {
+	vals, err := m.fetch(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	loadedAt := m.now()
+	for i, val := range vals {
+		m.cache.Add(ids[i], &entry{value: val, loadedAt: loadedAt})
+	}
+	return vals, nil
+}
+
+// fetch calls the ReadThroughFunc, waiting for a free slot unless ctx is done first.
+func (m *MemReadThroughCache) fetch(ctx context.Context, ids []string) ([]interface{}, error) {
+	select {
+	case m.activeReadsCh <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() {
+		<-m.activeReadsCh
+	}()
+	return m.rtf(ctx, ids)
+}
+
+// scheduleRefresh queues stale ids and starts a background refresh if none is running.
+func (m *MemReadThroughCache) scheduleRefresh(ids []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		return
+	}
+	for _, id := range ids {
+		m.pending[id] = struct{}{}
+	}
+	if m.refreshing {
+		return
+	}
+	m.refreshing = true
+	go m.refresh()
+}
+
+// refresh reads through all pending ids in one ReadThroughFunc call, until none are left
+// or the cache is stopped. Failed refreshes keep the stale values, which will be scheduled
+// again on the next read.
+func (m *MemReadThroughCache) refresh() {
+	timer := time.NewTimer(m.opts.refreshDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			m.mu.Lock()
+			m.pending = map[string]struct{}{}
+			m.refreshing = false
+			m.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		m.mu.Lock()
+		if len(m.pending) == 0 {
+			m.refreshing = false
+			m.mu.Unlock()
+			return
+		}
+		ids := make([]string, 0, len(m.pending))
+		for id := range m.pending {
+			ids = append(ids, id)
+		}
+		m.pending = map[string]struct{}{}
+		m.removed = map[string]struct{}{}
+		m.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(m.ctx, m.opts.refreshTimeout)
+		vals, err := m.fetch(ctx, ids)
+		cancel()
+
+		m.mu.Lock()
+		if err == nil {
+			loadedAt := m.now()
+			for i, val := range vals {
+				// The id was removed after the refresh started, the value may predate the write.
+				if _, ok := m.removed[ids[i]]; ok {
+					continue
+				}
+				m.cache.Add(ids[i], &entry{value: val, loadedAt: loadedAt})
+			}
+		}
+		m.removed = nil
+		m.mu.Unlock()
+
+		if err != nil {
+			m.opts.logger.Error("rtcache: background refresh",
+				slog.Int("ids", len(ids)), slog.Any("err", err))
+		}
+		timer.Reset(m.opts.refreshDelay)
+	}
+}
+
+func (m *MemReadThroughCache) get(id string) (*entry, bool) {
+	v, ok := m.cache.Get(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(*entry), true
+}
+
+func (m *MemReadThroughCache) stale(e *entry, now time.Time) bool {
+	return m.opts.softTTL > 0 && now.Sub(e.loadedAt) >= m.opts.softTTL
+}
+
+func (m *MemReadThroughCache) expired(e *entry, now time.Time) bool {
+	return m.opts.hardTTL > 0 && now.Sub(e.loadedAt) >= m.opts.hardTTL
+}
+
 // Keys implements the ReadThroughCache interface.
 func (m *MemReadThroughCache) Keys() []string {
 	keys := m.cache.Keys()
@@ -95,16 +258,35 @@ func (m *MemReadThroughCache) Keys() []string {
 	return ret
 }
 
-// Remove implements the ReadThroughCache interface.
+// Remove implements the ReadThroughCache interface. If an invalidation bus is
+// configured, the ids are removed on the other nodes as well.
 func (m *MemReadThroughCache) Remove(ids []string) {
+	m.remove(ids)
+	if m.opts.bus != nil {
+		_ = m.opts.bus.Publish(context.Background(), ids...)
+	}
+}
+
+// remove removes the ids locally, and keeps an in-flight refresh from caching them again.
+func (m *MemReadThroughCache) remove(ids []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, id := range ids {
 		m.cache.Remove(id)
+		if m.removed != nil {
+			m.removed[id] = struct{}{}
+		}
 	}
 }
 
-// Contains implements the ReadThroughCache interface.
+// Contains implements the ReadThroughCache interface. Items older than the hard TTL
+// are not considered cached.
 func (m *MemReadThroughCache) Contains(id string) bool {
-	return m.cache.Contains(id)
+	v, ok := m.cache.Peek(id)
+	if !ok {
+		return false
+	}
+	return !m.expired(v.(*entry), m.now())
 }
 
 // Len implements the ReadThroughCache interface.
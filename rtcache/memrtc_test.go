@@ -1,13 +1,19 @@
 package rtcache
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
 )
 
 // TestMemReadThroughCacheGetSunnyDay checks that we tiny_cache the values after reading them
@@ -198,6 +204,37 @@ func TestMemReadThroughCacheRemove(t *testing.T) {
 	}, called)
 }
 
+// TestMemReadThroughCacheRemoveWithInvalidationBus checks that ids removed on one node
+// are removed on the other nodes sharing the bus.
+func TestMemReadThroughCacheRemoveWithInvalidationBus(t *testing.T) {
+
+	const alpha = "alpha"
+	const beta = "beta"
+
+	_, rtFn := countingReadThroughFn(t)
+	bus := invalidation.NewMemoryBus()
+
+	node1, err := New(rtFn, 10, 10, WithInvalidationBus(bus))
+	require.NoError(t, err)
+	node2, err := New(rtFn, 10, 10, WithInvalidationBus(bus))
+	require.NoError(t, err)
+
+	_, err = node1.GetAll(context.Background(), []string{alpha})
+	require.NoError(t, err)
+	_, err = node2.GetAll(context.Background(), []string{alpha, beta})
+	require.NoError(t, err)
+
+	node1.Remove([]string{alpha})
+	assert.False(t, node1.Contains(alpha))
+	assert.False(t, node2.Contains(alpha))
+	assert.True(t, node2.Contains(beta))
+
+	// Stopped caches no longer receive invalidations.
+	node2.Stop()
+	node1.Remove([]string{beta})
+	assert.True(t, node2.Contains(beta))
+}
+
 // TestMemReadThroughCacheGetErrors checks that if a worker function call returns error, we will
 // try it again later
 func TestMemReadThroughCacheGetErrors(t *testing.T) {
@@ -297,3 +334,244 @@ func countingReadThroughFn(t *testing.T) (map[string]int, ReadThroughFunc) {
 	}
 	return called, rtFn
 }
+
+// TestMemReadThroughCacheStaleWhileRevalidate checks that items older than the soft TTL are
+// returned right away and refreshed in the background in a single batched call.
+func TestMemReadThroughCacheStaleWhileRevalidate(t *testing.T) {
+
+	const alpha = "alpha"
+	const beta = "beta"
+
+	var mu sync.Mutex
+	version := 1
+	var batches [][]string
+	rtFn := func(ctx context.Context, ids []string) ([]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, ids)
+		rv := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			rv = append(rv, fmt.Sprintf("%s%d", id, version))
+		}
+		return rv, nil
+	}
+
+	rtc, err := New(rtFn, 10, 10, WithSoftTTL(time.Minute), WithHardTTL(time.Hour))
+	require.NoError(t, err)
+	now := time.Now()
+	rtc.now = func() time.Time { return now }
+
+	all, err := rtc.GetAll(context.Background(), []string{alpha, beta})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"alpha1", "beta1"}, all)
+
+	mu.Lock()
+	version = 2
+	mu.Unlock()
+	now = now.Add(2 * time.Minute)
+
+	// Stale values are returned without blocking.
+	v, err := rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha1", v)
+	v, err = rtc.Get(context.Background(), beta)
+	require.NoError(t, err)
+	assert.Equal(t, "beta1", v)
+
+	assert.Eventually(t, func() bool {
+		e, ok := rtc.get(beta)
+		return ok && e.value == "beta2"
+	}, time.Second, 5*time.Millisecond)
+
+	v, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha2", v)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 2)
+	assert.ElementsMatch(t, []string{alpha, beta}, batches[1])
+}
+
+// TestMemReadThroughCacheHardTTL checks that items older than the hard TTL are read through
+// again before being returned.
+func TestMemReadThroughCacheHardTTL(t *testing.T) {
+
+	const alpha = "alpha"
+
+	called, rtFn := countingReadThroughFn(t)
+
+	rtc, err := New(rtFn, 10, 10, WithSoftTTL(time.Minute), WithHardTTL(time.Hour))
+	require.NoError(t, err)
+	now := time.Now()
+	rtc.now = func() time.Time { return now }
+
+	_, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.True(t, rtc.Contains(alpha))
+
+	now = now.Add(2 * time.Hour)
+	assert.False(t, rtc.Contains(alpha))
+
+	v, err := rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alphaalpha", v)
+	assert.Equal(t, map[string]int{
+		alpha: 2,
+	}, called)
+}
+
+// TestMemReadThroughCacheRefreshRemoveRace checks that a background refresh which started
+// before an id was removed doesn't cache the value it read before the removal.
+func TestMemReadThroughCacheRefreshRemoveRace(t *testing.T) {
+
+	const alpha = "alpha"
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	rtFn := func(ctx context.Context, ids []string) ([]interface{}, error) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 2 {
+			close(started)
+			<-release
+		}
+		return []interface{}{fmt.Sprintf("%s%d", ids[0], n)}, nil
+	}
+
+	bus := invalidation.NewMemoryBus()
+	rtc, err := New(rtFn, 10, 10, WithSoftTTL(time.Minute), WithInvalidationBus(bus))
+	require.NoError(t, err)
+	defer rtc.Stop()
+	now := time.Now()
+	rtc.now = func() time.Time { return now }
+
+	v, err := rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha1", v)
+
+	now = now.Add(2 * time.Minute)
+	v, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha1", v)
+
+	// The id is invalidated by another node while the refresh is in flight.
+	<-started
+	require.NoError(t, bus.Publish(context.Background(), alpha))
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		rtc.mu.Lock()
+		defer rtc.mu.Unlock()
+		return !rtc.refreshing
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, rtc.Contains(alpha))
+
+	v, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha3", v)
+}
+
+// TestMemReadThroughCacheRefreshTimeout checks that a hung background refresh is cancelled
+// after the refresh timeout, releases its read slot and logs the error.
+func TestMemReadThroughCacheRefreshTimeout(t *testing.T) {
+
+	const alpha = "alpha"
+
+	var mu sync.Mutex
+	calls := 0
+	rtFn := func(ctx context.Context, ids []string) ([]interface{}, error) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n > 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []interface{}{ids[0]}, nil
+	}
+
+	var buf bytes.Buffer
+	var bufMu sync.Mutex
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &bufMu, w: &buf}, nil))
+	rtc, err := New(rtFn, 10, 1, WithSoftTTL(time.Minute),
+		WithRefreshTimeout(20*time.Millisecond), WithLogger(logger))
+	require.NoError(t, err)
+	defer rtc.Stop()
+	now := time.Now()
+	rtc.now = func() time.Time { return now }
+
+	_, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		bufMu.Lock()
+		defer bufMu.Unlock()
+		return bytes.Contains(buf.Bytes(), []byte("context deadline exceeded"))
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return len(rtc.activeReadsCh) == 0
+	}, time.Second, 5*time.Millisecond)
+	// The stale value is kept.
+	assert.True(t, rtc.Contains(alpha))
+}
+
+// TestMemReadThroughCacheStopCancelsRefresh checks that Stop cancels an in-flight refresh.
+func TestMemReadThroughCacheStopCancelsRefresh(t *testing.T) {
+
+	const alpha = "alpha"
+
+	started := make(chan struct{})
+	rtFn := func(ctx context.Context, ids []string) ([]interface{}, error) {
+		select {
+		case <-started:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		default:
+			close(started)
+			return []interface{}{ids[0]}, nil
+		}
+	}
+
+	rtc, err := New(rtFn, 10, 10, WithSoftTTL(time.Minute), WithRefreshTimeout(time.Hour),
+		WithLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))))
+	require.NoError(t, err)
+	now := time.Now()
+	rtc.now = func() time.Time { return now }
+
+	_, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = rtc.Get(context.Background(), alpha)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(rtc.activeReadsCh) == 1
+	}, time.Second, time.Millisecond)
+	rtc.Stop()
+	assert.Eventually(t, func() bool {
+		rtc.mu.Lock()
+		defer rtc.mu.Unlock()
+		return !rtc.refreshing && len(rtc.activeReadsCh) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+// lockedWriter serializes writes to w.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,84 @@
+package rtcache
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/apus-run/sea-kit/collection/invalidation"
+)
+
+// Option configures a MemReadThroughCache.
+type Option func(*options)
+
+type options struct {
+	// softTTL is the age after which a cached item is stale. Stale items are
+	// returned immediately and refreshed in the background. 0 means never stale.
+	softTTL time.Duration
+	// hardTTL is the age after which a cached item is expired. Expired items are
+	// treated as misses and block on the ReadThroughFunc. 0 means never expired.
+	hardTTL time.Duration
+	// refreshDelay is how long a background refresh waits to collect more stale
+	// ids before calling the ReadThroughFunc once for all of them.
+	refreshDelay time.Duration
+	// refreshTimeout bounds each background ReadThroughFunc call.
+	refreshTimeout time.Duration
+	// bus is used to remove ids cluster-wide.
+	bus invalidation.Bus
+	// logger logs failed background refreshes.
+	logger *slog.Logger
+}
+
+// WithSoftTTL sets the age after which items are served stale and refreshed in the background.
+func WithSoftTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.softTTL = d
+	}
+}
+
+// WithHardTTL sets the age after which items must be read through again before being returned.
+func WithHardTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.hardTTL = d
+	}
+}
+
+// WithRefreshDelay sets how long background refreshes wait to batch stale ids together.
+func WithRefreshDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.refreshDelay = d
+	}
+}
+
+// WithRefreshTimeout sets the timeout of background refreshes (default 10s).
+func WithRefreshTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.refreshTimeout = d
+		}
+	}
+}
+
+// WithLogger sets the logger used to log failed background refreshes (default slog.Default()).
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithInvalidationBus removes ids cluster-wide through the given bus. Ids passed to Remove
+// are published to the bus, and ids received from the bus are removed locally.
+func WithInvalidationBus(bus invalidation.Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
+}
+
+func defaultOptions() *options {
+	return &options{
+		refreshDelay:   10 * time.Millisecond,
+		refreshTimeout: 10 * time.Second,
+		logger:         slog.Default(),
+	}
+}
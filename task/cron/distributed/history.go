@@ -0,0 +1,130 @@
+package distributed
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Run 一次任务执行的记录
+type Run struct {
+	JobID       string    `gorm:"primaryKey;size:128"`
+	ScheduledAt time.Time `gorm:"primaryKey"`
+	Instance    string    `gorm:"size:128"`
+	StartedAt   time.Time
+	// FinishedAt 为零值表示任务仍在执行或实例在执行中退出
+	FinishedAt time.Time
+	Error      string
+}
+
+// HistoryStore 保存任务执行记录, 同一任务同一调度时间只保留一条记录
+type HistoryStore interface {
+	// Save 保存执行记录, 任务开始和结束时各调用一次
+	Save(ctx context.Context, run *Run) error
+	// Last 返回调度时间最新的记录, 没有记录时返回 nil
+	Last(ctx context.Context, jobID string) (*Run, error)
+	// List 按调度时间倒序返回最近 limit 条记录
+	List(ctx context.Context, jobID string, limit int) ([]*Run, error)
+}
+
+// MemoryHistoryStore 进程内的 HistoryStore, 只适合单实例和测试.
+// 重启后记录全部丢失, 不能配合 MisfirePolicy 使用
+type MemoryHistoryStore struct {
+	mu   sync.RWMutex
+	runs map[string][]*Run
+}
+
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{runs: make(map[string][]*Run)}
+}
+
+func (s *MemoryHistoryStore) Save(_ context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *run
+	runs := s.runs[run.JobID]
+	for i, r := range runs {
+		if r.ScheduledAt.Equal(run.ScheduledAt) {
+			runs[i] = &cp
+			return nil
+		}
+	}
+	runs = append(runs, &cp)
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].ScheduledAt.After(runs[j].ScheduledAt)
+	})
+	s.runs[run.JobID] = runs
+	return nil
+}
+
+func (s *MemoryHistoryStore) Last(ctx context.Context, jobID string) (*Run, error) {
+	runs, err := s.List(ctx, jobID, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return runs[0], nil
+}
+
+func (s *MemoryHistoryStore) List(_ context.Context, jobID string, limit int) ([]*Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	runs := s.runs[jobID]
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	res := make([]*Run, 0, len(runs))
+	for _, r := range runs {
+		cp := *r
+		res = append(res, &cp)
+	}
+	return res, nil
+}
+
+// GormHistoryStore 把执行记录写入数据库
+type GormHistoryStore struct {
+	db    *gorm.DB
+	table string
+}
+
+// NewGormHistoryStore 创建 GormHistoryStore, 默认表名 cron_runs
+func NewGormHistoryStore(db *gorm.DB, table ...string) *GormHistoryStore {
+	s := &GormHistoryStore{db: db, table: "cron_runs"}
+	if len(table) > 0 && table[0] != "" {
+		s.table = table[0]
+	}
+	return s
+}
+
+// AutoMigrate 创建记录表
+func (s *GormHistoryStore) AutoMigrate() error {
+	return s.db.Table(s.table).AutoMigrate(&Run{})
+}
+
+func (s *GormHistoryStore) Save(ctx context.Context, run *Run) error {
+	return s.db.WithContext(ctx).Table(s.table).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(run).Error
+}
+
+func (s *GormHistoryStore) Last(ctx context.Context, jobID string) (*Run, error) {
+	runs, err := s.List(ctx, jobID, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return runs[0], nil
+}
+
+func (s *GormHistoryStore) List(ctx context.Context, jobID string, limit int) ([]*Run, error) {
+	var runs []*Run
+	q := s.db.WithContext(ctx).Table(s.table).
+		Where("job_id = ?", jobID).
+		Order("scheduled_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return runs, q.Find(&runs).Error
+}
@@ -0,0 +1,77 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/apus-run/sea-kit/redisx/redislock"
+)
+
+// Locker 保证同一次调度只会被一个实例执行
+type Locker interface {
+	// TryLock 尝试占有 key 直到 ttl 过期, 返回是否占有成功.
+	// 锁不会主动释放, 这样时钟稍慢的实例在同一调度时间触发时也无法再次执行
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// LockerFunc 函数形式的 Locker
+type LockerFunc func(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+func (f LockerFunc) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return f(ctx, key, ttl)
+}
+
+// RedisLocker 基于 redisx/redislock 的 Locker
+type RedisLocker struct {
+	client *redislock.Client
+}
+
+// NewRedisLocker 创建 RedisLocker, client 可以通过 redisx.Helper 获取
+func NewRedisLocker(client redis.Cmdable) *RedisLocker {
+	return &RedisLocker{client: redislock.NewClient(client)}
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	_, err := l.client.TryLock(ctx, key, ttl)
+	if errors.Is(err, redislock.ErrFailedToPreemptLock) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// EtcdLocker 基于 etcd 租约的 Locker, key 绑定到 ttl 的租约上, 租约过期后 key 被删除
+type EtcdLocker struct {
+	client *clientv3.Client
+}
+
+// NewEtcdLocker 创建 EtcdLocker
+func NewEtcdLocker(client *clientv3.Client) *EtcdLocker {
+	return &EtcdLocker{client: client}
+}
+
+func (l *EtcdLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	seconds := int64(ttl / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	lease, err := l.client.Grant(ctx, seconds)
+	if err != nil {
+		return false, err
+	}
+	resp, err := l.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, "", clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	if !resp.Succeeded {
+		// 没有抢到锁, 释放刚申请的租约
+		_, _ = l.client.Revoke(context.WithoutCancel(ctx), lease.ID)
+	}
+	return resp.Succeeded, nil
+}
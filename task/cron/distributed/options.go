@@ -0,0 +1,84 @@
+package distributed
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Option 调度器选项
+type Option func(*Scheduler)
+
+// WithHistoryStore 设置执行记录的存储, 默认使用 MemoryHistoryStore.
+// 多实例部署时应使用共享存储, 否则无法在停机后判断错过的调度
+func WithHistoryStore(store HistoryStore) Option {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+// WithInstance 设置实例标识, 默认 hostname-pid
+func WithInstance(instance string) Option {
+	return func(s *Scheduler) {
+		s.instance = instance
+	}
+}
+
+// WithKeyPrefix 设置锁 key 的前缀, 默认 "cron:"
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Scheduler) {
+		s.prefix = prefix
+	}
+}
+
+// WithLockTTL 设置每次调度占用锁的时间, 默认 1 分钟.
+// 应大于实例之间的时钟偏差, 可以小于任务的执行时间
+func WithLockTTL(ttl time.Duration) Option {
+	return func(s *Scheduler) {
+		s.lockTTL = ttl
+	}
+}
+
+// WithTimezone 设置解析 cron 表达式的时区, 默认 UTC
+func WithTimezone(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		s.timezone = loc
+	}
+}
+
+// WithMaxCatchUp 设置 MisfireCatchUp 最多补跑的次数, 默认 100
+func WithMaxCatchUp(n int) Option {
+	return func(s *Scheduler) {
+		s.maxCatchUp = n
+	}
+}
+
+// WithLogger 设置日志, 默认使用 slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// JobOption 任务选项
+type JobOption func(*job)
+
+// WithMisfirePolicy 设置停机期间错过调度的处理方式, 默认 MisfireSkip.
+//
+// 注意: 补跑依赖 HistoryStore.Last 返回的上一次调度, 必须通过 WithHistoryStore 配置持久化的存储
+// (例如 GormHistoryStore). 默认的 MemoryHistoryStore 在重启后丢失全部记录,
+// Last 返回 nil, 错过的调度不会被补跑, 此时 Add 会打印警告日志
+func WithMisfirePolicy(p MisfirePolicy) JobOption {
+	return func(j *job) {
+		j.misfire = p
+	}
+}
+
+func defaultInstance() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
@@ -0,0 +1,274 @@
+// Package distributed 在多个实例之间协调 cron 任务, 同一调度时间只有一个实例执行.
+//
+// 每次调度以 "前缀 + 任务 ID + 调度时间" 为 key 通过 Locker 抢占, 抢到的实例执行任务
+// 并把执行记录写入 HistoryStore. 启动时根据执行记录和 MisfirePolicy 处理停机期间错过的调度.
+//
+// Example:
+//
+//	s := distributed.New(distributed.NewRedisLocker(rdb),
+//		distributed.WithHistoryStore(distributed.NewGormHistoryStore(db)))
+//	s.MustAdd("dailyReport", "0 0 * * *", report, distributed.WithMisfirePolicy(distributed.MisfireFireOnce))
+//	s.Start(ctx)
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	cron "github.com/apus-run/sea-kit/task/cron/v2"
+)
+
+// MisfirePolicy 停机期间错过调度的处理方式
+type MisfirePolicy int
+
+const (
+	// MisfireSkip 忽略错过的调度
+	MisfireSkip MisfirePolicy = iota
+	// MisfireFireOnce 有错过的调度时在启动后补跑一次
+	MisfireFireOnce
+	// MisfireCatchUp 按调度时间顺序补跑每一次错过的调度
+	MisfireCatchUp
+)
+
+// maxLookback 查找错过的调度时最多回溯的时间
+const maxLookback = 31 * 24 * time.Hour
+
+type job struct {
+	id       string
+	schedule *cron.Schedule
+	run      func(ctx context.Context) error
+	misfire  MisfirePolicy
+}
+
+// Scheduler 分布式 cron 调度器
+type Scheduler struct {
+	cron   *cron.Cron
+	locker Locker
+	store  HistoryStore
+
+	instance   string
+	prefix     string
+	lockTTL    time.Duration
+	timezone   *time.Location
+	maxCatchUp int
+	logger     *slog.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*job
+	ctx  context.Context
+	wg   sync.WaitGroup
+}
+
+// New 创建调度器, locker 用于在实例之间抢占每一次调度
+func New(locker Locker, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		cron:       cron.New(),
+		locker:     locker,
+		store:      NewMemoryHistoryStore(),
+		instance:   defaultInstance(),
+		prefix:     "cron:",
+		lockTTL:    time.Minute,
+		timezone:   time.UTC,
+		maxCatchUp: 100,
+		logger:     slog.Default(),
+		jobs:       make(map[string]*job),
+		ctx:        context.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.cron.SetTimezone(s.timezone)
+	return s
+}
+
+// MustAdd 与 Add 相同, 失败时 panic
+func (s *Scheduler) MustAdd(jobID, cronExpr string, run func(ctx context.Context) error, opts ...JobOption) {
+	if err := s.Add(jobID, cronExpr, run, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Add 注册任务, 已存在同名任务时替换. cronExpr 的格式参考 cron.NewSchedule
+func (s *Scheduler) Add(jobID, cronExpr string, run func(ctx context.Context) error, opts ...JobOption) error {
+	if run == nil {
+		return errors.New("failed to add new cron job: run must be non-nil function")
+	}
+	schedule, err := cron.NewSchedule(cronExpr)
+	if err != nil {
+		return fmt.Errorf("failed to add new cron job: %w", err)
+	}
+	j := &job{id: jobID, schedule: schedule, run: run}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if _, ok := s.store.(*MemoryHistoryStore); ok && j.misfire != MisfireSkip {
+		s.logger.Warn("cron: misfire policy requires a persistent history store, missed runs are lost on restart with MemoryHistoryStore",
+			slog.String("job", jobID))
+	}
+
+	s.mu.Lock()
+	s.jobs[jobID] = j
+	s.mu.Unlock()
+
+	// 以 Schedule.Next 计算的调度时间作为抢占的 key, 不受定时器触发时间偏差的影响
+	return s.cron.AddJob(jobID, cronExpr, func(ctx context.Context) error {
+		scheduledAt, ok := cron.ScheduledTime(ctx)
+		if !ok {
+			scheduledAt = time.Now().Truncate(time.Second)
+		}
+		s.fire(j, scheduledAt)
+		return nil
+	})
+}
+
+// Remove 删除任务
+func (s *Scheduler) Remove(jobID string) {
+	s.mu.Lock()
+	delete(s.jobs, jobID)
+	s.mu.Unlock()
+	s.cron.Remove(jobID)
+}
+
+// Start 处理错过的调度并启动调度器, ctx 会传递给任务
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	for _, j := range jobs {
+		j := j
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.catchUp(ctx, j, now)
+		}()
+	}
+	s.cron.Start()
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	s.cron.Stop()
+	s.wg.Wait()
+}
+
+// History 按调度时间倒序返回任务最近 limit 条执行记录
+func (s *Scheduler) History(ctx context.Context, jobID string, limit int) ([]*Run, error) {
+	return s.store.List(ctx, jobID, limit)
+}
+
+// Wrap 把任务包装成 func(), 用于 task/cron 等只接受 func() 的调度器.
+// 调度时间按秒截断, 各实例的时钟偏差应小于 1 秒
+func (s *Scheduler) Wrap(jobID string, run func(ctx context.Context) error) func() {
+	j := &job{id: jobID, run: run}
+	return func() {
+		s.fire(j, time.Now().Truncate(time.Second))
+	}
+}
+
+func (s *Scheduler) fire(j *job, scheduledAt time.Time) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	s.mu.RLock()
+	ctx := s.ctx
+	s.mu.RUnlock()
+	if err := s.runOnce(ctx, j, scheduledAt); err != nil {
+		s.logger.ErrorContext(ctx, "cron: job failed",
+			slog.String("job", j.id), slog.Time("scheduled_at", scheduledAt), slog.Any("err", err))
+	}
+}
+
+// runOnce 抢占 scheduledAt 这次调度并执行, 没有抢到时返回 nil
+func (s *Scheduler) runOnce(ctx context.Context, j *job, scheduledAt time.Time) (err error) {
+	key := s.prefix + j.id + ":" + strconv.FormatInt(scheduledAt.Unix(), 10)
+	ok, err := s.locker.TryLock(ctx, key, s.lockTTL)
+	if err != nil || !ok {
+		return err
+	}
+
+	run := &Run{
+		JobID:       j.id,
+		ScheduledAt: scheduledAt,
+		Instance:    s.instance,
+		StartedAt:   time.Now(),
+	}
+	if err = s.store.Save(ctx, run); err != nil {
+		s.logger.ErrorContext(ctx, "cron: save run history failed", slog.String("job", j.id), slog.Any("err", err))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cron: job panic: %v", r)
+		}
+		run.FinishedAt = time.Now()
+		if err != nil {
+			run.Error = err.Error()
+		}
+		if serr := s.store.Save(context.WithoutCancel(ctx), run); serr != nil {
+			s.logger.ErrorContext(ctx, "cron: save run history failed", slog.String("job", j.id), slog.Any("err", serr))
+		}
+	}()
+	return j.run(ctx)
+}
+
+// catchUp 按 MisfirePolicy 补跑停机期间错过的调度
+func (s *Scheduler) catchUp(ctx context.Context, j *job, now time.Time) {
+	if j.misfire == MisfireSkip {
+		return
+	}
+	last, err := s.store.Last(ctx, j.id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "cron: load run history failed", slog.String("job", j.id), slog.Any("err", err))
+		return
+	}
+	if last == nil {
+		// 第一次运行, 没有错过的调度
+		return
+	}
+
+	limit := 1
+	if j.misfire == MisfireCatchUp {
+		limit = s.maxCatchUp
+	}
+	missed := s.missed(j.schedule, last.ScheduledAt, now)
+	if len(missed) == 0 {
+		return
+	}
+	if len(missed) > limit {
+		missed = missed[len(missed)-limit:]
+	}
+	for _, t := range missed {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.runOnce(ctx, j, t); err != nil {
+			s.logger.ErrorContext(ctx, "cron: misfired job failed",
+				slog.String("job", j.id), slog.Time("scheduled_at", t), slog.Any("err", err))
+		}
+	}
+}
+
+// missed 返回 (last, now] 之间应该触发的调度时间, 最多回溯 maxLookback
+func (s *Scheduler) missed(schedule *cron.Schedule, last, now time.Time) []time.Time {
+	start := last
+	if min := now.Add(-maxLookback); start.Before(min) {
+		start = min
+	}
+	var res []time.Time
+	for t := schedule.Next(start.In(s.timezone)); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		res = append(res, t)
+	}
+	return res
+}
@@ -0,0 +1,168 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// memoryLocker 进程内的 Locker, 模拟多个实例共享的锁
+func memoryLocker() Locker {
+	var (
+		mu   sync.Mutex
+		keys = map[string]time.Time{}
+	)
+	return LockerFunc(func(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if exp, ok := keys[key]; ok && time.Now().Before(exp) {
+			return false, nil
+		}
+		keys[key] = time.Now().Add(ttl)
+		return true, nil
+	})
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	locker := memoryLocker()
+	store := NewMemoryHistoryStore()
+
+	var calls int
+	run := func(ctx context.Context) error {
+		calls++
+		return errors.New("mock error")
+	}
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, instance := range []string{"a", "b"} {
+		s := New(locker, WithHistoryStore(store), WithInstance(instance))
+		require.NoError(t, s.Add("report", "* * * * *", run))
+		s.fire(s.jobs["report"], at)
+	}
+	assert.Equal(t, 1, calls)
+
+	runs, err := store.List(context.Background(), "report", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "a", runs[0].Instance)
+	assert.Equal(t, "mock error", runs[0].Error)
+	assert.False(t, runs[0].FinishedAt.IsZero())
+}
+
+func TestScheduler_ScheduledAt(t *testing.T) {
+	store := NewMemoryHistoryStore()
+	s := New(memoryLocker(), WithHistoryStore(store))
+	done := make(chan struct{}, 1)
+	require.NoError(t, s.Add("report", "* * * * * *", func(ctx context.Context) error {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+		return nil
+	}))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not run")
+	}
+	// 抢占的 key 和执行记录使用 Schedule.Next 计算的调度时间, 而不是定时器触发的时间
+	runs, err := store.List(context.Background(), "report", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Zero(t, runs[0].ScheduledAt.Nanosecond())
+	assert.False(t, runs[0].StartedAt.Before(runs[0].ScheduledAt))
+}
+
+func TestScheduler_RunOncePanic(t *testing.T) {
+	s := New(memoryLocker())
+	j := &job{id: "report", run: func(ctx context.Context) error { panic("boom") }}
+	err := s.runOnce(context.Background(), j, time.Now())
+	assert.EqualError(t, err, "cron: job panic: boom")
+}
+
+func TestScheduler_Misfire(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 20, 0, time.UTC)
+	last := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		policy MisfirePolicy
+		want   []time.Time
+	}{
+		{name: "skip", policy: MisfireSkip},
+		{
+			name:   "fire once",
+			policy: MisfireFireOnce,
+			want:   []time.Time{last.Add(30 * time.Minute)},
+		},
+		{
+			name:   "catch up",
+			policy: MisfireCatchUp,
+			want: []time.Time{
+				last.Add(10 * time.Minute),
+				last.Add(20 * time.Minute),
+				last.Add(30 * time.Minute),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryHistoryStore()
+			require.NoError(t, store.Save(context.Background(), &Run{JobID: "report", ScheduledAt: last}))
+
+			var got []time.Time
+			s := New(memoryLocker(), WithHistoryStore(store))
+			require.NoError(t, s.Add("report", "*/10 * * * *", func(ctx context.Context) error {
+				return nil
+			}, WithMisfirePolicy(tc.policy)))
+			j := s.jobs["report"]
+			run := j.run
+			j.run = func(ctx context.Context) error {
+				runs, _ := store.List(ctx, "report", 1)
+				got = append(got, runs[0].ScheduledAt)
+				return run(ctx)
+			}
+
+			s.catchUp(context.Background(), j, now)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGormHistoryStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	store := NewGormHistoryStore(db)
+	require.NoError(t, store.AutoMigrate())
+
+	ctx := context.Background()
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	run := &Run{JobID: "report", ScheduledAt: at, Instance: "a", StartedAt: at}
+	require.NoError(t, store.Save(ctx, run))
+	run.Error = "mock error"
+	run.FinishedAt = at.Add(time.Second)
+	require.NoError(t, store.Save(ctx, run))
+	require.NoError(t, store.Save(ctx, &Run{JobID: "report", ScheduledAt: at.Add(-time.Hour)}))
+
+	last, err := store.Last(ctx, "report")
+	require.NoError(t, err)
+	assert.Equal(t, "mock error", last.Error)
+	assert.True(t, last.ScheduledAt.Equal(at))
+
+	runs, err := store.List(ctx, "report", 0)
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	last, err = store.Last(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, last)
+}
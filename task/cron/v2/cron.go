@@ -3,68 +3,152 @@
 //
 // Example:
 //
-//	c := cron.New()
+//	c := cron.New(cron.WithMetrics("app", "cron"))
 //	c.MustAdd("dailyReport", "0 0 * * *", func() { ... })
+//	c.MustAddJob("sync", "*/5 * * * *", func(ctx context.Context) error { ... },
+//		cron.WithTimeout(time.Minute), cron.WithOverlap(cron.OverlapSkip))
 //	c.Start()
 package cron
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/apus-run/sea-kit/slogx"
+)
+
+// JobFunc is a cron job. The ctx is canceled when the job timeout is exceeded.
+type JobFunc func(ctx context.Context) error
+
+// OverlapPolicy controls what happens when a job is due while its previous run
+// is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapAllow runs the job concurrently with the previous run (default).
+	OverlapAllow OverlapPolicy = iota
+	// OverlapSkip skips the run if the previous one is still in progress.
+	OverlapSkip
+	// OverlapQueue waits for the previous run to finish before running.
+	// At most one run is queued, the following ones are skipped until it starts,
+	// and the queued run is skipped if the cron is stopped meanwhile.
+	OverlapQueue
 )
 
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	default:
+		return "allow"
+	}
+}
+
 type job struct {
+	id       string
+	expr     string
 	schedule *Schedule
-	run      func()
+	run      JobFunc
+	timeout  time.Duration
+	overlap  OverlapPolicy
+
+	queue   sync.Mutex
+	queued  atomic.Bool
+	running atomic.Int32
+
+	// timer fires the job at next, both are guarded by the Cron lock
+	timer *time.Timer
+	next  time.Time
+}
+
+type scheduledTimeKey struct{}
+
+// ScheduledTime returns the time the job run was scheduled for,
+// which may be slightly earlier than the actual start time.
+func ScheduledTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(scheduledTimeKey{}).(time.Time)
+	return t, ok
+}
+
+// JobInfo describes a registered cron job.
+type JobInfo struct {
+	ID      string
+	Expr    string
+	Timeout time.Duration
+	Overlap OverlapPolicy
+	// Running is the number of runs currently in progress.
+	Running int
+	// Next is the next time the job is due, zero if it will never run.
+	Next time.Time
 }
 
 // Cron is a crontab-like struct for tasks/jobs scheduling.
+//
+// Each job is driven by its own timer armed at Schedule.Next,
+// so the 6 segments expressions run at their exact second
+// and the 5 segments ones only at second 0.
 type Cron struct {
-	timezone   *time.Location
-	ticker     *time.Ticker
-	startTimer *time.Timer
-	jobs       map[string]*job
-	interval   time.Duration
+	timezone *time.Location
+	// done is closed on Stop, nil when the cron is not started
+	done     chan struct{}
+	jobs     map[string]*job
+	interval time.Duration
+
+	logger  *slog.Logger
+	metrics *metrics
+	running sync.WaitGroup
 
 	sync.RWMutex
 }
 
-// New create a new Cron struct with default tick interval of 1 minute
-// and timezone in UTC.
+// New create a new Cron struct with timezone in UTC.
 //
-// You can change the default tick interval with Cron.SetInterval().
 // You can change the default timezone with Cron.SetTimezone().
-func New() *Cron {
-	return &Cron{
+func New(opts ...Option) *Cron {
+	c := &Cron{
 		interval: 1 * time.Minute,
 		timezone: time.UTC,
 		jobs:     map[string]*job{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// SetInterval changes the current cron tick interval
-// (it usually should be >= 1 minute).
+// SetInterval changes the current cron tick interval.
+//
+// Deprecated: the jobs are fired at their Schedule.Next time,
+// the interval no longer affects when they run.
 func (c *Cron) SetInterval(d time.Duration) {
-	// update interval
 	c.Lock()
-	wasStarted := c.ticker != nil
-	c.interval = d
-	c.Unlock()
+	defer c.Unlock()
 
-	// restart the ticker
-	if wasStarted {
-		c.Start()
-	}
+	c.interval = d
 }
 
-// SetTimezone changes the current cron tick timezone.
+// SetTimezone changes the current cron timezone.
+//
+// The started jobs are rescheduled in the new timezone.
 func (c *Cron) SetTimezone(l *time.Location) {
 	c.Lock()
 	defer c.Unlock()
 
 	c.timezone = l
+	if c.done != nil {
+		now := time.Now()
+		for _, j := range c.jobs {
+			c.schedule(j, now)
+		}
+	}
 }
 
 // MustAdd is similar to Add() but panic on failure.
@@ -86,18 +170,53 @@ func (c *Cron) Add(jobId string, cronExpr string, run func()) error {
 		return errors.New("failed to add new cron job: run must be non-nil function")
 	}
 
-	c.Lock()
-	defer c.Unlock()
+	return c.AddJob(jobId, cronExpr, func(context.Context) error {
+		run()
+		return nil
+	})
+}
+
+// MustAddJob is similar to AddJob() but panic on failure.
+func (c *Cron) MustAddJob(jobId string, cronExpr string, run JobFunc, opts ...JobOption) {
+	if err := c.AddJob(jobId, cronExpr, run, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// AddJob registers a single cron job with a context aware run function.
+//
+// The returned error and any panic are logged and counted as a failure.
+// Use WithTimeout() and WithOverlap() to control how the job runs.
+func (c *Cron) AddJob(jobId string, cronExpr string, run JobFunc, opts ...JobOption) error {
+	if run == nil {
+		return errors.New("failed to add new cron job: run must be non-nil function")
+	}
 
 	schedule, err := NewSchedule(cronExpr)
 	if err != nil {
 		return fmt.Errorf("failed to add new cron job: %w", err)
 	}
 
-	c.jobs[jobId] = &job{
+	j := &job{
+		id:       jobId,
+		expr:     cronExpr,
 		schedule: schedule,
 		run:      run,
 	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if old, ok := c.jobs[jobId]; ok {
+		old.stopTimer()
+	}
+	c.jobs[jobId] = j
+	if c.done != nil {
+		c.schedule(j, time.Now())
+	}
 
 	return nil
 }
@@ -107,6 +226,9 @@ func (c *Cron) Remove(jobId string) {
 	c.Lock()
 	defer c.Unlock()
 
+	if j, ok := c.jobs[jobId]; ok {
+		j.stopTimer()
+	}
 	delete(c.jobs, jobId)
 }
 
@@ -115,6 +237,9 @@ func (c *Cron) RemoveAll() {
 	c.Lock()
 	defer c.Unlock()
 
+	for _, j := range c.jobs {
+		j.stopTimer()
+	}
 	c.jobs = map[string]*job{}
 }
 
@@ -126,74 +251,214 @@ func (c *Cron) Total() int {
 	return len(c.jobs)
 }
 
-// Stop stops the current cron ticker (if not already).
+// Next returns the next time the job is due after now.
 //
-// You can resume the ticker by calling Start().
+// It returns false if there is no such job or the job will never run again.
+func (c *Cron) Next(jobId string) (time.Time, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	j, ok := c.jobs[jobId]
+	if !ok {
+		return time.Time{}, false
+	}
+	next := c.next(j, time.Now())
+	return next, !next.IsZero()
+}
+
+// List returns the registered cron jobs sorted by id.
+func (c *Cron) List() []JobInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	now := time.Now()
+	res := make([]JobInfo, 0, len(c.jobs))
+	for _, j := range c.jobs {
+		res = append(res, JobInfo{
+			ID:      j.id,
+			Expr:    j.expr,
+			Timeout: j.timeout,
+			Overlap: j.overlap,
+			Running: int(j.running.Load()),
+			Next:    c.next(j, now),
+		})
+	}
+	sort.Slice(res, func(i, k int) bool {
+		return res[i].ID < res[k].ID
+	})
+	return res
+}
+
+// next returns the first time after t the job is due in the cron timezone.
+func (c *Cron) next(j *job, t time.Time) time.Time {
+	if !j.next.IsZero() {
+		return j.next.In(c.timezone)
+	}
+	return j.schedule.Next(t.In(c.timezone))
+}
+
+// Stop stops the job timers (if not already)
+// and waits for the running jobs to finish.
+//
+// You can resume the cron by calling Start().
 func (c *Cron) Stop() {
+	c.stop()
+	c.running.Wait()
+}
+
+func (c *Cron) stop() {
 	c.Lock()
 	defer c.Unlock()
 
-	if c.startTimer != nil {
-		c.startTimer.Stop()
-		c.startTimer = nil
-	}
-
-	if c.ticker == nil {
+	if c.done == nil {
 		return // already stopped
 	}
 
-	c.ticker.Stop()
-	c.ticker = nil
+	for _, j := range c.jobs {
+		j.stopTimer()
+	}
+	close(c.done)
+	c.done = nil
 }
 
-// Start starts the cron ticker.
+// Start arms the timer of each job at its next due time.
 //
-// Calling Start() on already started cron will restart the ticker.
+// Calling Start() on already started cron will reschedule the jobs.
 func (c *Cron) Start() {
-	c.Stop()
-
-	// delay the ticker to start at 00 of 1 c.interval duration
-	now := time.Now()
-	next := now.Add(c.interval).Truncate(c.interval)
-	delay := next.Sub(now)
+	c.stop()
 
 	c.Lock()
-	c.startTimer = time.AfterFunc(delay, func() {
-		c.Lock()
-		c.ticker = time.NewTicker(c.interval)
-		c.Unlock()
-
-		// run immediately at 00
-		c.runDue(time.Now())
-
-		// run after each tick
-		go func() {
-			for t := range c.ticker.C {
-				c.runDue(t)
-			}
-		}()
-	})
-	c.Unlock()
+	defer c.Unlock()
+
+	c.done = make(chan struct{})
+	now := time.Now()
+	for _, j := range c.jobs {
+		c.schedule(j, now)
+	}
 }
 
-// HasStarted checks whether the current Cron ticker has been started.
+// HasStarted checks whether the current Cron has been started.
 func (c *Cron) HasStarted() bool {
 	c.RLock()
 	defer c.RUnlock()
 
-	return c.ticker != nil
+	return c.done != nil
 }
 
-// runDue runs all registered jobs that are scheduled for the provided time.
-func (c *Cron) runDue(t time.Time) {
-	c.RLock()
-	defer c.RUnlock()
+// schedule arms the job timer at its first due time after t.
+// It must be called with the Cron lock held.
+func (c *Cron) schedule(j *job, t time.Time) {
+	j.stopTimer()
 
-	moment := NewMoment(t.In(c.timezone))
+	next := j.schedule.Next(t.In(c.timezone))
+	if next.IsZero() {
+		return // never due again
+	}
+	j.next = next
 
-	for _, j := range c.jobs {
-		if j.schedule.IsDue(moment) {
-			go j.run()
+	done := c.done
+	j.timer = time.AfterFunc(time.Until(next), func() {
+		c.fire(j, next, done)
+	})
+}
+
+// fire runs the job scheduled at the provided time and arms its next run.
+func (c *Cron) fire(j *job, at time.Time, done chan struct{}) {
+	c.Lock()
+	defer c.Unlock()
+
+	select {
+	case <-done:
+		return // stopped or restarted
+	default:
+	}
+	if c.jobs[j.id] != j {
+		return // removed or replaced
+	}
+
+	c.running.Add(1)
+	go func() {
+		defer c.running.Done()
+		c.runJob(j, at, done)
+	}()
+
+	// the runs missed while the process was suspended are skipped
+	after := time.Now()
+	if after.Before(at) {
+		after = at
+	}
+	c.schedule(j, after)
+}
+
+func (j *job) stopTimer() {
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+	j.next = time.Time{}
+}
+
+// runJob runs a single job applying its overlap policy and timeout.
+// The done channel is the one of the Start() that fired the run.
+func (c *Cron) runJob(j *job, at time.Time, done <-chan struct{}) {
+	switch j.overlap {
+	case OverlapSkip:
+		if !j.running.CompareAndSwap(0, 1) {
+			c.log().Warn("cron job skipped, previous run still in progress", slog.String("job", j.id))
+			return
+		}
+	case OverlapQueue:
+		if !j.queued.CompareAndSwap(false, true) {
+			c.log().Warn("cron job skipped, a run is already queued", slog.String("job", j.id))
+			return
 		}
+		j.queue.Lock()
+		j.queued.Store(false)
+		defer j.queue.Unlock()
+		select {
+		case <-done:
+			c.log().Warn("cron job skipped, cron stopped while queued", slog.String("job", j.id))
+			return
+		default:
+		}
+		j.running.Add(1)
+	default:
+		j.running.Add(1)
+	}
+	defer j.running.Add(-1)
+
+	logger := c.log().With(slog.String("job", j.id))
+	ctx := slogx.NewContext(context.WithValue(context.Background(), scheduledTimeKey{}, at), logger)
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := safeRun(ctx, j.run)
+	elapsed := time.Since(start)
+	c.metrics.observe(j.id, elapsed, err)
+
+	if err != nil {
+		logger.ErrorContext(ctx, "cron job failed", slog.Duration("elapsed", elapsed), slogx.ErrorString(err))
+		return
+	}
+	logger.InfoContext(ctx, "cron job finished", slog.Duration("elapsed", elapsed))
+}
+
+func (c *Cron) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
 	}
+	return slog.Default()
+}
+
+func safeRun(ctx context.Context, run JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cron job panic: %v", r)
+		}
+	}()
+	return run(ctx)
 }
@@ -1,9 +1,14 @@
 package cron
 
 import (
+	"context"
 	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestCronNew(t *testing.T) {
@@ -25,8 +30,8 @@ func TestCronNew(t *testing.T) {
 		t.Fatalf("Expected no jobs by default, got \n%v", c.jobs)
 	}
 
-	if c.ticker != nil {
-		t.Fatal("Expected the ticker NOT to be initialized")
+	if c.HasStarted() {
+		t.Fatal("Expected the cron NOT to be started")
 	}
 }
 
@@ -216,17 +221,15 @@ func TestCronStartStop(t *testing.T) {
 
 	c := New()
 
-	c.SetInterval(500 * time.Millisecond)
-
-	c.Add("test1", "* * * * *", func() {
+	c.Add("test1", "* * * * * *", func() {
 		test1++
 	})
 
-	c.Add("test2", "* * * * *", func() {
+	c.Add("test2", "* * * * * *", func() {
 		test2++
 	})
 
-	expectedCalls := 2
+	expectedCalls := 1
 
 	// call twice Start to check if the previous ticker will be reseted
 	c.Start()
@@ -252,7 +255,7 @@ func TestCronStartStop(t *testing.T) {
 
 	c.Stop()
 
-	expectedCalls += 4
+	expectedCalls += 2
 
 	if test1 != expectedCalls {
 		t.Fatalf("Expected %d test1, got %d", expectedCalls, test1)
@@ -261,3 +264,268 @@ func TestCronStartStop(t *testing.T) {
 		t.Fatalf("Expected %d test2, got %d", expectedCalls, test2)
 	}
 }
+
+func TestCronAddJobOverlap(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		overlap  OverlapPolicy
+		expected int32
+	}{
+		{OverlapAllow, 3},
+		{OverlapSkip, 1},
+		// one run is queued, the third one is skipped
+		{OverlapQueue, 2},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.overlap.String(), func(t *testing.T) {
+			c := New()
+
+			var calls, concurrent, maxConcurrent atomic.Int32
+			c.MustAddJob("test", "* * * * *", func(ctx context.Context) error {
+				calls.Add(1)
+				n := concurrent.Add(1)
+				defer concurrent.Add(-1)
+				for {
+					m := maxConcurrent.Load()
+					if n <= m || maxConcurrent.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			}, WithOverlap(s.overlap))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c.runJob(c.jobs["test"], time.Now(), nil)
+				}()
+			}
+			wg.Wait()
+
+			if v := calls.Load(); v != s.expected {
+				t.Fatalf("Expected %d calls, got %d", s.expected, v)
+			}
+			if s.overlap == OverlapQueue && maxConcurrent.Load() != 1 {
+				t.Fatalf("Expected queued runs not to overlap, got %d concurrent runs", maxConcurrent.Load())
+			}
+		})
+	}
+}
+
+func TestCronOverlapQueueStop(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c.MustAddJob("test", "* * * * *", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	}, WithOverlap(OverlapQueue))
+	j := c.jobs["test"]
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runJob(j, time.Now(), done)
+	}()
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runJob(j, time.Now(), done)
+	}()
+	for !j.queued.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the cron is stopped while the second run is queued
+	close(done)
+	close(release)
+	wg.Wait()
+
+	if v := calls.Load(); v != 1 {
+		t.Fatalf("Expected the queued run to be skipped after stop, got %d calls", v)
+	}
+}
+
+func TestCronAddJobTimeoutAndMetrics(t *testing.T) {
+	t.Parallel()
+
+	c := New(WithMetrics("test", "cron_timeout"))
+
+	c.MustAddJob("timeout", "* * * * *", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+	c.MustAddJob("panic", "* * * * *", func(ctx context.Context) error {
+		panic("boom")
+	})
+	c.MustAddJob("success", "* * * * *", func(ctx context.Context) error {
+		return nil
+	})
+
+	for _, id := range []string{"timeout", "panic", "success"} {
+		c.runJob(c.jobs[id], time.Now(), nil)
+	}
+
+	if v := testutil.ToFloat64(c.metrics.failures.WithLabelValues("timeout")); v != 1 {
+		t.Fatalf("Expected 1 timeout failure, got %v", v)
+	}
+	if v := testutil.ToFloat64(c.metrics.failures.WithLabelValues("panic")); v != 1 {
+		t.Fatalf("Expected 1 panic failure, got %v", v)
+	}
+	if v := testutil.ToFloat64(c.metrics.lastSuccess.WithLabelValues("success")); v == 0 {
+		t.Fatal("Expected the last success timestamp to be set")
+	}
+	if v := testutil.CollectAndCount(c.metrics.duration); v != 3 {
+		t.Fatalf("Expected 3 duration series, got %d", v)
+	}
+}
+
+func TestCronNextAndList(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	c.MustAdd("hourly", "@hourly", func() {})
+	c.MustAddJob("daily", "@daily", func(ctx context.Context) error { return nil },
+		WithTimeout(time.Minute), WithOverlap(OverlapSkip))
+
+	now := time.Now().UTC()
+
+	next, ok := c.Next("hourly")
+	if !ok {
+		t.Fatal("Expected hourly to have a next run")
+	}
+	if expected := now.Truncate(time.Hour).Add(time.Hour); !next.Equal(expected) {
+		t.Fatalf("Expected next hourly run %v, got %v", expected, next)
+	}
+
+	if _, ok := c.Next("missing"); ok {
+		t.Fatal("Expected missing job not to have a next run")
+	}
+
+	list := c.List()
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(list))
+	}
+	if list[0].ID != "daily" || list[0].Overlap != OverlapSkip || list[0].Timeout != time.Minute {
+		t.Fatalf("Unexpected daily job info %+v", list[0])
+	}
+	if list[0].Next.Hour() != 0 || list[0].Next.Minute() != 0 {
+		t.Fatalf("Expected daily job to run at midnight, got %v", list[0].Next)
+	}
+}
+
+func TestCronStopWaitsForRunningJobs(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	var finished atomic.Bool
+	started := make(chan struct{}, 1)
+	c.MustAddJob("slow", "* * * * * *", func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		time.Sleep(200 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	}, WithOverlap(OverlapSkip))
+
+	c.Start()
+	<-started
+	c.Stop()
+
+	if !finished.Load() {
+		t.Fatal("Expected Stop to wait for the running job")
+	}
+}
+
+func TestCronMixedSegments(t *testing.T) {
+	t.Parallel()
+
+	// the interval must not affect when the jobs run
+	for _, interval := range []time.Duration{time.Minute, time.Second} {
+		interval := interval
+		t.Run(interval.String(), func(t *testing.T) {
+			t.Parallel()
+
+			c := New()
+			c.SetInterval(interval)
+
+			var mu sync.Mutex
+			runs := map[string][]time.Time{}
+			for id, expr := range map[string]string{
+				"everySecond": "* * * * * *",
+				"minutely":    "* * * * *",
+				"at30":        "30 * * * * *",
+			} {
+				id := id
+				c.MustAddJob(id, expr, func(ctx context.Context) error {
+					at, _ := ScheduledTime(ctx)
+					mu.Lock()
+					runs[id] = append(runs[id], at)
+					mu.Unlock()
+					return nil
+				})
+			}
+
+			c.Start()
+			time.Sleep(2500 * time.Millisecond)
+			c.Stop()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if n := len(runs["everySecond"]); n < 2 || n > 3 {
+				t.Fatalf("Expected everySecond to run 2-3 times, got %d", n)
+			}
+			for i, at := range runs["everySecond"] {
+				if at.Nanosecond() != 0 {
+					t.Fatalf("Expected everySecond to run at whole seconds, got %v", at)
+				}
+				if i > 0 && !at.After(runs["everySecond"][i-1]) {
+					t.Fatalf("Expected everySecond to run once per second, got %v", runs["everySecond"])
+				}
+			}
+			for _, at := range runs["minutely"] {
+				if at.Second() != 0 {
+					t.Fatalf("Expected minutely to run at second 0, got %v", at)
+				}
+			}
+			for _, at := range runs["at30"] {
+				if at.Second() != 30 {
+					t.Fatalf("Expected at30 to run at second 30, got %v", at)
+				}
+			}
+
+			for _, info := range c.List() {
+				switch info.ID {
+				case "minutely":
+					if info.Next.Second() != 0 {
+						t.Fatalf("Expected minutely next run at second 0, got %v", info.Next)
+					}
+				case "at30":
+					if info.Next.Second() != 30 {
+						t.Fatalf("Expected at30 next run at second 30, got %v", info.Next)
+					}
+				}
+			}
+		})
+	}
+}
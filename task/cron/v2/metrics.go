@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	kitmetrics "github.com/apus-run/sea-kit/metrics"
+)
+
+type metrics struct {
+	duration    *prometheus.HistogramVec
+	failures    *prometheus.CounterVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+func newMetrics(namespace, subsystem string) (*metrics, error) {
+	duration, err := kitmetrics.Register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of cron job runs.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"}))
+	if err != nil {
+		return nil, err
+	}
+	failures, err := kitmetrics.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "job_failures_total",
+		Help:      "Number of failed cron job runs.",
+	}, []string{"job"}))
+	if err != nil {
+		return nil, err
+	}
+	lastSuccess, err := kitmetrics.Register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful cron job run.",
+	}, []string{"job"}))
+	if err != nil {
+		return nil, err
+	}
+	return &metrics{duration: duration, failures: failures, lastSuccess: lastSuccess}, nil
+}
+
+func (m *metrics) observe(jobId string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.duration.WithLabelValues(jobId).Observe(elapsed.Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(jobId).Inc()
+		return
+	}
+	m.lastSuccess.WithLabelValues(jobId).SetToCurrentTime()
+}
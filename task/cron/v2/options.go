@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures a Cron.
+type Option func(*Cron)
+
+// WithLogger sets the logger used to log each job run (default slog.Default()).
+//
+// The logger, with the job id attached, is also stored in the job context
+// and can be retrieved with slogx.FromContext().
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cron) {
+		c.logger = logger
+	}
+}
+
+// WithMetrics enables the prometheus job metrics registered
+// under the provided namespace and subsystem.
+//
+// Creating multiple Cron with the same namespace and subsystem shares the metrics.
+func WithMetrics(namespace, subsystem string) Option {
+	return func(c *Cron) {
+		m, err := newMetrics(namespace, subsystem)
+		if err != nil {
+			panic(err)
+		}
+		c.metrics = m
+	}
+}
+
+// JobOption configures a single cron job.
+type JobOption func(*job)
+
+// WithTimeout cancels the job context after d.
+//
+// The job should return once its context is done, the run is not abandoned.
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// WithOverlap sets the job overlap policy (default OverlapAllow).
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = p
+	}
+}
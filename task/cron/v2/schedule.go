@@ -10,48 +10,84 @@ import (
 
 // Moment represents a parsed single time moment.
 type Moment struct {
+	Second    int `json:"second"`
 	Minute    int `json:"minute"`
 	Hour      int `json:"hour"`
 	Day       int `json:"day"`
 	Month     int `json:"month"`
+	Year      int `json:"year"`
 	DayOfWeek int `json:"dayOfWeek"`
 }
 
 // NewMoment creates a new Moment from the specified time.
 func NewMoment(t time.Time) *Moment {
 	return &Moment{
+		Second:    t.Second(),
 		Minute:    t.Minute(),
 		Hour:      t.Hour(),
 		Day:       t.Day(),
 		Month:     int(t.Month()),
+		Year:      t.Year(),
 		DayOfWeek: int(t.Weekday()),
 	}
 }
 
 // Schedule stores parsed information for each time component when a cron job should run.
 type Schedule struct {
+	// Seconds is nil for the 5 segments expressions, which run at second 0.
+	Seconds    map[int]struct{} `json:"seconds,omitempty"`
 	Minutes    map[int]struct{} `json:"minutes"`
 	Hours      map[int]struct{} `json:"hours"`
 	Days       map[int]struct{} `json:"days"`
 	Months     map[int]struct{} `json:"months"`
 	DaysOfWeek map[int]struct{} `json:"daysOfWeek"`
+
+	// day of the month modifiers
+	lastDay     bool             // L
+	lastDayOff  map[int]struct{} // L-n
+	lastWeekday bool             // LW
+	nearestWeek map[int]struct{} // nW
+
+	// day of the week modifiers
+	lastDow map[int]struct{}    // nL
+	nthDow  map[[2]int]struct{} // n#k
+
+	every    time.Duration
+	location *time.Location
+}
+
+// Location returns the CRON_TZ location of the schedule, nil if not specified.
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// Every returns the "@every" interval of the schedule, 0 for the regular expressions.
+func (s *Schedule) Every() time.Duration {
+	return s.every
 }
 
 // IsDue checks whether the provided Moment satisfies the current Schedule.
+//
+// The 5 segments expressions are due only at second 0 and
+// "@every" schedules are never due (use Next() instead).
 func (s *Schedule) IsDue(m *Moment) bool {
-	if _, ok := s.Minutes[m.Minute]; !ok {
+	if s.every > 0 {
 		return false
 	}
 
-	if _, ok := s.Hours[m.Hour]; !ok {
+	if !s.matchSecond(m.Second) {
 		return false
 	}
 
-	if _, ok := s.Days[m.Day]; !ok {
+	if _, ok := s.Minutes[m.Minute]; !ok {
 		return false
 	}
 
-	if _, ok := s.DaysOfWeek[m.DayOfWeek]; !ok {
+	if _, ok := s.Hours[m.Hour]; !ok {
+		return false
+	}
+
+	if !s.matchDay(m.Year, m.Month, m.Day, m.DayOfWeek) {
 		return false
 	}
 
@@ -62,6 +98,183 @@ func (s *Schedule) IsDue(m *Moment) bool {
 	return true
 }
 
+// Next returns the first time after the provided one that satisfies the Schedule,
+// or the zero time if there is no such time within the next 5 years.
+//
+// The regular expressions are evaluated in the CRON_TZ location or, if not specified,
+// in the location of after. Across DST transitions the wall clock times that don't exist
+// are skipped and the repeated ones fire only once (unless the hour segment is a wildcard).
+func (s *Schedule) Next(after time.Time) time.Time {
+	if s.every > 0 {
+		return after.Truncate(time.Second).Add(s.every)
+	}
+
+	loc := after.Location()
+	if s.location != nil {
+		loc = s.location
+	}
+
+	t := after.In(loc)
+	// start from the next whole second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	added := false
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for !has(s.Months, int(t.Month())) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !s.matchDay(t.Year(), int(t.Month()), t.Day(), int(t.Weekday())) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		// the midnight may not exist because of DST, adjust to the closest hour 0
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for !has(s.Hours, t.Hour()) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for !has(s.Minutes, t.Minute()) {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for !s.matchSecond(t.Second()) {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	// skip the second occurrence of a repeated wall clock time (DST end)
+	// for the schedules that run at specific hours
+	if len(s.Hours) < 24 {
+		if prev := t.Add(-time.Hour); prev.Hour() == t.Hour() && prev.Minute() == t.Minute() {
+			t = t.Add(time.Second)
+			added = true
+			goto WRAP
+		}
+	}
+
+	return t.In(after.Location())
+}
+
+func (s *Schedule) matchSecond(sec int) bool {
+	if s.Seconds == nil {
+		return sec == 0
+	}
+	return has(s.Seconds, sec)
+}
+
+// matchDay checks both the day of the month and the day of the week segments.
+func (s *Schedule) matchDay(year, month, day, dow int) bool {
+	last := daysIn(year, month)
+	return s.matchDayOfMonth(year, month, day, last) && s.matchDayOfWeek(day, dow, last)
+}
+
+func (s *Schedule) matchDayOfMonth(year, month, day, last int) bool {
+	if has(s.Days, day) {
+		return true
+	}
+	if s.lastDay && day == last {
+		return true
+	}
+	for off := range s.lastDayOff {
+		if day == last-off {
+			return true
+		}
+	}
+	if s.lastWeekday && day == nearestWeekday(year, month, last, last) {
+		return true
+	}
+	for d := range s.nearestWeek {
+		if d <= last && day == nearestWeekday(year, month, d, last) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) matchDayOfWeek(day, dow, last int) bool {
+	if has(s.DaysOfWeek, dow) {
+		return true
+	}
+	if has(s.lastDow, dow) && day+7 > last {
+		return true
+	}
+	_, ok := s.nthDow[[2]int{dow, (day-1)/7 + 1}]
+	return ok
+}
+
+// nearestWeekday returns the weekday (Mon-Fri) nearest to the day without leaving the month.
+func nearestWeekday(year, month, day, last int) int {
+	switch time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	default:
+		return day
+	}
+}
+
+func daysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func has(set map[int]struct{}, v int) bool {
+	_, ok := set[v]
+	return ok
+}
+
 var macros = map[string]string{
 	"@yearly":   "0 0 1 1 *",
 	"@annually": "0 0 1 1 *",
@@ -72,16 +285,41 @@ var macros = map[string]string{
 	"@hourly":   "0 * * * *",
 }
 
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dowNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
 // NewSchedule creates a new Schedule from a cron expression.
 //
 // A cron expression could be a macro OR 5 segments separated by space,
 // representing: minute, hour, day of the month, month and day of the week.
+// An optional leading seconds segment could be added (6 segments).
+//
+// The expression could be prefixed with "CRON_TZ=<location> " (or "TZ=<location> ")
+// to evaluate it in a specific timezone.
 //
 // The following segment formats are supported:
-//   - wildcard: *
+//   - wildcard: * (or ? for the day of the month and the day of the week)
 //   - range:    1-30
 //   - step:     */n or 1-30/n
 //   - list:     1,2,3,10-20/n
+//   - names:    JAN-DEC for the month and SUN-SAT for the day of the week
+//
+// The following day of the month modifiers are supported:
+//   - L:   the last day of the month
+//   - L-n: n days before the last day of the month
+//   - nW:  the weekday nearest to the day n
+//   - LW:  the last weekday of the month
+//
+// The following day of the week modifiers are supported:
+//   - nL:  the last day n of the month, eg. 5L is the last friday
+//   - n#k: the k-th day n of the month, eg. 1#2 is the second monday
 //
 // The following macros are supported:
 //   - @yearly (or @annually)
@@ -89,48 +327,185 @@ var macros = map[string]string{
 //   - @weekly
 //   - @daily (or @midnight)
 //   - @hourly
+//   - @every <duration>, eg. @every 90s
 func NewSchedule(cronExpr string) (*Schedule, error) {
+	var loc *time.Location
+	if strings.HasPrefix(cronExpr, "CRON_TZ=") || strings.HasPrefix(cronExpr, "TZ=") {
+		tz, rest, _ := strings.Cut(cronExpr, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron timezone %q: %w", name, err)
+		}
+		loc = l
+		cronExpr = strings.TrimSpace(rest)
+	}
+
+	if every, ok := strings.CutPrefix(cronExpr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration: %w", err)
+		}
+		if d < time.Second {
+			return nil, errors.New("invalid @every duration - must be at least 1 second")
+		}
+		return &Schedule{every: d, location: loc}, nil
+	}
+
 	if v, ok := macros[cronExpr]; ok {
 		cronExpr = v
 	}
 
 	segments := strings.Split(cronExpr, " ")
-	if len(segments) != 5 {
-		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 space separated segments")
+	if len(segments) != 5 && len(segments) != 6 {
+		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 or 6 space separated segments")
 	}
 
-	minutes, err := parseCronSegment(segments[0], 0, 59)
-	if err != nil {
+	s := &Schedule{location: loc}
+
+	if len(segments) == 6 {
+		seconds, err := parseCronSegment(segments[0], 0, 59)
+		if err != nil {
+			return nil, err
+		}
+		s.Seconds = seconds
+		segments = segments[1:]
+	}
+
+	var err error
+
+	if s.Minutes, err = parseCronSegment(segments[0], 0, 59); err != nil {
 		return nil, err
 	}
 
-	hours, err := parseCronSegment(segments[1], 0, 23)
-	if err != nil {
+	if s.Hours, err = parseCronSegment(segments[1], 0, 23); err != nil {
 		return nil, err
 	}
 
-	days, err := parseCronSegment(segments[2], 1, 31)
-	if err != nil {
+	if err = s.parseDays(segments[2]); err != nil {
 		return nil, err
 	}
 
-	months, err := parseCronSegment(segments[3], 1, 12)
-	if err != nil {
+	if s.Months, err = parseCronSegment(replaceNames(segments[3], monthNames), 1, 12); err != nil {
 		return nil, err
 	}
 
-	daysOfWeek, err := parseCronSegment(segments[4], 0, 6)
-	if err != nil {
+	if err = s.parseDaysOfWeek(replaceNames(segments[4], dowNames)); err != nil {
 		return nil, err
 	}
 
-	return &Schedule{
-		Minutes:    minutes,
-		Hours:      hours,
-		Days:       days,
-		Months:     months,
-		DaysOfWeek: daysOfWeek,
-	}, nil
+	return s, nil
+}
+
+// parseDays parses the day of the month segment and its modifiers.
+func (s *Schedule) parseDays(segment string) error {
+	if segment == "?" {
+		segment = "*"
+	}
+
+	var rest []string
+	for _, p := range strings.Split(segment, ",") {
+		switch {
+		case p == "L":
+			s.lastDay = true
+		case p == "LW":
+			s.lastWeekday = true
+		case strings.HasPrefix(p, "L-"):
+			off, err := strconv.Atoi(p[2:])
+			if err != nil || off < 1 || off > 30 {
+				return fmt.Errorf("invalid segment last day offset %q - must be between 1 and 30", p)
+			}
+			s.lastDayOff = addTo(s.lastDayOff, off)
+		case strings.HasSuffix(p, "W"):
+			day, err := strconv.Atoi(p[:len(p)-1])
+			if err != nil || day < 1 || day > 31 {
+				return fmt.Errorf("invalid segment nearest weekday %q - must be between 1 and 31", p)
+			}
+			s.nearestWeek = addTo(s.nearestWeek, day)
+		default:
+			rest = append(rest, p)
+		}
+	}
+
+	days, err := parseRest(rest, 1, 31)
+	if err != nil {
+		return err
+	}
+	s.Days = days
+
+	return nil
+}
+
+// parseDaysOfWeek parses the day of the week segment and its modifiers.
+func (s *Schedule) parseDaysOfWeek(segment string) error {
+	if segment == "?" {
+		segment = "*"
+	}
+
+	var rest []string
+	for _, p := range strings.Split(segment, ",") {
+		switch {
+		case len(p) > 1 && strings.HasSuffix(p, "L"):
+			dow, err := strconv.Atoi(p[:len(p)-1])
+			if err != nil || dow < 0 || dow > 6 {
+				return fmt.Errorf("invalid segment last day of the week %q - must be between 0 and 6", p)
+			}
+			s.lastDow = addTo(s.lastDow, dow)
+		case strings.Contains(p, "#"):
+			dowStr, nthStr, _ := strings.Cut(p, "#")
+			dow, err := strconv.Atoi(dowStr)
+			if err != nil || dow < 0 || dow > 6 {
+				return fmt.Errorf("invalid segment day of the week %q - must be between 0 and 6", p)
+			}
+			nth, err := strconv.Atoi(nthStr)
+			if err != nil || nth < 1 || nth > 5 {
+				return fmt.Errorf("invalid segment day of the week occurrence %q - must be between 1 and 5", p)
+			}
+			if s.nthDow == nil {
+				s.nthDow = map[[2]int]struct{}{}
+			}
+			s.nthDow[[2]int{dow, nth}] = struct{}{}
+		default:
+			rest = append(rest, p)
+		}
+	}
+
+	daysOfWeek, err := parseRest(rest, 0, 6)
+	if err != nil {
+		return err
+	}
+	s.DaysOfWeek = daysOfWeek
+
+	return nil
+}
+
+// parseRest parses the segment parts left after extracting the modifiers.
+func parseRest(parts []string, min int, max int) (map[int]struct{}, error) {
+	if len(parts) == 0 {
+		return map[int]struct{}{}, nil
+	}
+	return parseCronSegment(strings.Join(parts, ","), min, max)
+}
+
+func addTo(set map[int]struct{}, v int) map[int]struct{} {
+	if set == nil {
+		set = map[int]struct{}{}
+	}
+	set[v] = struct{}{}
+	return set
+}
+
+// replaceNames replaces the case insensitive month or day of the week names with their numbers.
+func replaceNames(segment string, names map[string]int) string {
+	lower := strings.ToLower(segment)
+	for name, v := range names {
+		lower = strings.ReplaceAll(lower, name, strconv.Itoa(v))
+	}
+	// keep the original case of the modifiers (L, W)
+	if strings.ContainsAny(segment, "LW") {
+		return strings.NewReplacer("l", "L", "w", "W").Replace(lower)
+	}
+	return lower
 }
 
 // parseCronSegment parses a single cron expression segment and
@@ -58,7 +58,32 @@ func TestNewSchedule(t *testing.T) {
 			"",
 		},
 		{
-			"* * * * * *",
+			"* * * * * * *",
+			true,
+			"",
+		},
+		{
+			"@every 500ms",
+			true,
+			"",
+		},
+		{
+			"CRON_TZ=Invalid/Zone * * * * *",
+			true,
+			"",
+		},
+		{
+			"* * L-31 * *",
+			true,
+			"",
+		},
+		{
+			"* * * * 7L",
+			true,
+			"",
+		},
+		{
+			"* * * * 1#6",
 			true,
 			"",
 		},
@@ -402,3 +427,168 @@ func TestScheduleIsDue(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduleIsDueTicks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		cronExpr string
+		interval time.Duration
+		expected int
+	}{
+		// 5 segments expressions are due once per minute at any tick interval
+		{"* * * * *", time.Minute, 10},
+		{"* * * * *", time.Second, 10},
+		{"*/5 * * * *", time.Second, 2},
+		// 6 segments expressions need a tick interval that reaches their second
+		{"* * * * * *", time.Second, 600},
+		{"30 * * * * *", time.Second, 10},
+		{"0,30 * * * * *", time.Minute, 10},
+	}
+
+	for i, s := range scenarios {
+		schedule, err := cron.NewSchedule(s.cronExpr)
+		if err != nil {
+			t.Fatalf("[%d-%s] Unexpected cron error: %v", i, s.cronExpr, err)
+		}
+
+		due := 0
+		for tick := start; tick.Before(start.Add(10 * time.Minute)); tick = tick.Add(s.interval) {
+			if schedule.IsDue(cron.NewMoment(tick)) {
+				due++
+			}
+		}
+
+		if due != s.expected {
+			t.Fatalf("[%d-%s-%v] Expected %d due ticks, got %d", i, s.cronExpr, s.interval, s.expected, due)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		cronExpr string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			"* * * * *",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 21, 0, 0, time.UTC),
+		},
+		{
+			"*/15 * * * * *",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 20, 45, 0, time.UTC),
+		},
+		{
+			"@every 90s",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 22, 0, 0, time.UTC),
+		},
+		{
+			"@daily",
+			time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 12 L * *",
+			time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 12 L-2 * *",
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 28, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2024-06-15 is a saturday
+			"0 0 15W * *",
+			time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2024-06-01 is a saturday
+			"0 0 1W * *",
+			time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC),
+			time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2024-03-31 is a sunday
+			"0 0 LW * *",
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 0 * * 5L",
+			time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 0 * * MON#2",
+			time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 0 1 JAN-MAR ?",
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 0 30 2 *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Time{},
+		},
+		{
+			"CRON_TZ=America/New_York 0 9 * * *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		},
+
+		// DST start (2024-03-10 02:00 -> 03:00): the missing time is skipped
+		{
+			"30 2 * * *",
+			time.Date(2024, 3, 9, 12, 0, 0, 0, ny),
+			time.Date(2024, 3, 11, 2, 30, 0, 0, ny),
+		},
+		{
+			"0 * * * *",
+			time.Date(2024, 3, 10, 1, 30, 0, 0, ny),
+			time.Date(2024, 3, 10, 3, 0, 0, 0, ny),
+		},
+
+		// DST end (2024-11-03 02:00 -> 01:00): the repeated time runs once
+		{
+			"30 1 * * *",
+			time.Date(2024, 11, 3, 1, 30, 0, 0, ny),
+			time.Date(2024, 11, 4, 1, 30, 0, 0, ny),
+		},
+		{
+			"30 * * * *",
+			time.Date(2024, 11, 3, 1, 30, 0, 0, ny),
+			time.Date(2024, 11, 3, 1, 30, 0, 0, ny).Add(time.Hour),
+		},
+	}
+
+	for i, s := range scenarios {
+		schedule, err := cron.NewSchedule(s.cronExpr)
+		if err != nil {
+			t.Fatalf("[%d-%s] Unexpected cron error: %v", i, s.cronExpr, err)
+		}
+
+		result := schedule.Next(s.after)
+
+		if !result.Equal(s.expected) {
+			t.Fatalf("[%d-%s] Expected %v, got %v", i, s.cronExpr, s.expected, result)
+		}
+	}
+}
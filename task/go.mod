@@ -2,4 +2,70 @@ module github.com/apus-run/sea-kit/task
 
 go 1.21
 
-require github.com/robfig/cron/v3 v3.0.1
+require (
+	github.com/apus-run/sea-kit/metrics v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/redisx v0.0.0-00010101000000-000000000000
+	github.com/apus-run/sea-kit/slogx v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.17.0
+	github.com/redis/go-redis/v9 v9.4.0
+	github.com/robfig/cron/v3 v3.0.1
+	github.com/stretchr/testify v1.8.4
+	go.etcd.io/etcd/client/v3 v3.5.11
+	gorm.io/driver/sqlite v1.5.2
+	gorm.io/gorm v1.25.2-0.20230530020048-26663ab9bf55
+)
+
+require (
+	github.com/apus-run/sea-kit/errorsx v0.0.0-00010101000000-000000000000 // indirect
+	github.com/apus-run/sea-kit/log v0.0.0-20231107100551-698711f6fbdb // indirect
+	github.com/apus-run/sea-kit/tls v0.0.0-20231107100551-698711f6fbdb // indirect
+	github.com/apus-run/sea-kit/utils v0.0.0-20231107100551-698711f6fbdb // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/coreos/go-semver v0.3.0 // indirect
+	github.com/coreos/go-systemd/v22 v22.3.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.4.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/lithammer/shortuuid/v4 v4.0.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
+	github.com/natefinch/lumberjack v2.0.0+incompatible // indirect
+	github.com/oklog/ulid v1.3.1 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_model v0.4.1-0.20230718164431-9a2bf3000d16 // indirect
+	github.com/prometheus/common v0.44.0 // indirect
+	github.com/prometheus/procfs v0.11.1 // indirect
+	go.etcd.io/etcd/api/v3 v3.5.11 // indirect
+	go.etcd.io/etcd/client/pkg/v3 v3.5.11 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/zap v1.17.0 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sync v0.9.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
+	google.golang.org/genproto v0.0.0-20230822172742-b8732ec3820d // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20230822172742-b8732ec3820d // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230822172742-b8732ec3820d // indirect
+	google.golang.org/grpc v1.59.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/apus-run/sea-kit/collection => ../collection
+	github.com/apus-run/sea-kit/errorsx => ../errorsx
+	github.com/apus-run/sea-kit/log => ../log
+	github.com/apus-run/sea-kit/metrics => ../metrics
+	github.com/apus-run/sea-kit/prof => ../prof
+	github.com/apus-run/sea-kit/redisx => ../redisx
+	github.com/apus-run/sea-kit/slogx => ../slogx
+	github.com/apus-run/sea-kit/tls => ../tls
+	github.com/apus-run/sea-kit/utils => ../utils
+)